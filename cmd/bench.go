@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/di"
+	"github.com/ryanolee/go-pot/protocol/http"
+	"github.com/spf13/cobra"
+	"github.com/valyala/fasthttp/fasthttputil"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// Memory the number of connections that could be stalled is projected for
+	benchProjectedMemory = 512 * 1024 * 1024
+
+	// Goroutines reading what is sent to the benchmark clients
+	benchDrainers = 4
+)
+
+type (
+	// A single connection held open by the benchmark
+	benchClient struct {
+		conn     net.Conn
+		received atomic.Int64
+		closed   atomic.Bool
+	}
+
+	// Memory in use by the process at a point in time
+	benchMemory struct {
+		heap       uint64
+		stack      uint64
+		runtime    uint64
+		rss        uint64
+		goroutines int
+	}
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measures how many HTTP connections can be stalled at once",
+	Long: "Opens connections to an in memory HTTP server and holds them open while they are stalled, measuring the memory used per connection. " +
+		"Nothing is bound to a port. Memory is measured from the Go runtime (and the resident set size where available) and includes the in memory " +
+		"client side of each connection. Kernel socket buffers used by real connections are not included.",
+	Run: func(cmd *cobra.Command, args []string) {
+		conf, err := config.NewConfig(cmd, config.GetBenchFlags())
+		if err != nil {
+			fmt.Println("Failed to run the benchmark due to a bad configuration. Please check your GO__POT__ environment variables, cli flags and config file (if set).\nThe errors are as follows::")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		connections, _ := cmd.Flags().GetInt("connections")
+		hold, _ := cmd.Flags().GetDuration("hold")
+		paths, _ := cmd.Flags().GetStringSlice("paths")
+		if connections < 1 || len(paths) == 0 {
+			fmt.Println("At least one connection and path is needed to run the benchmark")
+			os.Exit(1)
+		}
+
+		// Every connection comes from the same client and is stalled for longer than the benchmark runs. The pool
+		// prunes connections once it is 90% full so the maximum is set well above the number of connections
+		conf.Staller.MaximumConnections = connections * 2
+		conf.Staller.GroupLimit = connections
+		conf.TimeoutWatcher.Enabled = false
+		conf.TimeoutWatcher.LongestTimeout = int((hold + time.Hour).Milliseconds())
+		conf.Server.AccessLog.Mode = "none"
+		conf.Generator.Secrets.Honeytokens.Enabled = false
+		conf.Logging.Level = zapcore.ErrorLevel.String()
+
+		var (
+			server    *http.Server
+			scheduler *stall.Scheduler
+			pool      *stall.StallerPool
+		)
+		app := di.CreateBenchContainer(conf, func(s *http.Server, sc *stall.Scheduler, p *stall.StallerPool) {
+			server, scheduler, pool = s, sc, p
+		})
+		if err := app.Start(context.Background()); err != nil {
+			fmt.Println("Failed to start the benchmark:", err)
+			os.Exit(1)
+		}
+
+		listener := fasthttputil.NewInmemoryListener()
+		go func() {
+			if err := server.Serve(listener); err != nil {
+				fmt.Println("Benchmark server stopped:", err)
+			}
+		}()
+
+		fmt.Printf("Stalling %d connections for %s (%d CPUs, %s)\n", connections, hold, runtime.NumCPU(), runtime.Version())
+		baseline := readBenchMemory()
+		fmt.Printf("Baseline: %s\n", baseline)
+
+		clients := make([]*benchClient, 0, connections)
+		for i := 0; i < connections; i++ {
+			client, err := dialBenchClient(listener, paths[i%len(paths)])
+			if err != nil {
+				fmt.Printf("Failed to open connection %d: %s\n", i+1, err)
+				break
+			}
+
+			clients = append(clients, client)
+			if (i+1)%max(connections/10, 1) == 0 {
+				fmt.Printf("Opened %d connections\n", i+1)
+			}
+		}
+
+		stop := make(chan struct{})
+		drainers := &sync.WaitGroup{}
+		for i := 0; i < benchDrainers; i++ {
+			drainers.Add(1)
+			go func(offset int) {
+				defer drainers.Done()
+				drainBenchClients(clients, offset, stop)
+			}(i)
+		}
+
+		// Generating the start of every response takes a while. Memory is only measured once every connection is waiting on the scheduler
+		settling := time.Now()
+		for deadline := settling.Add(time.Minute * 5); time.Now().Before(deadline); time.Sleep(time.Millisecond * 100) {
+			if pool.Len() >= len(clients) && scheduler.Pending() >= len(clients)*9/10 {
+				break
+			}
+		}
+		fmt.Printf("Connections settled after %s (stalled: %d waiting: %d)\n", time.Since(settling).Round(time.Millisecond), pool.Len(), scheduler.Pending())
+
+		start := time.Now()
+		startReceived := countBenchReceived(clients)
+		peak := readBenchMemory()
+		for time.Since(start) < hold {
+			time.Sleep(min(time.Second*5, hold-time.Since(start)))
+
+			memory := readBenchMemory()
+			if memory.runtime > peak.runtime {
+				peak = memory
+			}
+
+			fmt.Printf("%6s stalled: %d waiting: %d open: %d %s\n", time.Since(start).Round(time.Second), pool.Len(), scheduler.Pending(), countOpenBenchClients(clients), memory)
+		}
+
+		elapsed := time.Since(start)
+		received := countBenchReceived(clients) - startReceived
+		open := countOpenBenchClients(clients)
+		close(stop)
+		drainers.Wait()
+
+		fmt.Println()
+		fmt.Printf("Connections held:         %d of %d\n", open, connections)
+		fmt.Printf("Peak memory:              %s\n", peak)
+		if open > 0 {
+			perConnection := peak.runtime - min(baseline.runtime, peak.runtime)
+			fmt.Printf("Go runtime per connection: %s\n", formatBenchBytes(perConnection/uint64(open)))
+			if peak.rss > 0 {
+				perConnection = peak.rss - min(baseline.rss, peak.rss)
+				fmt.Printf("RSS per connection:        %s\n", formatBenchBytes(perConnection/uint64(open)))
+			}
+
+			if perConnection > 0 {
+				fmt.Printf("Projected for %s:        %d connections\n", formatBenchBytes(benchProjectedMemory), (benchProjectedMemory-min(baseline.rss, benchProjectedMemory))/(perConnection/uint64(open)))
+			}
+
+			fmt.Printf("Bytes sent per connection: %.2f/s including chunk framing (bytes_per_second: %d, pacer: %s)\n", float64(received)/float64(open)/elapsed.Seconds(), conf.Staller.BytesPerSecond, conf.Staller.Pacing.Pacer)
+		}
+
+		// Stallers are closed before the server so it does not wait on their next send to shut down
+		pool.Stop()
+		for _, client := range clients {
+			client.conn.Close()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+		if err := app.Stop(ctx); err != nil {
+			fmt.Println("Failed to stop the benchmark cleanly:", err)
+		}
+	},
+}
+
+// Opens a connection requesting the given path. Reads never block so a few goroutines can drain every connection
+func dialBenchClient(listener *fasthttputil.InmemoryListener, path string) (*benchClient, error) {
+	conn, err := listener.Dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: bench\r\nUser-Agent: go-pot-bench\r\n\r\n", path); err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetReadDeadline(time.Unix(1, 0)); err != nil {
+		return nil, err
+	}
+
+	return &benchClient{conn: conn}, nil
+}
+
+// Reads whatever has been sent to every nth client until stopped
+func drainBenchClients(clients []*benchClient, offset int, stop chan struct{}) {
+	buffer := make([]byte, 32*1024)
+	for {
+		for i := offset; i < len(clients); i += benchDrainers {
+			client := clients[i]
+			for !client.closed.Load() {
+				n, err := client.conn.Read(buffer)
+				client.received.Add(int64(n))
+
+				// In memory connections only implement the timeout part of net.Error
+				var timeoutErr interface{ Timeout() bool }
+				if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+					break
+				}
+
+				if err != nil {
+					client.closed.Store(true)
+				}
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(time.Millisecond * 100):
+		}
+	}
+}
+
+func countBenchReceived(clients []*benchClient) int64 {
+	var received int64
+	for _, client := range clients {
+		received += client.received.Load()
+	}
+
+	return received
+}
+
+func countOpenBenchClients(clients []*benchClient) int {
+	open := 0
+	for _, client := range clients {
+		if !client.closed.Load() {
+			open++
+		}
+	}
+
+	return open
+}
+
+// Reads memory in use once everything unused has been collected and returned to the OS
+func readBenchMemory() benchMemory {
+	debug.FreeOSMemory()
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return benchMemory{
+		heap:       stats.HeapInuse,
+		stack:      stats.StackInuse,
+		runtime:    stats.Sys - stats.HeapReleased,
+		rss:        readBenchRss(),
+		goroutines: runtime.NumGoroutine(),
+	}
+}
+
+// Reads the resident set size of the process. Only available on linux
+func readBenchRss() uint64 {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "VmRSS:" {
+			continue
+		}
+
+		kilobytes, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+
+		return kilobytes * 1024
+	}
+
+	return 0
+}
+
+func (m benchMemory) String() string {
+	rss := "unknown"
+	if m.rss > 0 {
+		rss = formatBenchBytes(m.rss)
+	}
+
+	return fmt.Sprintf("heap: %s stacks: %s runtime: %s rss: %s goroutines: %d", formatBenchBytes(m.heap), formatBenchBytes(m.stack), formatBenchBytes(m.runtime), rss, m.goroutines)
+}
+
+func formatBenchBytes(bytes uint64) string {
+	switch {
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1fMiB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1fKiB", float64(bytes)/1024)
+	}
+
+	return fmt.Sprintf("%dB", bytes)
+}
+
+func init() {
+	benchCmd.Flags().Int("connections", 50000, "The number of connections to stall at once.")
+	benchCmd.Flags().Duration("hold", time.Second*30, "How long to hold the connections open once they are all stalled.")
+	benchCmd.Flags().StringSlice("paths", []string{"/.env", "/config.json", "/backup.sql", "/users.csv", "/wp-config.php.bak", "/robots.txt"}, "The paths requested by the connections as comma separated values.")
+
+	config.BindConfigFlags(benchCmd, config.GetBenchFlags())
+	config.BindConfigFileFlags(benchCmd)
+	rootCmd.AddCommand(benchCmd)
+}
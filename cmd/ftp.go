@@ -23,6 +23,12 @@ var ftpCommand = &cobra.Command{
 
 		// Make sure only the FTP server is enabled
 		conf.FtpServer.Enabled = true
+		conf.SshServer.Enabled = false
+		conf.SmtpServer.Enabled = false
+		conf.TelnetServer.Enabled = false
+		conf.RedisServer.Enabled = false
+		conf.MysqlServer.Enabled = false
+		conf.PostgresServer.Enabled = false
 		conf.Server.Disable = true
 
 		di := di.CreateContainer(conf)
@@ -23,6 +23,12 @@ var httpCommand = &cobra.Command{
 
 		// Make sure only the HTTP server is enabled
 		conf.FtpServer.Enabled = false
+		conf.SshServer.Enabled = false
+		conf.SmtpServer.Enabled = false
+		conf.TelnetServer.Enabled = false
+		conf.RedisServer.Enabled = false
+		conf.MysqlServer.Enabled = false
+		conf.PostgresServer.Enabled = false
 		conf.Server.Disable = false
 
 		di := di.CreateContainer(conf)
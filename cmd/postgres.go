@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/di"
+	"github.com/spf13/cobra"
+)
+
+var postgresCommand = &cobra.Command{
+	Use:   "postgres",
+	Short: "Starts the PostgreSQL server",
+	Run: func(cmd *cobra.Command, args []string) {
+		conf, err := config.NewConfig(cmd, config.GetPostgresFlags())
+
+		if err != nil {
+			fmt.Println("Failed to start go pot in PostgreSQL mode due to a bad configuration. Please check your GO__POT__ environment variables, cli flags and config file (if set).\nThe errors are as follows:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// Make sure only the PostgreSQL server is enabled
+		conf.PostgresServer.Enabled = true
+		conf.MysqlServer.Enabled = false
+		conf.FtpServer.Enabled = false
+		conf.SshServer.Enabled = false
+		conf.SmtpServer.Enabled = false
+		conf.TelnetServer.Enabled = false
+		conf.RedisServer.Enabled = false
+		conf.Server.Disable = true
+
+		di := di.CreateContainer(conf)
+		di.Run()
+	},
+}
+
+func init() {
+	config.BindConfigFlags(postgresCommand, config.GetPostgresFlags())
+	config.BindConfigFileFlags(postgresCommand)
+	rootCmd.AddCommand(postgresCommand)
+}
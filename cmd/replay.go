@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
+	ftpStall "github.com/ryanolee/go-pot/protocol/ftp/stall"
+	httpStall "github.com/ryanolee/go-pot/protocol/http/stall"
+	"github.com/ryanolee/go-pot/secrets"
+	"github.com/spf13/cobra"
+)
+
+// Number of bytes replayed for HTTP streams when no limit is given
+const defaultReplayBytes = 64 * 1024
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Generates a stream served in replay mode again",
+	Long: "Generates the stream served to a client again from the replay secret, client IP, path and stream number " +
+		"(the stream_number field of the HTTP access log or the \"Serving replayable stream\" log line for FTP). " +
+		"The stream is written to stdout. Honeytokens can not be replayed as they are issued per request.",
+	Run: func(cmd *cobra.Command, args []string) {
+		conf, err := config.NewConfig(cmd, config.GetReplayFlags())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to replay the stream due to a bad configuration. Please check your GO__POT__ environment variables, cli flags and config file (if set).\nThe errors are as follows::")
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if conf.Generator.Replay.Secret == "" {
+			fmt.Fprintln(os.Stderr, "No replay secret is configured. Streams can only be replayed with the generator.replay.secret they were served with")
+			os.Exit(1)
+		}
+
+		key, limit, err := getReplayKey(cmd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// Honeytokens are derived from the time they were served so they are looked up with go-pot trace instead
+		if conf.Generator.Secrets.Honeytokens.Enabled {
+			fmt.Fprintln(os.Stderr, "Warning: honeytokens are enabled. Secrets in the stream will differ from those served, use go-pot trace to look them up")
+			conf.Generator.Secrets.Honeytokens.Enabled = false
+		}
+
+		configGenerators, err := generator.NewConfigGeneratorCollection(conf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		tabularSchemas, err := source.NewTabularSchemaCollection(conf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		secretGenerators, err := secrets.NewSecretGeneratorCollection(conf, nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		stream := generator.NewReplayStreamSource(conf.Generator.Replay.Secret, *key)
+		switch key.Protocol {
+		case "http":
+			gen, err := getReplayHttpGenerator(cmd, key.Path, configGenerators, tabularSchemas, secretGenerators, stream)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+
+			if limit == 0 {
+				limit = defaultReplayBytes
+			}
+			os.Stdout.Write(replayHttpStream(gen, limit))
+		case "ftp":
+			if limit == 0 {
+				limit = conf.FtpServer.Transfer.FileSize
+			}
+			if err := replayFtpStream(conf, key.Path, configGenerators, tabularSchemas, secretGenerators, stream, limit); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "Streams served over %s can not be replayed. Options: http, ftp\n", key.Protocol)
+			os.Exit(1)
+		}
+	},
+}
+
+func getReplayKey(cmd *cobra.Command) (*generator.StreamKey, int, error) {
+	protocol, _ := cmd.Flags().GetString("protocol")
+	ip, _ := cmd.Flags().GetString("ip")
+	path, _ := cmd.Flags().GetString("path")
+	number, _ := cmd.Flags().GetUint64("n")
+	dateFlag, _ := cmd.Flags().GetString("date")
+	limit, _ := cmd.Flags().GetInt("bytes")
+
+	date := time.Now().UTC()
+	if dateFlag != "" {
+		var err error
+		if date, err = time.Parse(generator.StreamDateLayout, dateFlag); err != nil {
+			if date, err = time.Parse(time.RFC3339, dateFlag); err != nil {
+				return nil, 0, fmt.Errorf("invalid date %s. Dates must be given as YYYY-MM-DD or an RFC 3339 timestamp", dateFlag)
+			}
+		}
+	}
+
+	if number == 0 {
+		return nil, 0, fmt.Errorf("stream numbers start from 1")
+	}
+
+	return &generator.StreamKey{
+		Protocol: protocol,
+		Client:   ip,
+		Path:     path,
+		Date:     date,
+		Number:   number,
+	}, limit, nil
+}
+
+// Picks the generator the same way the HTTP server does from the method and path of the request
+func getReplayHttpGenerator(cmd *cobra.Command, path string, configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretGenerators *secrets.SecretGeneratorCollection, stream *generator.StreamSource) (generator.Generator, error) {
+	method, _ := cmd.Flags().GetString("method")
+	bodySize, _ := cmd.Flags().GetInt("body-size")
+	method = strings.ToUpper(method)
+
+	if path == "/robots.txt" && (method == fiber.MethodGet || method == fiber.MethodHead) {
+		return generator.NewRobotsTxtGenerator(stream.Rand)
+	}
+
+	gen, _ := httpStall.GetGeneratorForRequest(method, path, bodySize, configGenerators, tabularSchemas, secretGenerators, stream)
+	return gen, nil
+}
+
+// Generates the stream the same way the HTTP staller sends it up to the given number of bytes
+func replayHttpStream(gen generator.Generator, limit int) []byte {
+	var data bytes.Buffer
+	data.Write(gen.Start())
+	for data.Len() < limit {
+		if finite, ok := gen.(generator.FiniteGenerator); ok && finite.Done() {
+			data.Write(gen.End())
+			break
+		}
+
+		data.Write(gen.GenerateChunk())
+		data.Write(gen.ChunkSeparator())
+	}
+
+	return data.Bytes()[:min(data.Len(), limit)]
+}
+
+// FTP files are padded out to their size so the stream is read from a file staller
+func replayFtpStream(conf *config.Config, path string, configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretGenerators *secrets.SecretGeneratorCollection, stream *generator.StreamSource, size int) error {
+	encoderInstance := encoder.GetEncoderForPath(path)
+	staller := ftpStall.NewFtpFileStall(&ftpStall.NewFtpFileStallerArgs{
+		Config:      conf,
+		Encoder:     encoderInstance,
+		Generator:   generator.GetGeneratorForPath(path, encoderInstance, configGenerators, tabularSchemas, secretGenerators, stream),
+		BytesToSend: size,
+	})
+
+	// The staller halts itself once the whole file has been read
+	staller.BindToPool(make(chan stall.Staller, 1))
+
+	_, err := io.Copy(os.Stdout, staller)
+	return err
+}
+
+func init() {
+	replayCmd.Flags().String("protocol", "http", "The protocol the stream was served over. Options: http, ftp")
+	replayCmd.Flags().String("ip", "", "The IP address of the client the stream was served to.")
+	replayCmd.Flags().String("path", "", "The requested path (or file name for FTP).")
+	replayCmd.Flags().String("method", fiber.MethodGet, "The method of the HTTP request (the method field of the HTTP access log).")
+	replayCmd.Flags().Int("body-size", 0, "The size of the body of the HTTP request (the body_size field of the HTTP access log). Only used to replay POST, PUT, PATCH and DELETE requests.")
+	replayCmd.Flags().Uint64("n", 0, "The number of the stream for the client on the day it was served.")
+	replayCmd.Flags().String("date", "", "The UTC day the stream was served on as YYYY-MM-DD or an RFC 3339 timestamp. (If not set, today is used.)")
+	replayCmd.Flags().Int("bytes", 0, "The number of bytes to generate. (If not set, 65536 bytes are generated for HTTP and the configured file size for FTP.)")
+	for _, flag := range []string{"ip", "path", "n"} {
+		if err := replayCmd.MarkFlagRequired(flag); err != nil {
+			panic(err)
+		}
+	}
+
+	config.BindConfigFlags(replayCmd, config.GetReplayFlags())
+	config.BindConfigFileFlags(replayCmd)
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ryanolee/go-pot/secrets"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Tools for managing the rules fake secrets are generated from",
+}
+
+var secretsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Converts a gitleaks.toml file into secret rules",
+	Long:  "Converts a gitleaks.toml file into secret rules that can be loaded with the generator.secrets.rule_files option. Rules that can not be generated from are skipped.",
+	Run: func(cmd *cobra.Command, args []string) {
+		gitLeaksPath, _ := cmd.Flags().GetString("gitleaks")
+		outputPath, _ := cmd.Flags().GetString("output")
+
+		gitLeaksRules, err := secrets.GetSecretsFromGitLeaksFile(gitLeaksPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read gitleaks rules from %s: %s\n", gitLeaksPath, err)
+			os.Exit(1)
+		}
+
+		rules := secrets.ConvertGitLeaksRules(gitLeaksRules)
+		for name, rule := range rules {
+			if _, err := secrets.NewGeneratorFromRule(rule); err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping rule: %s\n", err)
+				delete(rules, name)
+			}
+		}
+
+		data, err := yaml.Marshal(rules)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode secret rules: %s\n", err)
+			os.Exit(1)
+		}
+
+		if outputPath == "" {
+			fmt.Print(string(data))
+			return
+		}
+
+		if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write secret rules to %s: %s\n", outputPath, err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintf(os.Stderr, "Imported %d of %d rules into %s\n", len(rules), len(gitLeaksRules.Rules), outputPath)
+	},
+}
+
+var secretsValidateCmd = &cobra.Command{
+	Use:   "validate [rule files...]",
+	Short: "Checks every regex in the given rule files can be generated from",
+	Long:  "Checks every regex in the given rule files can be generated from. If no files are given the embedded rules are checked.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			if !validateSecretRules("embedded rules", *secrets.GetRules()) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		valid := true
+		for _, path := range args {
+			rules, err := secrets.LoadRulesFile(path)
+			if err != nil {
+				fmt.Println(err)
+				valid = false
+				continue
+			}
+
+			valid = validateSecretRules(path, rules) && valid
+		}
+
+		if !valid {
+			os.Exit(1)
+		}
+	},
+}
+
+func validateSecretRules(source string, rules secrets.SecretGeneratorRules) bool {
+	errs := secrets.ValidateRules(rules)
+	for _, err := range errs {
+		fmt.Printf("%s: %s\n", source, err)
+	}
+
+	fmt.Printf("%s: %d of %d rules are valid\n", source, len(rules)-len(errs), len(rules))
+	return len(errs) == 0
+}
+
+func init() {
+	secretsImportCmd.Flags().String("gitleaks", "", "Path to the gitleaks.toml file to import.")
+	secretsImportCmd.Flags().StringP("output", "o", "", "File to write the rules to. (If not set, rules are written to stdout.)")
+	if err := secretsImportCmd.MarkFlagRequired("gitleaks"); err != nil {
+		panic(err)
+	}
+
+	secretsCmd.AddCommand(secretsImportCmd)
+	secretsCmd.AddCommand(secretsValidateCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
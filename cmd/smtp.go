@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/di"
+	"github.com/spf13/cobra"
+)
+
+var smtpCommand = &cobra.Command{
+	Use:   "smtp",
+	Short: "Starts the SMTP server",
+	Run: func(cmd *cobra.Command, args []string) {
+		conf, err := config.NewConfig(cmd, config.GetSmtpFlags())
+
+		if err != nil {
+			fmt.Println("Failed to start go pot in SMTP mode due to a bad configuration. Please check your GO__POT__ environment variables, cli flags and config file (if set).\nThe errors are as follows:")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// Make sure only the SMTP server is enabled
+		conf.SmtpServer.Enabled = true
+		conf.FtpServer.Enabled = false
+		conf.SshServer.Enabled = false
+		conf.TelnetServer.Enabled = false
+		conf.RedisServer.Enabled = false
+		conf.MysqlServer.Enabled = false
+		conf.PostgresServer.Enabled = false
+		conf.Server.Disable = true
+
+		di := di.CreateContainer(conf)
+		di.Run()
+	},
+}
+
+func init() {
+	config.BindConfigFlags(smtpCommand, config.GetSmtpFlags())
+	config.BindConfigFileFlags(smtpCommand)
+	rootCmd.AddCommand(smtpCommand)
+}
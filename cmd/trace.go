@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/secrets"
+	"github.com/spf13/cobra"
+)
+
+var traceCmd = &cobra.Command{
+	Use:   "trace <secret>",
+	Short: "Finds who a honeytoken was served to",
+	Long:  "Looks a secret up in the honeytoken ledger and shows the IP, path and time it was served to. Entries are verified against the honeytoken key if one is configured.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		conf, err := config.NewConfig(cmd, config.GetTraceFlags())
+		if err != nil {
+			fmt.Println("Failed to trace the secret due to a bad configuration. Please check your GO__POT__ environment variables, cli flags and config file (if set).\nThe errors are as follows::")
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		honeytokenConfig := conf.Generator.Secrets.Honeytokens
+		entries, err := secrets.TraceHoneytoken(honeytokenConfig.LedgerPath, args[0])
+		if err != nil {
+			fmt.Printf("Failed to read the honeytoken ledger %s: %s\n", honeytokenConfig.LedgerPath, err)
+			os.Exit(1)
+		}
+
+		if len(entries) == 0 {
+			fmt.Printf("The secret was not found in the honeytoken ledger %s\n", honeytokenConfig.LedgerPath)
+			os.Exit(1)
+		}
+
+		rules, err := secrets.LoadRules(conf.Generator.Secrets.RuleFiles)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		verifier := secrets.NewHoneytokenVerifier(honeytokenConfig.Key)
+		for _, entry := range entries {
+			fmt.Printf("Served to %s over %s\n", entry.Ip, entry.Protocol)
+			fmt.Printf("  Path:       %s\n", entry.Path)
+			fmt.Printf("  Time:       %s\n", entry.Time)
+			fmt.Printf("  Request ID: %s\n", entry.RequestId)
+			fmt.Printf("  Rule:       %s\n", entry.Rule)
+			fmt.Printf("  Secret:     %s\n", entry.Secret)
+			fmt.Printf("  Verified:   %s\n\n", verifyHoneytoken(verifier, honeytokenConfig.Key, rules, entry))
+		}
+	},
+}
+
+// Describes if the entry could be proven to have been issued with the configured key
+func verifyHoneytoken(verifier *secrets.HoneytokenIssuer, key string, rules secrets.SecretGeneratorRules, entry *secrets.HoneytokenLedgerEntry) string {
+	if key == "" {
+		return "unknown (no honeytoken key is configured)"
+	}
+
+	rule, ok := rules[entry.Rule]
+	if !ok {
+		return "unknown (the rule the secret was generated from no longer exists)"
+	}
+
+	generator, err := secrets.NewGeneratorFromRule(rule)
+	if err != nil {
+		return fmt.Sprintf("unknown (%s)", err)
+	}
+
+	if !verifier.Verify(entry, generator) {
+		return "no (the entry does not match the honeytoken key)"
+	}
+
+	return "yes"
+}
+
+func init() {
+	config.BindConfigFlags(traceCmd, config.GetTraceFlags())
+	config.BindConfigFileFlags(traceCmd)
+	rootCmd.AddCommand(traceCmd)
+}
@@ -14,12 +14,19 @@ type (
 	Config struct {
 		Server         serverConfig         `koanf:"server"`
 		FtpServer      ftpServerConfig      `koanf:"ftp_server"`
+		SshServer      sshServerConfig      `koanf:"ssh_server"`
+		SmtpServer     smtpServerConfig     `koanf:"smtp_server"`
+		TelnetServer   telnetServerConfig   `koanf:"telnet_server"`
+		RedisServer    redisServerConfig    `koanf:"redis_server"`
+		MysqlServer    mysqlServerConfig    `koanf:"mysql_server"`
+		PostgresServer postgresServerConfig `koanf:"postgres_server"`
 		Logging        loggingConfig        `koanf:"logging"`
 		Cluster        clusterConfig        `koanf:"cluster"`
 		TimeoutWatcher timeoutWatcherConfig `koanf:"timeout_watcher"`
 		Recast         recastConfig         `koanf:"recast"`
 		Telemetry      telemetryConfig      `koanf:"telemetry"`
 		Staller        stallerConfig        `koanf:"staller"`
+		Generator      generatorConfig      `koanf:"generator"`
 	}
 
 	// Server specific configuration
@@ -45,6 +52,43 @@ type (
 
 		// Enable access logs
 		AccessLog httpAccessLogConfig `koanf:"access_log"`
+
+		// Serve HTTPS alongside plain HTTP
+		Tls httpTlsConfig `koanf:"tls"`
+	}
+
+	// Configuration for the HTTPS listener
+	httpTlsConfig struct {
+		// If the HTTPS listener should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// Port for the HTTPS listener to listen on
+		Port int `koanf:"port" validate:"required_if=Enabled true,omitempty,min=1,max=65535"`
+
+		// The certificate and key to serve. If not set a certificate is generated in memory on startup
+		CertFile string `koanf:"cert_file" validate:"required_with=KeyFile,omitempty,file"`
+		KeyFile  string `koanf:"key_file" validate:"required_with=CertFile,omitempty,file"`
+
+		// Subject of the generated certificate
+		CommonName   string `koanf:"common_name" validate:"omitempty"`
+		Organization string `koanf:"organization" validate:"omitempty"`
+
+		// Subject alternative names of the generated certificate
+		DnsNames    []string `koanf:"dns_names" validate:"omitempty"`
+		IpAddresses []string `koanf:"ip_addresses" validate:"omitempty,dive,ip"`
+
+		// How long the generated certificate is valid for (In days)
+		ValidDays int `koanf:"valid_days" validate:"omitempty,min=1"`
+
+		// The made up certificate authority the generated certificate is issued by
+		Issuer httpTlsIssuerConfig `koanf:"issuer"`
+	}
+
+	httpTlsIssuerConfig struct {
+		CommonName         string `koanf:"common_name" validate:"omitempty"`
+		Organization       string `koanf:"organization" validate:"omitempty"`
+		OrganizationalUnit string `koanf:"organizational_unit" validate:"omitempty"`
+		Country            string `koanf:"country" validate:"omitempty"`
 	}
 
 	// Config relating to FTP Server File Transfer
@@ -108,6 +152,205 @@ type (
 		AdditionalFields []string `koanf:"additional_fields" validate:"omitempty,dive,oneof=id dest_addr src_addr client_version type dest_port src_port src_host dest_host none"`
 	}
 
+	// Settings relating to the SSH server
+	sshServerConfig struct {
+		// If the SSH server should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// The port to listen on
+		Port int `koanf:"port" validate:"required,min=1,max=65535"`
+
+		// Host to listen on
+		Host string `koanf:"host" validate:"required"`
+
+		// The mode the SSH server runs in. The modes are as follows:
+		// banner - Never completes the handshake. Instead an endless stream of pre-banner lines is dripped to the client (Like endlessh)
+		// shell  - Completes the handshake, accepts any password and hands out a slow fake shell that streams generated secrets
+		Mode string `koanf:"mode" validate:"required,oneof=banner shell"`
+
+		// The version string announced to clients in shell mode
+		ServerVersion string `koanf:"server_version" validate:"omitempty,startswith=SSH-2.0-"`
+
+		// The path to a PEM encoded host key. If not set a host key is generated in memory on startup
+		HostKeyPath string `koanf:"host_key_path" validate:"omitempty,file"`
+
+		// The hostname shown in the prompt of the fake shell
+		ShellHostname string `koanf:"shell_hostname" validate:"omitempty"`
+
+		// Command logging configuration
+		CommandLog sshCommandLogConfig `koanf:"command_log"`
+	}
+
+	sshCommandLogConfig struct {
+		// The path to write the command logs to (Otherwise stdout)
+		Path string `koanf:"path" validate:"omitempty"`
+
+		// A list of commands to log against each connection to the SSH server (All commands are logged by default)
+		CommandsToLog []string `koanf:"commands_to_log" validate:"omitempty,dive,oneof=all all_detailed client_connected client_disconnected handshake_failed auth_user auth_public_key session_request channel_rejected exec command banner_line none"`
+
+		// Additional fields to log against each command
+		AdditionalFields []string `koanf:"additional_fields" validate:"omitempty,dive,oneof=id dest_addr src_addr client_version type dest_port src_port src_host dest_host none"`
+	}
+
+	// Settings relating to the SMTP server
+	smtpServerConfig struct {
+		// If the SMTP server should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// The port to listen on
+		Port int `koanf:"port" validate:"required,min=1,max=65535"`
+
+		// Host to listen on
+		Host string `koanf:"host" validate:"required"`
+
+		// The hostname the server announces itself as in the greeting and EHLO replies
+		Hostname string `koanf:"hostname" validate:"required"`
+
+		// The software shown in the greeting (i.e "ESMTP Postfix (Ubuntu)")
+		Banner string `koanf:"banner" validate:"omitempty"`
+
+		// The maximum number of bytes of a message body to capture and log. The rest of the body is still read but discarded
+		MaxBodyLogSize int `koanf:"max_body_log_size" validate:"min=0"`
+
+		// Command logging configuration
+		CommandLog smtpCommandLogConfig `koanf:"command_log"`
+	}
+
+	smtpCommandLogConfig struct {
+		// The path to write the command logs to (Otherwise stdout)
+		Path string `koanf:"path" validate:"omitempty"`
+
+		// A list of commands to log against each connection to the SMTP server (All commands are logged by default)
+		CommandsToLog []string `koanf:"commands_to_log" validate:"omitempty,dive,oneof=all all_detailed client_connected client_disconnected helo ehlo auth_user mail_from rcpt_to data message rset noop vrfy starttls quit unknown_command none"`
+
+		// Additional fields to log against each command
+		AdditionalFields []string `koanf:"additional_fields" validate:"omitempty,dive,oneof=id dest_addr src_addr client_version type dest_port src_port src_host dest_host none"`
+	}
+
+	// Settings relating to the Telnet server
+	telnetServerConfig struct {
+		// If the Telnet server should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// The port to listen on
+		Port int `koanf:"port" validate:"required,min=1,max=65535"`
+
+		// Host to listen on
+		Host string `koanf:"host" validate:"required"`
+
+		// The hostname shown in the login prompt and shell
+		Hostname string `koanf:"hostname" validate:"required"`
+
+		// Credentials accepted by the login prompt in the form "user:pass". If empty any credentials are accepted
+		Credentials []string `koanf:"credentials" validate:"omitempty,dive,contains=:"`
+
+		// The number of failed logins allowed before the connection is dropped
+		MaxLoginAttempts int `koanf:"max_login_attempts" validate:"min=1"`
+
+		// Access log configuration
+		AccessLog telnetAccessLogConfig `koanf:"access_log"`
+	}
+
+	telnetAccessLogConfig struct {
+		// The path to write the access logs to (Otherwise stdout)
+		Path string `koanf:"path" validate:"omitempty"`
+
+		// The fields to log in the access logs (Fields that are not relevant to an event are logged as empty)
+		FieldsToLog []string `koanf:"fields_to_log" validate:"omitempty,dive,oneof=timestamp id event src_ip src_port dest_port type user pass success command duration"`
+	}
+
+	// Settings relating to the Redis server
+	redisServerConfig struct {
+		// If the Redis server should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// The port to listen on
+		Port int `koanf:"port" validate:"required,min=1,max=65535"`
+
+		// Host to listen on
+		Host string `koanf:"host" validate:"required"`
+
+		// The Redis version reported by INFO and HELLO
+		Version string `koanf:"version" validate:"required"`
+
+		// Command logging configuration
+		CommandLog redisCommandLogConfig `koanf:"command_log"`
+	}
+
+	redisCommandLogConfig struct {
+		// The path to write the command logs to (Otherwise stdout)
+		Path string `koanf:"path" validate:"omitempty"`
+
+		// A list of commands to log against each connection to the Redis server (All commands are logged by default)
+		CommandsToLog []string `koanf:"commands_to_log" validate:"omitempty,dive,oneof=all all_detailed client_connected client_disconnected protocol_error auth_user hello config_set slaveof set save flushall module_load eval command ping none"`
+
+		// Additional fields to log against each command
+		AdditionalFields []string `koanf:"additional_fields" validate:"omitempty,dive,oneof=id dest_addr src_addr client_version type dest_port src_port src_host dest_host none"`
+	}
+
+	// Settings relating to the MySQL server
+	mysqlServerConfig struct {
+		// If the MySQL server should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// The port to listen on
+		Port int `koanf:"port" validate:"required,min=1,max=65535"`
+
+		// Host to listen on
+		Host string `koanf:"host" validate:"required"`
+
+		// The server version sent to clients in the initial handshake
+		ServerVersion string `koanf:"server_version" validate:"required"`
+
+		// Command logging configuration
+		CommandLog mysqlCommandLogConfig `koanf:"command_log"`
+	}
+
+	mysqlCommandLogConfig struct {
+		// The path to write the command logs to (Otherwise stdout)
+		Path string `koanf:"path" validate:"omitempty"`
+
+		// A list of commands to log against each connection to the MySQL server (All commands are logged by default)
+		CommandsToLog []string `koanf:"commands_to_log" validate:"omitempty,dive,oneof=all all_detailed client_connected client_disconnected handshake_failed auth_user init_db query command ping none"`
+
+		// Additional fields to log against each command
+		AdditionalFields []string `koanf:"additional_fields" validate:"omitempty,dive,oneof=id dest_addr src_addr client_version type dest_port src_port src_host dest_host none"`
+	}
+
+	// Settings relating to the PostgreSQL server
+	postgresServerConfig struct {
+		// If the PostgreSQL server should be enabled
+		Enabled bool `koanf:"enabled"`
+
+		// The port to listen on
+		Port int `koanf:"port" validate:"required,min=1,max=65535"`
+
+		// Host to listen on
+		Host string `koanf:"host" validate:"required"`
+
+		// The server version reported to clients once logged in
+		ServerVersion string `koanf:"server_version" validate:"required"`
+
+		// The password authentication requested from clients. The modes are as follows:
+		// cleartext - Clients send their password as is
+		// md5       - Clients send an md5 hash of their password salted with the username and a random salt
+		AuthMethod string `koanf:"auth_method" validate:"required,oneof=cleartext md5"`
+
+		// Command logging configuration
+		CommandLog postgresCommandLogConfig `koanf:"command_log"`
+	}
+
+	postgresCommandLogConfig struct {
+		// The path to write the command logs to (Otherwise stdout)
+		Path string `koanf:"path" validate:"omitempty"`
+
+		// A list of commands to log against each connection to the PostgreSQL server (All commands are logged by default)
+		CommandsToLog []string `koanf:"commands_to_log" validate:"omitempty,dive,oneof=all all_detailed client_connected client_disconnected ssl_request startup auth_user query parse command none"`
+
+		// Additional fields to log against each command
+		AdditionalFields []string `koanf:"additional_fields" validate:"omitempty,dive,oneof=id dest_addr src_addr client_version type dest_port src_port src_host dest_host none"`
+	}
+
 	// Cluster specific configuration
 	clusterConfig struct {
 		// If cluster mode is enabled (Nodes will become aware of each other)
@@ -165,7 +408,10 @@ type (
 		Mode string `koanf:"mode" validate:"omitempty,oneof=start end both none"`
 
 		// The fields to log in the access logs (Note that not all fields are aviailable for all protocols and will be omitted if not present)
-		FieldsToLog []string `koanf:"fields_to_log" validate:"omitempty,dive,oneof=timestamp status src_ip method path qs dest_port type host user_agent browser browser_version os os_version device device_brand phase duration id"`
+		FieldsToLog []string `koanf:"fields_to_log" validate:"omitempty,dive,oneof=timestamp status src_ip method path qs stream_number dest_port type host user_agent browser browser_version os os_version device device_brand phase duration id body body_size"`
+
+		// The maximum number of bytes of a request body to log. Anything past this is cut off
+		MaxBodySize int `koanf:"max_body_size" validate:"omitempty,min=1"`
 	}
 
 	// Timeout watcher specific configuration
@@ -302,6 +548,217 @@ type (
 
 		// The transfer rate for the staller (bytes per second)
 		BytesPerSecond int `koanf:"bytes_per_second" validate:"omitempty,min=1"`
+
+		// How data is paced when trickled to HTTP and FTP clients
+		Pacing stallerPacingConfig `koanf:"pacing"`
+
+		// The scheduler deciding when each staller next sends data
+		Scheduler stallerSchedulerConfig `koanf:"scheduler"`
+	}
+
+	stallerSchedulerConfig struct {
+		// How often the scheduler checks for stallers due to send data (In milliseconds). Sends are never early but can be up to this late
+		TickMs int `koanf:"tick_ms" validate:"required,min=1"`
+
+		// The number of slots in the timer wheel. Stallers waiting longer than tick_ms * slots are checked once every turn of the wheel
+		Slots int `koanf:"slots" validate:"required,min=1"`
+
+		// The number of workers generating and sending data for stallers once they are due (0 for one per CPU)
+		Workers int `koanf:"workers" validate:"min=0"`
+	}
+
+	stallerPacingConfig struct {
+		// The pacer used for clients not matching a profile. The pacers are as follows:
+		// constant  - Sends data at a fixed rate (bytes_per_second for HTTP, ftp_server.transfer for FTP)
+		// jitter    - Sends data at the constant rate with a random variation in the time between each send
+		// bursty    - Sends data in bursts. Fast to start with and then slowing to a burst every interval
+		// decay     - Sends data at the constant rate to start with and then slows down exponentially
+		// keepalive - Sends data once every interval. The interval should be just under the read timeout of clients
+		Pacer string `koanf:"pacer" validate:"required,oneof=constant jitter bursty decay keepalive"`
+
+		Jitter    stallerJitterPacerConfig    `koanf:"jitter"`
+		Bursty    stallerBurstyPacerConfig    `koanf:"bursty"`
+		Decay     stallerDecayPacerConfig     `koanf:"decay"`
+		Keepalive stallerKeepalivePacerConfig `koanf:"keepalive"`
+
+		// Pacers used for clients matching a profile. The first matching profile is used
+		Profiles []stallerPacingProfileConfig `koanf:"profiles" validate:"omitempty,dive"`
+	}
+
+	stallerJitterPacerConfig struct {
+		// How far the time between each send can vary from the constant rate (i.e 0.5 for +/- 50%)
+		Spread float64 `koanf:"spread" validate:"required,gt=0,lte=1"`
+	}
+
+	stallerBurstyPacerConfig struct {
+		// The number of bytes sent at the constant rate before slowing down
+		InitialBytes int `koanf:"initial_bytes" validate:"min=0"`
+
+		// The number of bytes sent at once in each burst
+		BurstSize int `koanf:"burst_size" validate:"required,min=1"`
+
+		// The time between each burst once slowed down (In milliseconds)
+		IntervalMs int `koanf:"interval_ms" validate:"required,min=1"`
+	}
+
+	stallerDecayPacerConfig struct {
+		// The amount the time between each send is multiplied by after every send
+		Factor float64 `koanf:"factor" validate:"required,gt=1"`
+
+		// The longest time between each send (In milliseconds)
+		MaxDelayMs int `koanf:"max_delay_ms" validate:"required,min=1"`
+	}
+
+	stallerKeepalivePacerConfig struct {
+		// The time between each send (In milliseconds)
+		IntervalMs int `koanf:"interval_ms" validate:"required,min=1"`
+	}
+
+	stallerPacingProfileConfig struct {
+		// Protocols the profile applies to (http or ftp). Applies to both if empty
+		Protocols []string `koanf:"protocols" validate:"omitempty,dive,oneof=http ftp"`
+
+		// A regular expression matched against the requested path (or file name)
+		Path string `koanf:"path"`
+
+		// A regular expression matched against the user agent of the client. For FTP clients this is the client version sent with CLNT (if any)
+		UserAgent string `koanf:"user_agent"`
+
+		// The pacer used for clients matching the profile
+		Pacer string `koanf:"pacer" validate:"required,oneof=constant jitter bursty decay keepalive"`
+	}
+
+	// Configuration for the data generated by the honeypot
+	generatorConfig struct {
+		// The JSON schemas structured files (json, yaml, xml etc) are generated from
+		Schemas generatorSchemasConfig `koanf:"schemas"`
+
+		// How fake secrets are picked
+		Secrets generatorSecretsConfig `koanf:"secrets"`
+
+		// Derives every stream from a secret and the client it was served to so it can be generated again with go-pot replay
+		Replay generatorReplayConfig `koanf:"replay"`
+
+		// The tables tabular files (csv, sql etc) are generated from
+		Tabular generatorTabularConfig `koanf:"tabular"`
+	}
+
+	generatorTabularConfig struct {
+		// Additional YAML files of tables in the same format as generator/source/tabular-schemas.yml
+		SchemaFiles []string `koanf:"schema_files" validate:"omitempty,dive,file"`
+
+		// Tables defined inline. Tables from the config are matched before those from files followed by the embedded tables
+		Schemas []generatorTabularSchemaConfig `koanf:"schemas" validate:"omitempty,dive"`
+
+		// The table served for paths that do not match the pattern of any table
+		DefaultTable string `koanf:"default_table" validate:"required"`
+
+		// How SQL files are dumped
+		Dump generatorTabularDumpConfig `koanf:"dump"`
+	}
+
+	generatorTabularDumpConfig struct {
+		// The dialect dumps are written in. The dialects are as follows:
+		// auto     - Picked by the requested path (i.e pg_dump.sql and .pgsql files are dumped by PostgreSQL)
+		// mysql    - Every dump looks like the output of mysqldump
+		// postgres - Every dump looks like the output of pg_dump
+		Dialect string `koanf:"dialect" validate:"required,oneof=auto mysql postgres"`
+
+		// Tables dumped after the table matching the requested path. The last table is dumped forever
+		Tables []string `koanf:"tables" validate:"required,min=1"`
+
+		// The number of rows in each INSERT statement
+		RowsPerInsert int `koanf:"rows_per_insert" validate:"required,min=1"`
+	}
+
+	generatorTabularSchemaConfig struct {
+		// The name of the table
+		Table string `koanf:"table" validate:"required"`
+
+		// A regular expression matched against the requested path (or file name)
+		Pattern string `koanf:"pattern" validate:"required"`
+
+		// The columns of the table in order
+		Columns []generatorTabularColumnConfig `koanf:"columns" validate:"required,min=1,dive"`
+	}
+
+	generatorTabularColumnConfig struct {
+		// The name of the column
+		Name string `koanf:"name" validate:"required"`
+
+		// Values are generated from exactly one of a named type (i.e email), a regex, the name of a secret rule or a list of values
+		Type   string   `koanf:"type"`
+		Regex  string   `koanf:"regex"`
+		Secret string   `koanf:"secret"`
+		Values []string `koanf:"values"`
+
+		// The type of the column in CREATE TABLE statements. Defaults to one matching the type of the column
+		SqlType string `koanf:"sql_type"`
+	}
+
+	generatorReplayConfig struct {
+		// If streams should be seeded so they can be replayed. Each stream is numbered per client and day in the logs
+		Enabled bool `koanf:"enabled"`
+
+		// The node secret streams are seeded with. Streams can only be replayed with the secret they were served with
+		Secret string `koanf:"secret" validate:"required_if=Enabled true"`
+	}
+
+	generatorSecretsConfig struct {
+		// Additional YAML files of secret rules in the same format as secrets/secret-rules.yml. Rules replace embedded rules with the same name
+		RuleFiles []string `koanf:"rule_files" validate:"omitempty,dive,file"`
+
+		// Overrides the weight of secret rules by rule name (i.e aws-access-token). Rules are picked in proportion
+		// to their weight so higher weights make a rule more common. A weight of 0 stops a rule being picked at random
+		Weights map[string]int `koanf:"weights" validate:"omitempty,dive,min=0"`
+
+		// Weights used for requested paths matching a pattern on top of the weights above. The first matching profile is used
+		Profiles []generatorSecretsProfileConfig `koanf:"profiles" validate:"omitempty,dive"`
+
+		// Makes each secret served unique to the client it was served to so leaked secrets can be traced back to them
+		Honeytokens generatorHoneytokensConfig `koanf:"honeytokens"`
+	}
+
+	generatorHoneytokensConfig struct {
+		// If secrets should be fingerprinted and recorded
+		Enabled bool `koanf:"enabled"`
+
+		// The key fingerprints are signed with. If not given a key is generated on startup meaning secrets served before
+		// a restart can still be traced but can no longer be verified
+		Key string `koanf:"key"`
+
+		// The file every secret served is recorded in along with who it was served to
+		LedgerPath string `koanf:"ledger_path" validate:"required_if=Enabled true"`
+	}
+
+	generatorSecretsProfileConfig struct {
+		// A regular expression matched against the requested path (or file name)
+		Pattern string `koanf:"pattern" validate:"required"`
+
+		// Weights by rule name for paths matching the pattern
+		Weights map[string]int `koanf:"weights" validate:"required,dive,min=0"`
+	}
+
+	generatorSchemasConfig struct {
+		// Directories of additional JSON schema files (*.json) to generate configs from
+		Directories []string `koanf:"directories" validate:"omitempty,dive,dir"`
+
+		// How schemas from the directories are used. The modes are as follows:
+		// merge   - Schemas are used alongside the embedded schemas
+		// replace - Only schemas from the directories are used
+		Mode string `koanf:"mode" validate:"required,oneof=merge replace"`
+
+		// Maps requested paths onto the schema to generate them from. The first mapping with a matching
+		// pattern is used and paths without a match fall back to a random schema
+		PathMappings []generatorSchemaMappingConfig `koanf:"path_mappings" validate:"omitempty,dive"`
+	}
+
+	generatorSchemaMappingConfig struct {
+		// A regular expression matched against the requested path (or file name)
+		Pattern string `koanf:"pattern" validate:"required"`
+
+		// The schema to generate from. Either the file name of an embedded schema or of a file in one of the schema directories
+		Schema string `koanf:"schema" validate:"required"`
 	}
 )
 
@@ -339,8 +796,26 @@ func NewConfig(cmd *cobra.Command, flagsUsed flagMap) (*Config, error) {
 	setStringSlice(k, "cluster.known_peer_ips")
 	setStringSlice(k, "server.trusted_proxies")
 	setStringSlice(k, "server.access_log.fields_to_log")
+	setStringSlice(k, "server.tls.dns_names")
+	setStringSlice(k, "server.tls.ip_addresses")
+	setStringSlice(k, "generator.schemas.directories")
+	setStringSlice(k, "generator.secrets.rule_files")
+	setStringSlice(k, "generator.tabular.schema_files")
+	setStringSlice(k, "generator.tabular.dump.tables")
 	setStringSlice(k, "ftp_server.command_log.commands_to_log")
 	setStringSlice(k, "ftp_server.command_log.additional_fields")
+	setStringSlice(k, "ssh_server.command_log.commands_to_log")
+	setStringSlice(k, "ssh_server.command_log.additional_fields")
+	setStringSlice(k, "smtp_server.command_log.commands_to_log")
+	setStringSlice(k, "smtp_server.command_log.additional_fields")
+	setStringSlice(k, "telnet_server.credentials")
+	setStringSlice(k, "telnet_server.access_log.fields_to_log")
+	setStringSlice(k, "redis_server.command_log.commands_to_log")
+	setStringSlice(k, "redis_server.command_log.additional_fields")
+	setStringSlice(k, "mysql_server.command_log.commands_to_log")
+	setStringSlice(k, "mysql_server.command_log.additional_fields")
+	setStringSlice(k, "postgres_server.command_log.commands_to_log")
+	setStringSlice(k, "postgres_server.command_log.additional_fields")
 
 	var cfg *Config
 	if err := k.UnmarshalWithConf("", &cfg, koanf.UnmarshalConf{Tag: "koanf"}); err != nil {
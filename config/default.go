@@ -20,6 +20,26 @@ var defaultConfig = Config{
 				"qs",
 				"duration",
 			},
+			MaxBodySize: 1024 * 64, // 64Kb
+		},
+		Tls: httpTlsConfig{
+			Enabled:      false,
+			Port:         8443,
+			CommonName:   "portal.example.com",
+			Organization: "Example Corp",
+			DnsNames: []string{
+				"portal.example.com",
+				"vpn.example.com",
+				"*.internal.example.com",
+			},
+			IpAddresses: []string{},
+			ValidDays:   397,
+			Issuer: httpTlsIssuerConfig{
+				CommonName:         "Example Corp Issuing CA 01",
+				Organization:       "Example Corp",
+				OrganizationalUnit: "IT Security",
+				Country:            "US",
+			},
 		},
 	},
 	FtpServer: ftpServerConfig{
@@ -46,6 +66,128 @@ var defaultConfig = Config{
 			},
 		},
 	},
+	SshServer: sshServerConfig{
+		Enabled:       false,
+		Port:          2222,
+		Host:          "0.0.0.0",
+		Mode:          "shell",
+		ServerVersion: "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.6",
+		ShellHostname: "prod-db-01",
+		CommandLog: sshCommandLogConfig{
+			CommandsToLog: []string{
+				"all",
+			},
+			AdditionalFields: []string{
+				"id",
+				"src_host",
+			},
+		},
+	},
+	SmtpServer: smtpServerConfig{
+		Enabled:        false,
+		Port:           2525,
+		Host:           "0.0.0.0",
+		Hostname:       "mail.example.com",
+		Banner:         "ESMTP Postfix (Ubuntu)",
+		MaxBodyLogSize: 64 * 1024,
+		CommandLog: smtpCommandLogConfig{
+			CommandsToLog: []string{
+				"all",
+			},
+			AdditionalFields: []string{
+				"id",
+				"src_host",
+			},
+		},
+	},
+	TelnetServer: telnetServerConfig{
+		Enabled:  false,
+		Port:     2323,
+		Host:     "0.0.0.0",
+		Hostname: "router",
+		// Common factory default credentials tried by IoT botnets
+		Credentials: []string{
+			"root:xc3511",
+			"root:vizxv",
+			"root:admin",
+			"admin:admin",
+			"root:888888",
+			"root:xmhdipc",
+			"root:default",
+			"root:juantech",
+			"root:123456",
+			"root:54321",
+			"support:support",
+			"root:root",
+			"root:12345",
+			"user:user",
+			"admin:password",
+			"root:pass",
+			"admin:1234",
+			"root:1111",
+			"guest:guest",
+			"root:anko",
+		},
+		MaxLoginAttempts: 3,
+		AccessLog: telnetAccessLogConfig{
+			FieldsToLog: []string{
+				"id",
+				"event",
+				"src_ip",
+				"user",
+				"pass",
+				"success",
+				"command",
+				"duration",
+			},
+		},
+	},
+	RedisServer: redisServerConfig{
+		Enabled: false,
+		Port:    6379,
+		Host:    "0.0.0.0",
+		Version: "7.0.15",
+		CommandLog: redisCommandLogConfig{
+			CommandsToLog: []string{
+				"all",
+			},
+			AdditionalFields: []string{
+				"id",
+				"src_host",
+			},
+		},
+	},
+	MysqlServer: mysqlServerConfig{
+		Enabled:       false,
+		Port:          3306,
+		Host:          "0.0.0.0",
+		ServerVersion: "8.0.36-0ubuntu0.22.04.1",
+		CommandLog: mysqlCommandLogConfig{
+			CommandsToLog: []string{
+				"all",
+			},
+			AdditionalFields: []string{
+				"id",
+				"src_host",
+			},
+		},
+	},
+	PostgresServer: postgresServerConfig{
+		Enabled:       false,
+		Port:          5432,
+		Host:          "0.0.0.0",
+		ServerVersion: "14.11 (Ubuntu 14.11-0ubuntu0.22.04.1)",
+		AuthMethod:    "cleartext",
+		CommandLog: postgresCommandLogConfig{
+			CommandsToLog: []string{
+				"all",
+			},
+			AdditionalFields: []string{
+				"id",
+				"src_host",
+			},
+		},
+	},
 	Logging: loggingConfig{
 		Level:             zapcore.InfoLevel.String(),
 		StartUpLogEnabled: true,
@@ -116,5 +258,78 @@ var defaultConfig = Config{
 		MaximumConnections: 200,
 		GroupLimit:         50,
 		BytesPerSecond:     8,
+		Pacing: stallerPacingConfig{
+			Pacer: "constant",
+			Jitter: stallerJitterPacerConfig{
+				Spread: 0.5,
+			},
+			Bursty: stallerBurstyPacerConfig{
+				InitialBytes: 256,
+				BurstSize:    64,
+				IntervalMs:   5000,
+			},
+			Decay: stallerDecayPacerConfig{
+				Factor:     1.05,
+				MaxDelayMs: 20000,
+			},
+			Keepalive: stallerKeepalivePacerConfig{
+				IntervalMs: 25000,
+			},
+			Profiles: []stallerPacingProfileConfig{},
+		},
+		Scheduler: stallerSchedulerConfig{
+			TickMs:  10,
+			Slots:   4096,
+			Workers: 0,
+		},
+	},
+	Generator: generatorConfig{
+		Schemas: generatorSchemasConfig{
+			Directories: []string{},
+			Mode:        "merge",
+			PathMappings: []generatorSchemaMappingConfig{
+				{Pattern: `(^|/)package\.json$`, Schema: "package.json"},
+				{Pattern: `(^|/)composer\.json$`, Schema: "composer.json"},
+				{Pattern: `(^|/)appsettings(\.[\w-]+)?\.json$`, Schema: "appsettings.json"},
+				{Pattern: `(^|/)(docker-)?compose(\.[\w-]+)?\.ya?ml$`, Schema: "docker-compose.json"},
+				{Pattern: `(^|/)serverless\.ya?ml$`, Schema: "serverless.json"},
+				{Pattern: `(^|/)[tj]sconfig(\.[\w-]+)?\.json$`, Schema: "tsconfig.json"},
+				{Pattern: `(^|/)lerna\.json$`, Schema: "lerna.json"},
+				{Pattern: `(^|/)bower\.json$`, Schema: "bower.json"},
+				{Pattern: `(^|/)netlify\.json$`, Schema: "netlify.json"},
+				{Pattern: `(^|/)now\.json$`, Schema: "now.json"},
+				{Pattern: `(^|/)pubspec\.ya?ml$`, Schema: "pubspec.json"},
+				{Pattern: `(^|/)dependabot\.ya?ml$`, Schema: "dependabot.json"},
+				{Pattern: `(^|/)ecosystem\.config\.json$`, Schema: "pm2-ecosystem.json"},
+				{Pattern: `(^|/)launchSettings\.json$`, Schema: "launchsettings.json"},
+				{Pattern: `(^|/)(manifest\.json|site\.webmanifest)$`, Schema: "web-manifest.json"},
+				{Pattern: `(^|/)\.eslintrc\.(json|ya?ml)$`, Schema: "eslintrc.json"},
+				{Pattern: `(^|/)daemon\.json$`, Schema: "dockerd.json"},
+			},
+		},
+		Secrets: generatorSecretsConfig{
+			RuleFiles: []string{},
+			Weights:   map[string]int{},
+			Profiles:  []generatorSecretsProfileConfig{},
+			Honeytokens: generatorHoneytokensConfig{
+				Enabled:    false,
+				Key:        "",
+				LedgerPath: "honeytokens.log",
+			},
+		},
+		Replay: generatorReplayConfig{
+			Enabled: false,
+			Secret:  "",
+		},
+		Tabular: generatorTabularConfig{
+			SchemaFiles:  []string{},
+			Schemas:      []generatorTabularSchemaConfig{},
+			DefaultTable: "users",
+			Dump: generatorTabularDumpConfig{
+				Dialect:       "auto",
+				Tables:        []string{"users", "api_keys", "sessions"},
+				RowsPerInsert: 50,
+			},
+		},
 	},
 }
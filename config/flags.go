@@ -79,6 +79,62 @@ var commonFlags = flagMap{
 		configType:   "int",
 		defaultValue: defaultConfig.Staller.MaximumConnections,
 	},
+	"schema-dirs": {
+		flagName:     "schema-dirs",
+		configKey:    "generator.schemas.directories",
+		description:  "Directories of additional JSON schema files to generate configs from as comma separated values.",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.Generator.Schemas.Directories, ","),
+	},
+	"schema-mode": {
+		flagName:     "schema-mode",
+		configKey:    "generator.schemas.mode",
+		description:  "How schemas from the schema directories are used. Options: merge (alongside the embedded schemas), replace (instead of the embedded schemas).",
+		configType:   "string",
+		defaultValue: defaultConfig.Generator.Schemas.Mode,
+	},
+	"secret-rule-files": {
+		flagName:     "secret-rule-files",
+		configKey:    "generator.secrets.rule_files",
+		description:  "YAML files of additional secret rules to generate secrets from as comma separated values.",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.Generator.Secrets.RuleFiles, ","),
+	},
+	"tabular-schema-files": {
+		flagName:     "tabular-schema-files",
+		configKey:    "generator.tabular.schema_files",
+		description:  "YAML files of additional tables to generate tabular files (csv, sql etc) from as comma separated values.",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.Generator.Tabular.SchemaFiles, ","),
+	},
+	"sql-dump-dialect": {
+		flagName:     "sql-dump-dialect",
+		configKey:    "generator.tabular.dump.dialect",
+		description:  "The dialect SQL files are dumped in. Options: auto (picked by path), mysql, postgres",
+		configType:   "string",
+		defaultValue: defaultConfig.Generator.Tabular.Dump.Dialect,
+	},
+	"honeytokens-enabled": {
+		flagName:     "honeytokens-enabled",
+		configKey:    "generator.secrets.honeytokens.enabled",
+		description:  "Make every secret served unique to the client it was served to and record it in the honeytoken ledger.",
+		configType:   "bool",
+		defaultValue: defaultConfig.Generator.Secrets.Honeytokens.Enabled,
+	},
+	"honeytoken-ledger": {
+		flagName:     "honeytoken-ledger",
+		configKey:    "generator.secrets.honeytokens.ledger_path",
+		description:  "The file honeytokens are recorded in along with who they were served to.",
+		configType:   "string",
+		defaultValue: defaultConfig.Generator.Secrets.Honeytokens.LedgerPath,
+	},
+	"replay-enabled": {
+		flagName:     "replay-enabled",
+		configKey:    "generator.replay.enabled",
+		description:  "Seed every stream from the replay secret so it can be generated again with the replay command.",
+		configType:   "bool",
+		defaultValue: defaultConfig.Generator.Replay.Enabled,
+	},
 	"log-path": {
 		flagName:     "log-path",
 		configKey:    "logging.path",
@@ -132,6 +188,48 @@ var httpFlags = flagMap{
 		configType:   "string",
 		defaultValue: strings.Join(defaultConfig.Server.AccessLog.FieldsToLog, ","),
 	},
+	"https-enabled": {
+		flagName:     "https-enabled",
+		configKey:    "server.tls.enabled",
+		description:  "Serve HTTPS alongside plain HTTP.",
+		configType:   "bool",
+		defaultValue: defaultConfig.Server.Tls.Enabled,
+	},
+	"https-port": {
+		flagName:     "https-port",
+		configKey:    "server.tls.port",
+		description:  "The port for the HTTPS listener to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.Server.Tls.Port,
+	},
+	"https-cert-file": {
+		flagName:     "https-cert-file",
+		configKey:    "server.tls.cert_file",
+		description:  "The certificate to serve HTTPS with. (If not set, a certificate will be generated on startup.)",
+		configType:   "string",
+		defaultValue: defaultConfig.Server.Tls.CertFile,
+	},
+	"https-key-file": {
+		flagName:     "https-key-file",
+		configKey:    "server.tls.key_file",
+		description:  "The private key for the HTTPS certificate.",
+		configType:   "string",
+		defaultValue: defaultConfig.Server.Tls.KeyFile,
+	},
+	"https-dns-names": {
+		flagName:     "https-dns-names",
+		configKey:    "server.tls.dns_names",
+		description:  "The DNS names to add to the generated HTTPS certificate as comma separated values.",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.Server.Tls.DnsNames, ","),
+	},
+	"http-access-log-max-body-size": {
+		flagName:     "http-access-log-max-body-size",
+		configKey:    "server.access_log.max_body_size",
+		description:  "The maximum number of bytes of a request body to write to the http access log.",
+		configType:   "int",
+		defaultValue: defaultConfig.Server.AccessLog.MaxBodySize,
+	},
 	"cluster-mode-enabled": {
 		flagName:     "cluster-mode-enabled",
 		configKey:    "cluster.enabled",
@@ -174,6 +272,13 @@ var httpFlags = flagMap{
 		configType:   "int",
 		defaultValue: defaultConfig.Staller.BytesPerSecond,
 	},
+	"pacer": {
+		flagName:     "pacer",
+		configKey:    "staller.pacing.pacer",
+		description:  "How data is paced when trickled to clients. Options: constant, jitter, bursty, decay, keepalive.",
+		configType:   "string",
+		defaultValue: defaultConfig.Staller.Pacing.Pacer,
+	},
 }
 
 var ftpFlags = flagMap{
@@ -219,6 +324,269 @@ var ftpFlags = flagMap{
 		configType:   "string",
 		defaultValue: strings.Join(defaultConfig.FtpServer.CommandLog.AdditionalFields, ","),
 	},
+	"pacer": httpFlags["pacer"],
+}
+
+var sshFlags = flagMap{
+	"ssh-port": {
+		flagName:     "ssh-port",
+		configKey:    "ssh_server.port",
+		description:  "The port for the SSH service to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.SshServer.Port,
+	},
+	"ssh-host": {
+		flagName:     "ssh-host",
+		configKey:    "ssh_server.host",
+		description:  "The host for the SSH service to listen on.",
+		configType:   "string",
+		defaultValue: defaultConfig.SshServer.Host,
+	},
+	"ssh-mode": {
+		flagName:     "ssh-mode",
+		configKey:    "ssh_server.mode",
+		description:  "The mode for the SSH service. Options: banner (endless pre-banner lines), shell (accept any password and serve a slow fake shell).",
+		configType:   "string",
+		defaultValue: defaultConfig.SshServer.Mode,
+	},
+	"ssh-host-key-path": {
+		flagName:     "ssh-host-key-path",
+		configKey:    "ssh_server.host_key_path",
+		description:  "The path to a PEM encoded SSH host key. (If not set, one will be generated on startup.)",
+		configType:   "string",
+		defaultValue: defaultConfig.SshServer.HostKeyPath,
+	},
+	"ssh-log-path": {
+		flagName:     "ssh-log-path",
+		configKey:    "ssh_server.command_log.path",
+		description:  "The path to write the ssh command log to. (If not set, logs will be written to stdout.)",
+		configType:   "string",
+		defaultValue: defaultConfig.SshServer.CommandLog.Path,
+	},
+	"ssh-log-commands": {
+		flagName:     "ssh-log-commands",
+		configKey:    "ssh_server.command_log.commands_to_log",
+		description:  "The commands to log in the ssh command log as comma separated values. (Lookup documentation for available commands.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.SshServer.CommandLog.CommandsToLog, ","),
+	},
+	"ssh-log-fields": {
+		flagName:     "ssh-log-fields",
+		configKey:    "ssh_server.command_log.additional_fields",
+		description:  "The additional fields to log in each line of the SSH log. (Lookup documentation for available fields.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.SshServer.CommandLog.AdditionalFields, ","),
+	},
+	"bytes-per-second": httpFlags["bytes-per-second"],
+}
+
+var smtpFlags = flagMap{
+	"smtp-port": {
+		flagName:     "smtp-port",
+		configKey:    "smtp_server.port",
+		description:  "The port for the SMTP service to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.SmtpServer.Port,
+	},
+	"smtp-host": {
+		flagName:     "smtp-host",
+		configKey:    "smtp_server.host",
+		description:  "The host for the SMTP service to listen on.",
+		configType:   "string",
+		defaultValue: defaultConfig.SmtpServer.Host,
+	},
+	"smtp-hostname": {
+		flagName:     "smtp-hostname",
+		configKey:    "smtp_server.hostname",
+		description:  "The hostname the SMTP service announces itself as.",
+		configType:   "string",
+		defaultValue: defaultConfig.SmtpServer.Hostname,
+	},
+	"smtp-log-path": {
+		flagName:     "smtp-log-path",
+		configKey:    "smtp_server.command_log.path",
+		description:  "The path to write the smtp command log to. (If not set, logs will be written to stdout.)",
+		configType:   "string",
+		defaultValue: defaultConfig.SmtpServer.CommandLog.Path,
+	},
+	"smtp-log-commands": {
+		flagName:     "smtp-log-commands",
+		configKey:    "smtp_server.command_log.commands_to_log",
+		description:  "The commands to log in the smtp command log as comma separated values. (Lookup documentation for available commands.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.SmtpServer.CommandLog.CommandsToLog, ","),
+	},
+	"smtp-log-fields": {
+		flagName:     "smtp-log-fields",
+		configKey:    "smtp_server.command_log.additional_fields",
+		description:  "The additional fields to log in each line of the SMTP log. (Lookup documentation for available fields.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.SmtpServer.CommandLog.AdditionalFields, ","),
+	},
+	"bytes-per-second": httpFlags["bytes-per-second"],
+}
+
+var telnetFlags = flagMap{
+	"telnet-port": {
+		flagName:     "telnet-port",
+		configKey:    "telnet_server.port",
+		description:  "The port for the Telnet service to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.TelnetServer.Port,
+	},
+	"telnet-host": {
+		flagName:     "telnet-host",
+		configKey:    "telnet_server.host",
+		description:  "The host for the Telnet service to listen on.",
+		configType:   "string",
+		defaultValue: defaultConfig.TelnetServer.Host,
+	},
+	"telnet-credentials": {
+		flagName:     "telnet-credentials",
+		configKey:    "telnet_server.credentials",
+		description:  "The credentials accepted by the Telnet login prompt as comma separated user:pass values. (If empty, any credentials are accepted.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.TelnetServer.Credentials, ","),
+	},
+	"telnet-access-log-path": {
+		flagName:     "telnet-access-log-path",
+		configKey:    "telnet_server.access_log.path",
+		description:  "The path to write the telnet access log to. (If not set, logs will be written to stdout.)",
+		configType:   "string",
+		defaultValue: defaultConfig.TelnetServer.AccessLog.Path,
+	},
+	"telnet-access-log-fields": {
+		flagName:     "telnet-access-log-fields",
+		configKey:    "telnet_server.access_log.fields_to_log",
+		description:  "The fields to log in the telnet access log as comma separated values. (Lookup documentation for available fields.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.TelnetServer.AccessLog.FieldsToLog, ","),
+	},
+	"bytes-per-second": httpFlags["bytes-per-second"],
+}
+
+var redisFlags = flagMap{
+	"redis-port": {
+		flagName:     "redis-port",
+		configKey:    "redis_server.port",
+		description:  "The port for the Redis service to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.RedisServer.Port,
+	},
+	"redis-host": {
+		flagName:     "redis-host",
+		configKey:    "redis_server.host",
+		description:  "The host for the Redis service to listen on.",
+		configType:   "string",
+		defaultValue: defaultConfig.RedisServer.Host,
+	},
+	"redis-log-path": {
+		flagName:     "redis-log-path",
+		configKey:    "redis_server.command_log.path",
+		description:  "The path to write the redis command log to. (If not set, logs will be written to stdout.)",
+		configType:   "string",
+		defaultValue: defaultConfig.RedisServer.CommandLog.Path,
+	},
+	"redis-log-commands": {
+		flagName:     "redis-log-commands",
+		configKey:    "redis_server.command_log.commands_to_log",
+		description:  "The commands to log in the redis command log as comma separated values. (Lookup documentation for available commands.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.RedisServer.CommandLog.CommandsToLog, ","),
+	},
+	"redis-log-fields": {
+		flagName:     "redis-log-fields",
+		configKey:    "redis_server.command_log.additional_fields",
+		description:  "The additional fields to log in each line of the Redis log. (Lookup documentation for available fields.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.RedisServer.CommandLog.AdditionalFields, ","),
+	},
+	"bytes-per-second": httpFlags["bytes-per-second"],
+}
+
+var mysqlFlags = flagMap{
+	"mysql-port": {
+		flagName:     "mysql-port",
+		configKey:    "mysql_server.port",
+		description:  "The port for the MySQL service to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.MysqlServer.Port,
+	},
+	"mysql-host": {
+		flagName:     "mysql-host",
+		configKey:    "mysql_server.host",
+		description:  "The host for the MySQL service to listen on.",
+		configType:   "string",
+		defaultValue: defaultConfig.MysqlServer.Host,
+	},
+	"mysql-log-path": {
+		flagName:     "mysql-log-path",
+		configKey:    "mysql_server.command_log.path",
+		description:  "The path to write the mysql command log to. (If not set, logs will be written to stdout.)",
+		configType:   "string",
+		defaultValue: defaultConfig.MysqlServer.CommandLog.Path,
+	},
+	"mysql-log-commands": {
+		flagName:     "mysql-log-commands",
+		configKey:    "mysql_server.command_log.commands_to_log",
+		description:  "The commands to log in the mysql command log as comma separated values. (Lookup documentation for available commands.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.MysqlServer.CommandLog.CommandsToLog, ","),
+	},
+	"mysql-log-fields": {
+		flagName:     "mysql-log-fields",
+		configKey:    "mysql_server.command_log.additional_fields",
+		description:  "The additional fields to log in each line of the MySQL log. (Lookup documentation for available fields.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.MysqlServer.CommandLog.AdditionalFields, ","),
+	},
+	"bytes-per-second": httpFlags["bytes-per-second"],
+}
+
+var postgresFlags = flagMap{
+	"postgres-port": {
+		flagName:     "postgres-port",
+		configKey:    "postgres_server.port",
+		description:  "The port for the PostgreSQL service to listen on.",
+		configType:   "int",
+		defaultValue: defaultConfig.PostgresServer.Port,
+	},
+	"postgres-host": {
+		flagName:     "postgres-host",
+		configKey:    "postgres_server.host",
+		description:  "The host for the PostgreSQL service to listen on.",
+		configType:   "string",
+		defaultValue: defaultConfig.PostgresServer.Host,
+	},
+	"postgres-auth-method": {
+		flagName:     "postgres-auth-method",
+		configKey:    "postgres_server.auth_method",
+		description:  "The password authentication requested from clients. Options: cleartext, md5.",
+		configType:   "string",
+		defaultValue: defaultConfig.PostgresServer.AuthMethod,
+	},
+	"postgres-log-path": {
+		flagName:     "postgres-log-path",
+		configKey:    "postgres_server.command_log.path",
+		description:  "The path to write the postgres command log to. (If not set, logs will be written to stdout.)",
+		configType:   "string",
+		defaultValue: defaultConfig.PostgresServer.CommandLog.Path,
+	},
+	"postgres-log-commands": {
+		flagName:     "postgres-log-commands",
+		configKey:    "postgres_server.command_log.commands_to_log",
+		description:  "The commands to log in the postgres command log as comma separated values. (Lookup documentation for available commands.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.PostgresServer.CommandLog.CommandsToLog, ","),
+	},
+	"postgres-log-fields": {
+		flagName:     "postgres-log-fields",
+		configKey:    "postgres_server.command_log.additional_fields",
+		description:  "The additional fields to log in each line of the PostgreSQL log. (Lookup documentation for available fields.)",
+		configType:   "string",
+		defaultValue: strings.Join(defaultConfig.PostgresServer.CommandLog.AdditionalFields, ","),
+	},
+	"bytes-per-second": httpFlags["bytes-per-second"],
 }
 
 var startFlags = flagMap{
@@ -237,6 +605,54 @@ var startFlags = flagMap{
 		configType:   "bool",
 		defaultValue: defaultConfig.FtpServer.Enabled,
 	},
+
+	"ssh-enabled": {
+		flagName:     "ssh-enabled",
+		configKey:    "ssh_server.enabled",
+		description:  "Enable the SSH service.",
+		configType:   "bool",
+		defaultValue: defaultConfig.SshServer.Enabled,
+	},
+
+	"smtp-enabled": {
+		flagName:     "smtp-enabled",
+		configKey:    "smtp_server.enabled",
+		description:  "Enable the SMTP service.",
+		configType:   "bool",
+		defaultValue: defaultConfig.SmtpServer.Enabled,
+	},
+
+	"telnet-enabled": {
+		flagName:     "telnet-enabled",
+		configKey:    "telnet_server.enabled",
+		description:  "Enable the Telnet service.",
+		configType:   "bool",
+		defaultValue: defaultConfig.TelnetServer.Enabled,
+	},
+
+	"redis-enabled": {
+		flagName:     "redis-enabled",
+		configKey:    "redis_server.enabled",
+		description:  "Enable the Redis service.",
+		configType:   "bool",
+		defaultValue: defaultConfig.RedisServer.Enabled,
+	},
+
+	"mysql-enabled": {
+		flagName:     "mysql-enabled",
+		configKey:    "mysql_server.enabled",
+		description:  "Enable the MySQL service.",
+		configType:   "bool",
+		defaultValue: defaultConfig.MysqlServer.Enabled,
+	},
+
+	"postgres-enabled": {
+		flagName:     "postgres-enabled",
+		configKey:    "postgres_server.enabled",
+		description:  "Enable the PostgreSQL service.",
+		configType:   "bool",
+		defaultValue: defaultConfig.PostgresServer.Enabled,
+	},
 }
 
 func GetStartFlags() flagMap {
@@ -245,6 +661,12 @@ func GetStartFlags() flagMap {
 	maps.Copy(allFlags, commonFlags)
 	maps.Copy(allFlags, httpFlags)
 	maps.Copy(allFlags, ftpFlags)
+	maps.Copy(allFlags, sshFlags)
+	maps.Copy(allFlags, smtpFlags)
+	maps.Copy(allFlags, telnetFlags)
+	maps.Copy(allFlags, redisFlags)
+	maps.Copy(allFlags, mysqlFlags)
+	maps.Copy(allFlags, postgresFlags)
 	maps.Copy(allFlags, startFlags)
 
 	return allFlags
@@ -258,6 +680,80 @@ func GetFtpFlags() flagMap {
 	return internalFtpFlags
 }
 
+func GetSshFlags() flagMap {
+	internalSshFlags := make(flagMap)
+	maps.Copy(internalSshFlags, sshFlags)
+	maps.Copy(internalSshFlags, commonFlags)
+
+	return internalSshFlags
+}
+
+func GetSmtpFlags() flagMap {
+	internalSmtpFlags := make(flagMap)
+	maps.Copy(internalSmtpFlags, smtpFlags)
+	maps.Copy(internalSmtpFlags, commonFlags)
+
+	return internalSmtpFlags
+}
+
+func GetTelnetFlags() flagMap {
+	internalTelnetFlags := make(flagMap)
+	maps.Copy(internalTelnetFlags, telnetFlags)
+	maps.Copy(internalTelnetFlags, commonFlags)
+
+	return internalTelnetFlags
+}
+
+func GetRedisFlags() flagMap {
+	internalRedisFlags := make(flagMap)
+	maps.Copy(internalRedisFlags, redisFlags)
+	maps.Copy(internalRedisFlags, commonFlags)
+
+	return internalRedisFlags
+}
+
+func GetMysqlFlags() flagMap {
+	internalMysqlFlags := make(flagMap)
+	maps.Copy(internalMysqlFlags, mysqlFlags)
+	maps.Copy(internalMysqlFlags, commonFlags)
+
+	return internalMysqlFlags
+}
+
+func GetPostgresFlags() flagMap {
+	internalPostgresFlags := make(flagMap)
+	maps.Copy(internalPostgresFlags, postgresFlags)
+	maps.Copy(internalPostgresFlags, commonFlags)
+
+	return internalPostgresFlags
+}
+
+// Flags for tracing honeytokens back to who they were served to
+func GetTraceFlags() flagMap {
+	return flagMap{
+		"honeytoken-ledger": commonFlags["honeytoken-ledger"],
+		"secret-rule-files": commonFlags["secret-rule-files"],
+	}
+}
+
+// Flags for generating streams again with the replay command
+func GetReplayFlags() flagMap {
+	return flagMap{
+		"schema-dirs":          commonFlags["schema-dirs"],
+		"schema-mode":          commonFlags["schema-mode"],
+		"secret-rule-files":    commonFlags["secret-rule-files"],
+		"tabular-schema-files": commonFlags["tabular-schema-files"],
+		"sql-dump-dialect":     commonFlags["sql-dump-dialect"],
+	}
+}
+
+func GetBenchFlags() flagMap {
+	return flagMap{
+		"bytes-per-second": httpFlags["bytes-per-second"],
+		"pacer":            httpFlags["pacer"],
+	}
+}
+
 func GetHttpFlags() flagMap {
 	internalHttpFlags := make(flagMap)
 	maps.Copy(internalHttpFlags, httpFlags)
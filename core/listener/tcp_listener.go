@@ -0,0 +1,110 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Bounds for how long to wait before accepting connections again after a failed accept (i.e running out of file descriptors)
+	minAcceptBackoff = time.Millisecond * 5
+	maxAcceptBackoff = time.Second
+)
+
+type (
+	// Accepts connections for the stream based protocols (SSH, SMTP etc) handing each
+	// connection off to the given handler in its own goroutine
+	TcpListener struct {
+		Host string
+		Port int
+
+		protocol string
+		handler  func(net.Conn)
+		listener net.Listener
+		closing  bool
+		lock     sync.Mutex
+	}
+)
+
+func NewTcpListener(protocol string, host string, port int, handler func(net.Conn)) *TcpListener {
+	return &TcpListener{
+		Host:     host,
+		Port:     port,
+		protocol: protocol,
+		handler:  handler,
+	}
+}
+
+// Starts listening for connections. Blocks until the listener is stopped
+func (l *TcpListener) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", l.Host, l.Port))
+	if err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	if l.closing {
+		l.lock.Unlock()
+		return listener.Close()
+	}
+	l.listener = listener
+	l.lock.Unlock()
+
+	var backoff time.Duration
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+
+			// Back off on failures the same way net/http does so errors that persist do not spin
+			if backoff == 0 {
+				backoff = minAcceptBackoff
+			} else {
+				backoff = min(backoff*2, maxAcceptBackoff)
+			}
+
+			zap.L().Warn("Failed to accept connection", zap.String("protocol", l.protocol), zap.Duration("retry_in", backoff), zap.Error(err))
+			time.Sleep(backoff)
+			continue
+		}
+
+		backoff = 0
+		go l.handle(conn)
+	}
+}
+
+// Runs the handler for a connection. A handler panicking (i.e on input it fails to parse) only drops the
+// connection it was handling rather than taking down every protocol with it
+func (l *TcpListener) handle(conn net.Conn) {
+	defer func() {
+		if err := recover(); err != nil {
+			zap.L().Error("Recovered from panic while handling connection", zap.String("protocol", l.protocol), zap.String("remote_addr", conn.RemoteAddr().String()), zap.Any("error", err), zap.Stack("stack"))
+			conn.Close()
+		}
+	}()
+
+	l.handler(conn)
+}
+
+func (l *TcpListener) Stop() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.closing = true
+	if l.listener == nil {
+		return nil
+	}
+
+	if err := l.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package listener
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// A handler panicking drops the connection it was handling while the listener carries on accepting connections
+func TestTcpListenerRecoversFromHandlerPanic(t *testing.T) {
+	l := NewTcpListener("test", "127.0.0.1", 0, func(conn net.Conn) {
+		buffer := make([]byte, 1)
+		conn.Read(buffer)
+		if buffer[0] == 'p' {
+			panic("malformed input")
+		}
+
+		conn.Write([]byte("ok"))
+		conn.Close()
+	})
+
+	started := make(chan error, 1)
+	go func() {
+		started <- l.Start()
+	}()
+	t.Cleanup(func() {
+		l.Stop()
+		if err := <-started; err != nil {
+			t.Errorf("listener stopped with %v", err)
+		}
+	})
+
+	addr := waitForListener(t, l)
+	if response := dialAndSend(t, addr, 'p'); len(response) != 0 {
+		t.Errorf("expected the panicking handler to close the connection got %q", response)
+	}
+
+	if response := dialAndSend(t, addr, 'g'); string(response) != "ok" {
+		t.Errorf("expected the listener to keep serving got %q", response)
+	}
+}
+
+func waitForListener(t *testing.T, l *TcpListener) string {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second * 5); time.Now().Before(deadline); time.Sleep(time.Millisecond * 10) {
+		l.lock.Lock()
+		listener := l.listener
+		l.lock.Unlock()
+
+		if listener != nil {
+			return listener.Addr().String()
+		}
+	}
+
+	t.Fatal("listener did not start")
+	return ""
+}
+
+func dialAndSend(t *testing.T, addr string, data byte) []byte {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte{data}); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response
+}
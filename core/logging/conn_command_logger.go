@@ -0,0 +1,209 @@
+package logging
+
+import (
+	"net"
+
+	"go.uber.org/zap"
+)
+
+// Command logger shared by the stream based protocols (SSH, SMTP etc). Modeled on the FTP command logger
+// where each protocol has a set of commands that can be toggled on and off and a set of additional
+// fields pulled from the connection that are attached to every log line.
+type (
+	CommandLogger interface {
+		Log(command string, fields ...zap.Field)
+	}
+
+	ConnCommandLogger struct {
+		logger                *zap.Logger
+		protocol              string
+		commandsToLog         map[string]bool
+		verboseCommands       map[string]bool
+		additionalFieldsToLog []string
+	}
+
+	ConnCommandLoggerOptions struct {
+		// The protocol the logger is for (Logged as the "type" field)
+		Protocol string
+
+		// The path to write the command log to. Falls back to the global log path then stdout
+		Path string
+
+		// The global log path
+		FallbackPath string
+
+		// The commands to log ("all" and "all_detailed" are supported as groups)
+		CommandsToLog []string
+
+		// Commands that are only logged if "all_detailed" or the command itself is given
+		VerboseCommands []string
+
+		// Additional fields to log against each command
+		AdditionalFields []string
+	}
+
+	// Details about a single connection used to resolve additional fields
+	ConnContext struct {
+		Id            uint64
+		LocalAddr     net.Addr
+		RemoteAddr    net.Addr
+		ClientVersion string
+	}
+
+	contextBoundConnCommandLogger struct {
+		logger  *ConnCommandLogger
+		context *ConnContext
+	}
+
+	connContextFieldAccessor func(*ConnCommandLogger, *ConnContext) zap.Field
+)
+
+var connContextFieldAccessors = map[string]connContextFieldAccessor{
+	"id": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.Uint64("id", ctx.Id)
+	},
+	"dest_addr": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.String("dest_addr", addrString(ctx.LocalAddr))
+	},
+	"src_addr": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.String("src_addr", addrString(ctx.RemoteAddr))
+	},
+	"dest_port": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.Uint("dest_port", GetPort(ctx.LocalAddr))
+	},
+	"src_port": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.Uint("src_port", GetPort(ctx.RemoteAddr))
+	},
+	"dest_host": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.String("dest_host", GetHost(ctx.LocalAddr))
+	},
+	"src_host": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.String("src_host", GetHost(ctx.RemoteAddr))
+	},
+	"client_version": func(_ *ConnCommandLogger, ctx *ConnContext) zap.Field {
+		return zap.String("client_version", ctx.ClientVersion)
+	},
+	"type": func(l *ConnCommandLogger, _ *ConnContext) zap.Field {
+		return zap.String("type", l.protocol)
+	},
+}
+
+func NewConnContext(id uint64, conn net.Conn) *ConnContext {
+	return &ConnContext{
+		Id:         id,
+		LocalAddr:  conn.LocalAddr(),
+		RemoteAddr: conn.RemoteAddr(),
+	}
+}
+
+func NewConnCommandLogger(opts *ConnCommandLoggerOptions) (*ConnCommandLogger, error) {
+	loggerCfg := zap.NewProductionConfig()
+
+	if opts.Path != "" {
+		loggerCfg.OutputPaths = []string{opts.Path}
+	} else if opts.FallbackPath != "" {
+		loggerCfg.OutputPaths = []string{opts.FallbackPath}
+	} else {
+		loggerCfg.OutputPaths = []string{"stdout"}
+	}
+
+	logger, err := loggerCfg.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	commandsToLog := make(map[string]bool, len(opts.CommandsToLog))
+	for _, command := range opts.CommandsToLog {
+		commandsToLog[command] = true
+	}
+
+	verboseCommands := make(map[string]bool, len(opts.VerboseCommands))
+	for _, command := range opts.VerboseCommands {
+		verboseCommands[command] = true
+	}
+
+	return &ConnCommandLogger{
+		logger:                logger,
+		protocol:              opts.Protocol,
+		commandsToLog:         commandsToLog,
+		verboseCommands:       verboseCommands,
+		additionalFieldsToLog: opts.AdditionalFields,
+	}, nil
+}
+
+func (l *ConnCommandLogger) ShouldLog(command string) bool {
+	// Log commands that are explicitly listed
+	if _, ok := l.commandsToLog[command]; ok {
+		return true
+	}
+
+	// Log all commands if the "all_detailed" command is listed
+	if _, ok := l.commandsToLog["all_detailed"]; ok {
+		return true
+	}
+
+	// Log commands that are not overly verbose if the "all" command group is listed
+	_, logAll := l.commandsToLog["all"]
+	_, commandIsVerbose := l.verboseCommands[command]
+	return logAll && !commandIsVerbose
+}
+
+func (l *ConnCommandLogger) injectContext(ctx *ConnContext, fields []zap.Field) []zap.Field {
+	for _, accessor := range l.additionalFieldsToLog {
+		if fieldAccessor, ok := connContextFieldAccessors[accessor]; ok {
+			fields = append(fields, fieldAccessor(l, ctx))
+		}
+	}
+
+	return fields
+}
+
+func (l *ConnCommandLogger) LogWithContext(ctx *ConnContext, command string, fields ...zap.Field) {
+	if !l.ShouldLog(command) {
+		return
+	}
+
+	fields = l.injectContext(ctx, fields)
+	l.logger.Info(command, fields...)
+}
+
+func (l *ConnCommandLogger) WithContext(context *ConnContext) CommandLogger {
+	return &contextBoundConnCommandLogger{
+		logger:  l,
+		context: context,
+	}
+}
+
+func (l *contextBoundConnCommandLogger) Log(command string, fields ...zap.Field) {
+	l.logger.LogWithContext(l.context, command, fields...)
+}
+
+func GetPort(addr net.Addr) uint {
+	switch v := addr.(type) {
+	case *net.UDPAddr:
+		return uint(v.Port)
+	case *net.TCPAddr:
+		return uint(v.Port)
+	}
+
+	return 0
+}
+
+func GetHost(addr net.Addr) string {
+	switch v := addr.(type) {
+	case *net.UDPAddr:
+		return v.IP.String()
+	case *net.TCPAddr:
+		return v.IP.String()
+	}
+
+	return ""
+}
+
+func addrString(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+
+	return addr.String()
+}
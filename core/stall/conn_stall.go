@@ -0,0 +1,245 @@
+package stall
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/metrics"
+)
+
+const (
+	// Rate at which connection stallers will report on wasted time to the given telemetry instance
+	ConnStallerReportInterval = time.Second * 30
+)
+
+var (
+	// Returned by writes once the timeout given to the staller has been reached
+	ErrStallDeadlineReached = errors.New("stall deadline reached")
+
+	// Returned by writes once the staller has been closed (either by the pool or by the protocol handler)
+	ErrStallClosed = errors.New("staller closed")
+)
+
+type (
+	// Represents a single stream based connection (SSH, SMTP etc) actively being stalled.
+	// Data written through the staller is trickled to the client at the configured transfer rate
+	// until either the client gives up or the timeout given by the timeout watcher is reached.
+	ConnStaller struct {
+		id         uint64
+		groupId    string
+		conn       io.WriteCloser
+		pacer      Pacer
+		timeout    time.Duration
+		startTime  time.Time
+		endTime    time.Time
+		lastReport time.Time
+		onTimeout  func(*ConnStaller)
+		onClose    func(*ConnStaller)
+
+		closed    atomic.Bool
+		closeChan chan struct{}
+		haltOnce  sync.Once
+		scheduler *Scheduler
+		waker     *Waker
+
+		deregisterChan chan Staller
+		telemetry      *metrics.Telemetry
+	}
+
+	ConnStallerOptions struct {
+		// Unique identifier for the connection
+		Id uint64
+
+		// The group the staller belongs to (Normally "<protocol>-<ip>")
+		GroupId string
+
+		// The underlying connection (or channel) data will be trickled to
+		Conn io.WriteCloser
+
+		// The time between each byte being sent
+		TransferRate time.Duration
+
+		// The scheduler waking the staller when it is next due to send data
+		Scheduler *Scheduler
+
+		// How long to stall the connection for before closing it
+		Timeout time.Duration
+
+		// Called when the client gives up before the staller does
+		OnTimeout func(*ConnStaller)
+
+		// Called when the staller closes the connection after reaching its timeout
+		OnClose func(*ConnStaller)
+
+		Telemetry *metrics.Telemetry
+	}
+)
+
+func NewConnStaller(opts *ConnStallerOptions) *ConnStaller {
+	if opts.TransferRate == 0 {
+		opts.TransferRate = time.Millisecond * 75
+	}
+
+	if opts.Timeout == 0 {
+		opts.Timeout = time.Second * 10
+	}
+
+	if opts.OnClose == nil {
+		opts.OnClose = func(_ *ConnStaller) {}
+	}
+
+	if opts.OnTimeout == nil {
+		opts.OnTimeout = func(_ *ConnStaller) {}
+	}
+
+	now := time.Now()
+	return &ConnStaller{
+		id:         opts.Id,
+		groupId:    opts.GroupId,
+		conn:       opts.Conn,
+		pacer:      NewConstantPacer(Pace{Interval: opts.TransferRate, ChunkSize: 1}),
+		timeout:    opts.Timeout,
+		startTime:  now,
+		lastReport: now,
+		onTimeout:  opts.OnTimeout,
+		onClose:    opts.OnClose,
+		telemetry:  opts.Telemetry,
+		scheduler:  opts.Scheduler,
+		waker:      opts.Scheduler.NewWaker(),
+		closeChan:  make(chan struct{}),
+	}
+}
+
+// Writes data to the client one byte at a time at the configured transfer rate.
+// Returns ErrStallDeadlineReached once the staller has run out of time, in which case the
+// caller should wrap up the conversation and call Halt.
+func (s *ConnStaller) Write(data []byte) (int, error) {
+	for i := 0; i < len(data); {
+		delay, size := s.scheduler.NextSend(s.pacer, len(data)-i)
+		if !s.waker.Wait(delay, s.closeChan) {
+			return i, ErrStallClosed
+		}
+
+		if s.Expired() {
+			return i, ErrStallDeadlineReached
+		}
+
+		end := min(i+size, len(data))
+		if _, err := s.conn.Write(data[i:end]); err != nil {
+			return i, err
+		}
+
+		i = end
+		s.reportWastedTime()
+	}
+
+	return len(data), nil
+}
+
+// Writes data to the client straight away bypassing any throttling
+func (s *ConnStaller) WriteNow(data []byte) (int, error) {
+	if s.closed.Load() {
+		return 0, ErrStallClosed
+	}
+
+	return s.conn.Write(data)
+}
+
+// Sleeps for the given duration unless the staller is closed or reaches its deadline first
+func (s *ConnStaller) Wait(duration time.Duration) error {
+	deadlineReached := false
+	if remaining := s.GetRemainingTime(); duration > remaining {
+		duration = remaining
+		deadlineReached = true
+	}
+
+	if !s.waker.Wait(duration, s.closeChan) {
+		return ErrStallClosed
+	}
+
+	if deadlineReached {
+		return ErrStallDeadlineReached
+	}
+
+	return nil
+}
+
+// If the staller has passed the timeout it was given
+func (s *ConnStaller) Expired() bool {
+	return time.Since(s.startTime) >= s.timeout
+}
+
+// Stops the staller, deregisters it from the pool and closes the underlying connection.
+// The error given should be the error that caused the conversation with the client to end (if any)
+// and is used to decide if the client or the staller gave up first.
+func (s *ConnStaller) Halt(cause error) {
+	s.haltOnce.Do(func() {
+		s.endTime = time.Now()
+		if s.telemetry != nil {
+			s.telemetry.TrackWastedTime(s.endTime.Sub(s.lastReport))
+		}
+
+		if errors.Is(cause, ErrStallDeadlineReached) {
+			go s.onClose(s)
+		} else {
+			go s.onTimeout(s)
+		}
+
+		if s.deregisterChan != nil {
+			s.deregisterChan <- s
+		}
+
+		s.Close()
+	})
+}
+
+func (s *ConnStaller) reportWastedTime() {
+	if s.telemetry == nil || time.Since(s.lastReport) < ConnStallerReportInterval {
+		return
+	}
+
+	s.telemetry.TrackWastedTime(ConnStallerReportInterval)
+	s.lastReport = s.lastReport.Add(ConnStallerReportInterval)
+}
+
+func (s *ConnStaller) GetElapsedTime() time.Duration {
+	if s.endTime.IsZero() {
+		return time.Since(s.startTime)
+	}
+
+	return s.endTime.Sub(s.startTime)
+}
+
+func (s *ConnStaller) GetRemainingTime() time.Duration {
+	return time.Duration(math.Max(float64(s.timeout-time.Since(s.startTime)), 0))
+}
+
+// Staller interface impl
+
+func (s *ConnStaller) BindToPool(deregisterChan chan Staller) {
+	s.deregisterChan = deregisterChan
+}
+
+// Shuts down the staller instance and cleans up any resources
+func (s *ConnStaller) Close() {
+	if s.closed.Swap(true) {
+		return
+	}
+
+	close(s.closeChan)
+	s.conn.Close()
+}
+
+// Gets the group identifier for the staller
+func (s *ConnStaller) GetGroupIdentifier() string {
+	return s.groupId
+}
+
+// Gets the identifier for the staller
+func (s *ConnStaller) GetIdentifier() uint64 {
+	return s.id
+}
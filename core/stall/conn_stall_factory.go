@@ -0,0 +1,122 @@
+package stall
+
+import (
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/metrics"
+)
+
+type (
+	// Creates stallers for stream based protocols (SSH, SMTP etc) and binds them to
+	// the shared staller pool and timeout watcher
+	ConnStallerFactory struct {
+		pool           *StallerPool
+		timeoutWatcher *metrics.TimeoutWatcher
+		telemetry      *metrics.Telemetry
+		scheduler      *Scheduler
+
+		nextId *atomic.Uint64
+
+		// Config
+		bytesPerSecond int
+		longestTimeout time.Duration
+	}
+
+	// Hooks called once a connection staller finishes
+	ConnStallerHooks struct {
+		// Called when the client gives up before the staller does
+		OnTimeout func(*ConnStaller)
+
+		// Called when the staller closes the connection after reaching its timeout
+		OnClose func(*ConnStaller)
+	}
+)
+
+func NewConnStallerFactory(
+	config *config.Config,
+	pool *StallerPool,
+	timeoutWatcher *metrics.TimeoutWatcher,
+	telemetry *metrics.Telemetry,
+	scheduler *Scheduler,
+) *ConnStallerFactory {
+	return &ConnStallerFactory{
+		pool:           pool,
+		timeoutWatcher: timeoutWatcher,
+		telemetry:      telemetry,
+		scheduler:      scheduler,
+		nextId:         &atomic.Uint64{},
+
+		bytesPerSecond: config.Staller.BytesPerSecond,
+		longestTimeout: time.Duration(config.TimeoutWatcher.LongestTimeout) * time.Millisecond,
+	}
+}
+
+// Creates a staller for the given connection. Data will be written to "writer" which is normally
+// the connection itself but can be a channel multiplexed over the connection (ssh channels for instance)
+func (f *ConnStallerFactory) FromConn(protocol string, conn net.Conn, writer io.WriteCloser, hooks *ConnStallerHooks) (*ConnStaller, error) {
+	identifier := GetConnIdentifier(protocol, conn)
+
+	if hooks == nil {
+		hooks = &ConnStallerHooks{}
+	}
+
+	staller := NewConnStaller(&ConnStallerOptions{
+		Id:           f.nextId.Add(1),
+		GroupId:      identifier,
+		Conn:         writer,
+		TransferRate: time.Second / time.Duration(f.bytesPerSecond),
+		Scheduler:    f.scheduler,
+		Timeout:      f.getTimeout(identifier),
+		OnTimeout: func(stl *ConnStaller) {
+			f.recordResponse(identifier, stl.GetElapsedTime(), false)
+			if hooks.OnTimeout != nil {
+				hooks.OnTimeout(stl)
+			}
+		},
+		OnClose: func(stl *ConnStaller) {
+			f.recordResponse(identifier, stl.GetElapsedTime(), true)
+			if hooks.OnClose != nil {
+				hooks.OnClose(stl)
+			}
+		},
+		Telemetry: f.telemetry,
+	})
+
+	if err := f.pool.Register(staller); err != nil {
+		staller.Close()
+		return nil, err
+	}
+
+	return staller, nil
+}
+
+func (f *ConnStallerFactory) getTimeout(identifier string) time.Duration {
+	// With the timeout watcher disabled every connection is stalled for as long as possible
+	if f.timeoutWatcher == nil {
+		return f.longestTimeout
+	}
+
+	return f.timeoutWatcher.GetTimeout(identifier)
+}
+
+func (f *ConnStallerFactory) recordResponse(identifier string, elapsed time.Duration, successful bool) {
+	if f.timeoutWatcher == nil {
+		return
+	}
+
+	f.timeoutWatcher.RecordResponse(identifier, elapsed, successful)
+}
+
+// Gets the identifier used by the timeout watcher and staller pool for a given connection (i.e "ssh-127.0.0.1")
+func GetConnIdentifier(protocol string, conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		host = conn.RemoteAddr().String()
+	}
+
+	return protocol + "-" + host
+}
@@ -0,0 +1,131 @@
+package stall
+
+import (
+	"math"
+	"time"
+
+	"github.com/ryanolee/go-pot/rand"
+)
+
+type (
+	// Decides how data is trickled to a client. Each call to Next gives how long to wait
+	// before the next send and how many bytes to send once done waiting
+	Pacer interface {
+		Next() (time.Duration, int)
+	}
+
+	// The pace data is sent at by the constant pacer. Other pacers vary their pace from this
+	Pace struct {
+		// The time between each send
+		Interval time.Duration
+
+		// The number of bytes in each send
+		ChunkSize int
+	}
+
+	ConstantPacer struct {
+		pace Pace
+	}
+
+	JitterPacer struct {
+		pace   Pace
+		spread float64
+		rand   *rand.SeededRand
+	}
+
+	BurstyPacer struct {
+		pace         Pace
+		initialBytes int
+		burstSize    int
+		interval     time.Duration
+		sent         int
+	}
+
+	DecayPacer struct {
+		pace     Pace
+		factor   float64
+		maxDelay time.Duration
+		delay    float64
+	}
+
+	KeepalivePacer struct {
+		pace     Pace
+		interval time.Duration
+	}
+)
+
+func NewConstantPacer(pace Pace) *ConstantPacer {
+	return &ConstantPacer{pace: pace}
+}
+
+func (p *ConstantPacer) Next() (time.Duration, int) {
+	return p.pace.Interval, p.pace.ChunkSize
+}
+
+// Creates a pacer sending at the constant pace with the time between each send varying by up to
+// the given spread (i.e 0.5 for +/- 50%) so there is no fixed cadence for clients to spot
+func NewJitterPacer(pace Pace, spread float64, random *rand.SeededRand) *JitterPacer {
+	return &JitterPacer{
+		pace:   pace,
+		spread: spread,
+		rand:   random,
+	}
+}
+
+func (p *JitterPacer) Next() (time.Duration, int) {
+	variation := p.rand.RandomFloat(-p.spread, p.spread)
+	return time.Duration(float64(p.pace.Interval) * (1 + variation)), p.pace.ChunkSize
+}
+
+// Creates a pacer sending the first few bytes at the constant pace before slowing down to
+// sending a burst of bytes every interval. Clients tolerating bursts are held for longer this way
+func NewBurstyPacer(pace Pace, initialBytes int, burstSize int, interval time.Duration) *BurstyPacer {
+	return &BurstyPacer{
+		pace:         pace,
+		initialBytes: initialBytes,
+		burstSize:    burstSize,
+		interval:     interval,
+	}
+}
+
+func (p *BurstyPacer) Next() (time.Duration, int) {
+	if p.sent < p.initialBytes {
+		p.sent += p.pace.ChunkSize
+		return p.pace.Interval, p.pace.ChunkSize
+	}
+
+	return p.interval, p.burstSize
+}
+
+// Creates a pacer starting at the constant pace with the time between each send growing by the
+// given factor after every send up until the max delay
+func NewDecayPacer(pace Pace, factor float64, maxDelay time.Duration) *DecayPacer {
+	return &DecayPacer{
+		pace:     pace,
+		factor:   factor,
+		maxDelay: maxDelay,
+		delay:    float64(pace.Interval),
+	}
+}
+
+func (p *DecayPacer) Next() (time.Duration, int) {
+	delay := time.Duration(math.Min(p.delay, float64(p.maxDelay)))
+	if delay < p.maxDelay {
+		p.delay *= p.factor
+	}
+
+	return delay, p.pace.ChunkSize
+}
+
+// Creates a pacer sending a single chunk every interval. With an interval just under the read
+// timeout of a client the client is kept waiting for as long as possible for every byte
+func NewKeepalivePacer(pace Pace, interval time.Duration) *KeepalivePacer {
+	return &KeepalivePacer{
+		pace:     pace,
+		interval: interval,
+	}
+}
+
+func (p *KeepalivePacer) Next() (time.Duration, int) {
+	return p.interval, p.pace.ChunkSize
+}
@@ -0,0 +1,118 @@
+package stall
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/rand"
+)
+
+type (
+	// Creates pacers for stallers picking the pacer to use based on the client being stalled
+	PacerFactory struct {
+		pacer    string
+		profiles []pacerProfile
+		config   *config.Config
+	}
+
+	// Details about a client used to pick the pacer to stall it with
+	PacerClient struct {
+		// The protocol the client connected over (http or ftp)
+		Protocol string
+
+		// The requested path (or file name)
+		Path string
+
+		// The user agent sent by the client (if any)
+		UserAgent string
+	}
+
+	pacerProfile struct {
+		protocols []string
+		path      *regexp.Regexp
+		userAgent *regexp.Regexp
+		pacer     string
+	}
+)
+
+func NewPacerFactory(conf *config.Config) (*PacerFactory, error) {
+	pacingConfig := conf.Staller.Pacing
+	factory := &PacerFactory{
+		pacer:  pacingConfig.Pacer,
+		config: conf,
+	}
+
+	for _, profileConfig := range pacingConfig.Profiles {
+		profile := pacerProfile{
+			protocols: profileConfig.Protocols,
+			pacer:     profileConfig.Pacer,
+		}
+
+		if profileConfig.Path != "" {
+			pattern, err := regexp.Compile(profileConfig.Path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pacing profile path pattern %s: %w", profileConfig.Path, err)
+			}
+			profile.path = pattern
+		}
+
+		if profileConfig.UserAgent != "" {
+			pattern, err := regexp.Compile(profileConfig.UserAgent)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pacing profile user agent pattern %s: %w", profileConfig.UserAgent, err)
+			}
+			profile.userAgent = pattern
+		}
+
+		factory.profiles = append(factory.profiles, profile)
+	}
+
+	return factory, nil
+}
+
+// Creates a pacer for the given client. The pace given is the one the constant pacer
+// sends at and is the pace other pacers start from
+func (f *PacerFactory) ForClient(client PacerClient, pace Pace) Pacer {
+	pacer := f.pacer
+	for _, profile := range f.profiles {
+		if profile.matches(client) {
+			pacer = profile.pacer
+			break
+		}
+	}
+
+	pacingConfig := f.config.Staller.Pacing
+	switch pacer {
+	case "jitter":
+		return NewJitterPacer(pace, pacingConfig.Jitter.Spread, rand.NewSeededRandFromTime())
+	case "bursty":
+		bursty := pacingConfig.Bursty
+		return NewBurstyPacer(pace, bursty.InitialBytes, bursty.BurstSize, time.Duration(bursty.IntervalMs)*time.Millisecond)
+	case "decay":
+		decay := pacingConfig.Decay
+		return NewDecayPacer(pace, decay.Factor, time.Duration(decay.MaxDelayMs)*time.Millisecond)
+	case "keepalive":
+		return NewKeepalivePacer(pace, time.Duration(pacingConfig.Keepalive.IntervalMs)*time.Millisecond)
+	}
+
+	return NewConstantPacer(pace)
+}
+
+func (p *pacerProfile) matches(client PacerClient) bool {
+	if len(p.protocols) > 0 && !slices.Contains(p.protocols, client.Protocol) {
+		return false
+	}
+
+	if p.path != nil && !p.path.MatchString(client.Path) {
+		return false
+	}
+
+	if p.userAgent != nil && !p.userAgent.MatchString(client.UserAgent) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,296 @@
+package stall
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"go.uber.org/fx"
+)
+
+type (
+	// A hashed timer wheel shared by every staller. Rather than each staller holding timers of its own it
+	// schedules a task for when it next wants to send data. The wheel is turned once every tick and due tasks
+	// are run by a small pool of workers
+	Scheduler struct {
+		tick     time.Duration
+		slots    []*Task
+		position int
+		lock     sync.Mutex
+
+		workers int
+		work    chan *Task
+		pending atomic.Int64
+
+		stopChan chan struct{}
+		stopOnce sync.Once
+		running  sync.WaitGroup
+	}
+
+	SchedulerOptions struct {
+		// How often the wheel is turned. Tasks are never run early but can be late by up to a tick
+		Tick time.Duration
+
+		// The number of slots in the wheel. Tasks further away than a full turn are checked once every turn
+		Slots int
+
+		// The number of workers running due tasks
+		Workers int
+	}
+
+	// A task run by the scheduler once due. Tasks can be scheduled again once run so
+	// stallers only ever need the one task and scheduling allocates nothing
+	Task struct {
+		run func()
+
+		// Position in the wheel
+		slot      int
+		rounds    int
+		next      *Task
+		prev      *Task
+		scheduled bool
+	}
+
+	// Blocks a goroutine until woken by the scheduler. Used by stallers driven by a
+	// goroutine of their own (i.e a connection handler) in place of a timer
+	Waker struct {
+		scheduler *Scheduler
+		task      *Task
+		wake      chan struct{}
+	}
+)
+
+func NewScheduler(lifecycle fx.Lifecycle, conf *config.Config) *Scheduler {
+	schedulerConfig := conf.Staller.Scheduler
+	scheduler := NewSchedulerWithOptions(&SchedulerOptions{
+		Tick:    time.Duration(schedulerConfig.TickMs) * time.Millisecond,
+		Slots:   schedulerConfig.Slots,
+		Workers: schedulerConfig.Workers,
+	})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			scheduler.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			scheduler.Stop()
+			return nil
+		},
+	})
+
+	return scheduler
+}
+
+func NewSchedulerWithOptions(opts *SchedulerOptions) *Scheduler {
+	if opts.Tick == 0 {
+		opts.Tick = time.Millisecond * 10
+	}
+
+	if opts.Slots == 0 {
+		opts.Slots = 4096
+	}
+
+	if opts.Workers == 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	return &Scheduler{
+		tick:     opts.Tick,
+		slots:    make([]*Task, opts.Slots),
+		workers:  opts.Workers,
+		work:     make(chan *Task, opts.Workers*1024),
+		stopChan: make(chan struct{}),
+	}
+}
+
+func NewTask(run func()) *Task {
+	return &Task{run: run}
+}
+
+// Starts turning the wheel and the workers running due tasks
+func (s *Scheduler) Start() {
+	for i := 0; i < s.workers; i++ {
+		s.running.Add(1)
+		go func() {
+			defer s.running.Done()
+			for {
+				select {
+				case task := <-s.work:
+					task.run()
+				case <-s.stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.turn()
+			case <-s.stopChan:
+				return
+			}
+		}
+	}()
+}
+
+// Stops the scheduler. Tasks still scheduled are never run
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+	s.running.Wait()
+}
+
+// Schedules the task to run after the given delay. Tasks already scheduled are moved
+func (s *Scheduler) Schedule(task *Task, delay time.Duration) {
+	ticks := int((delay + s.tick - 1) / s.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if task.scheduled {
+		s.remove(task)
+	}
+
+	task.slot = (s.position + ticks) % len(s.slots)
+	task.rounds = (ticks - 1) / len(s.slots)
+	task.scheduled = true
+	task.prev = nil
+	task.next = s.slots[task.slot]
+	if task.next != nil {
+		task.next.prev = task
+	}
+	s.slots[task.slot] = task
+	s.pending.Add(1)
+}
+
+// Cancels the task if it is scheduled. Tasks already handed to a worker still run
+func (s *Scheduler) Cancel(task *Task) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if task.scheduled {
+		s.remove(task)
+	}
+}
+
+// The number of tasks waiting to run
+func (s *Scheduler) Pending() int {
+	return int(s.pending.Load())
+}
+
+// The time between each turn of the wheel
+func (s *Scheduler) Tick() time.Duration {
+	return s.tick
+}
+
+// Gets the next send from the pacer. Sends closer together than a tick are combined (up to the given
+// number of bytes) so stallers sending faster than the wheel turns are not slowed down to the tick rate
+func (s *Scheduler) NextSend(pacer Pacer, limit int) (time.Duration, int) {
+	delay, size := pacer.Next()
+	for delay < s.tick && size < limit {
+		nextDelay, nextSize := pacer.Next()
+		delay += nextDelay
+		size += nextSize
+	}
+
+	return delay, size
+}
+
+// Creates a waker for a single goroutine to wait on
+func (s *Scheduler) NewWaker() *Waker {
+	waker := &Waker{
+		scheduler: s,
+		wake:      make(chan struct{}, 1),
+	}
+
+	waker.task = NewTask(func() {
+		select {
+		case waker.wake <- struct{}{}:
+		default:
+		}
+	})
+
+	return waker
+}
+
+// Moves the wheel onto the next slot handing any due tasks to the workers
+func (s *Scheduler) turn() {
+	s.lock.Lock()
+	s.position = (s.position + 1) % len(s.slots)
+
+	var due []*Task
+	for task := s.slots[s.position]; task != nil; {
+		next := task.next
+		if task.rounds > 0 {
+			task.rounds--
+		} else {
+			s.remove(task)
+			due = append(due, task)
+		}
+		task = next
+	}
+	s.lock.Unlock()
+
+	for _, task := range due {
+		select {
+		case s.work <- task:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// Unlinks the task from its slot. Must be called with the lock held
+func (s *Scheduler) remove(task *Task) {
+	if task.prev != nil {
+		task.prev.next = task.next
+	} else {
+		s.slots[task.slot] = task.next
+	}
+
+	if task.next != nil {
+		task.next.prev = task.prev
+	}
+
+	task.next = nil
+	task.prev = nil
+	task.scheduled = false
+	s.pending.Add(-1)
+}
+
+// Blocks for the given delay. Returns false if done is closed before the delay is up
+func (w *Waker) Wait(delay time.Duration, done <-chan struct{}) bool {
+	if delay <= 0 {
+		select {
+		case <-done:
+			return false
+		default:
+			return true
+		}
+	}
+
+	w.scheduler.Schedule(w.task, delay)
+	select {
+	case <-w.wake:
+		return true
+	case <-done:
+		w.scheduler.Cancel(w.task)
+		return false
+	}
+}
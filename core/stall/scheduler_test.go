@@ -0,0 +1,110 @@
+package stall
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextSend(t *testing.T) {
+	tests := []struct {
+		name      string
+		pace      Pace
+		limit     int
+		wantDelay time.Duration
+		wantSize  int
+	}{
+		{name: "slower than a tick", pace: Pace{Interval: time.Millisecond * 125, ChunkSize: 1}, limit: 100, wantDelay: time.Millisecond * 125, wantSize: 1},
+		{name: "as fast as a tick", pace: Pace{Interval: time.Millisecond * 10, ChunkSize: 1}, limit: 100, wantDelay: time.Millisecond * 10, wantSize: 1},
+		{name: "faster than a tick", pace: Pace{Interval: time.Millisecond, ChunkSize: 1}, limit: 100, wantDelay: time.Millisecond * 10, wantSize: 10},
+		{name: "faster than a tick with larger chunks", pace: Pace{Interval: time.Millisecond * 4, ChunkSize: 3}, limit: 100, wantDelay: time.Millisecond * 12, wantSize: 9},
+		{name: "combined up to the limit", pace: Pace{Interval: time.Millisecond, ChunkSize: 1}, limit: 3, wantDelay: time.Millisecond * 3, wantSize: 3},
+		{name: "nothing left to send", pace: Pace{Interval: time.Millisecond, ChunkSize: 1}, limit: 0, wantDelay: time.Millisecond, wantSize: 1},
+	}
+
+	scheduler := NewSchedulerWithOptions(&SchedulerOptions{Tick: time.Millisecond * 10})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delay, size := scheduler.NextSend(NewConstantPacer(test.pace), test.limit)
+			if delay != test.wantDelay || size != test.wantSize {
+				t.Errorf("expected %d bytes after %s got %d bytes after %s", test.wantSize, test.wantDelay, size, delay)
+			}
+		})
+	}
+}
+
+// The rate stays the same however sends are combined
+func TestNextSendKeepsPace(t *testing.T) {
+	scheduler := NewSchedulerWithOptions(&SchedulerOptions{Tick: time.Millisecond * 10})
+	for _, limit := range []int{1, 7, 22, 1000} {
+		pacer := NewConstantPacer(Pace{Interval: time.Millisecond * 3, ChunkSize: 2})
+
+		var elapsed time.Duration
+		var sent int
+		for sent < 10000 {
+			delay, size := scheduler.NextSend(pacer, limit)
+			elapsed += delay
+			sent += size
+		}
+
+		if rate := float64(sent) / elapsed.Seconds(); rate < 666 || rate > 667 {
+			t.Errorf("expected %.2f bytes per second with a limit of %d got %.2f", 2/0.003, limit, rate)
+		}
+	}
+}
+
+func TestScheduleRunsTasksInOrder(t *testing.T) {
+	scheduler := NewSchedulerWithOptions(&SchedulerOptions{Tick: time.Millisecond, Slots: 8, Workers: 1})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	ran := make(chan int, 3)
+	cancelled := NewTask(func() { ran <- -1 })
+	for i, delay := range []time.Duration{time.Millisecond * 30, time.Millisecond * 2, time.Millisecond * 15} {
+		scheduler.Schedule(NewTask(func() { ran <- i }), delay)
+	}
+
+	// Delays longer than the wheel go round it more than once and cancelled tasks never run
+	scheduler.Schedule(cancelled, time.Millisecond*20)
+	scheduler.Cancel(cancelled)
+
+	for _, want := range []int{1, 2, 0} {
+		select {
+		case got := <-ran:
+			if got != want {
+				t.Fatalf("expected task %d to run got %d", want, got)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatalf("task %d never ran", want)
+		}
+	}
+
+	if pending := scheduler.Pending(); pending != 0 {
+		t.Errorf("expected no tasks to be pending got %d", pending)
+	}
+}
+
+func TestWakerWait(t *testing.T) {
+	scheduler := NewSchedulerWithOptions(&SchedulerOptions{Tick: time.Millisecond})
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	waker := scheduler.NewWaker()
+	start := time.Now()
+	if !waker.Wait(time.Millisecond*20, make(chan struct{})) {
+		t.Fatal("expected the wait to finish")
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Millisecond*20 {
+		t.Errorf("expected to wait at least 20ms got %s", elapsed)
+	}
+
+	done := make(chan struct{})
+	close(done)
+	if waker.Wait(time.Hour, done) {
+		t.Error("expected the wait to be cut short")
+	}
+
+	if pending := scheduler.Pending(); pending != 0 {
+		t.Errorf("expected the cut short wait to be cancelled got %d pending", pending)
+	}
+}
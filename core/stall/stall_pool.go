@@ -72,6 +72,11 @@ func (s *StallerPool) Register(staller Staller) error {
 
 }
 
+// The number of stallers currently registered
+func (s *StallerPool) Len() int {
+	return s.stallers.Len()
+}
+
 func (s *StallerPool) Start() {
 	// Deregistration watcher
 	go func() {
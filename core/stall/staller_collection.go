@@ -59,6 +59,9 @@ func (c *StallerCollection) PruneNByIdentifier(count int) {
 }
 
 func (c *StallerCollection) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	count := 0
 	for _, identifierMap := range c.stallers {
 		count += len(identifierMap)
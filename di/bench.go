@@ -0,0 +1,55 @@
+package di
+
+import (
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/metrics"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/generator/git"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/protocol/http"
+	httpLogger "github.com/ryanolee/go-pot/protocol/http/logging"
+	httpStall "github.com/ryanolee/go-pot/protocol/http/stall"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/fx"
+)
+
+// Creates a container holding only the HTTP server and the services it needs. The server is not started
+// so it can be served on a listener of the callers choosing. Used to benchmark how many connections can be stalled at once
+func CreateBenchContainer(conf *config.Config, invoke interface{}) *fx.App {
+	return fx.New(
+		fx.Supply(conf),
+		fx.Provide(
+			// Logging
+			logging.NewLogger,
+			httpLogger.NewHttpAccessLogger,
+			fx.Annotate(
+				httpLogger.NewServerLogger,
+				fx.As(new(httpLogger.IServerLogger)),
+			),
+
+			// Metrics
+			metrics.NewTimeoutWatcher,
+			metrics.NewTelemetry,
+
+			// Generators
+			generator.NewConfigGeneratorCollection,
+			git.NewRepository,
+			secrets.NewSecretGeneratorCollection,
+			source.NewTabularSchemaCollection,
+			generator.NewStreamSourceFactory,
+
+			// Stallers
+			stall.NewStallerPool,
+			stall.NewPacerFactory,
+			stall.NewScheduler,
+			httpStall.NewHttpStallerFactory,
+
+			// Http Server
+			http.NewServer,
+		),
+		fx.Invoke(invoke),
+		fx.NopLogger,
+	)
+}
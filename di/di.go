@@ -16,6 +16,8 @@ import (
 	"github.com/ryanolee/go-pot/core/metrics"
 	"github.com/ryanolee/go-pot/core/stall"
 	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/generator/git"
+	"github.com/ryanolee/go-pot/generator/source"
 	"github.com/ryanolee/go-pot/protocol/ftp"
 	ftpDi "github.com/ryanolee/go-pot/protocol/ftp/di"
 	"github.com/ryanolee/go-pot/protocol/ftp/driver"
@@ -25,6 +27,18 @@ import (
 	"github.com/ryanolee/go-pot/protocol/http"
 	httpLogger "github.com/ryanolee/go-pot/protocol/http/logging"
 	httpStall "github.com/ryanolee/go-pot/protocol/http/stall"
+	"github.com/ryanolee/go-pot/protocol/mysql"
+	mysqlLogging "github.com/ryanolee/go-pot/protocol/mysql/logging"
+	"github.com/ryanolee/go-pot/protocol/postgres"
+	postgresLogging "github.com/ryanolee/go-pot/protocol/postgres/logging"
+	"github.com/ryanolee/go-pot/protocol/redis"
+	redisLogging "github.com/ryanolee/go-pot/protocol/redis/logging"
+	"github.com/ryanolee/go-pot/protocol/smtp"
+	smtpLogging "github.com/ryanolee/go-pot/protocol/smtp/logging"
+	"github.com/ryanolee/go-pot/protocol/ssh"
+	sshLogging "github.com/ryanolee/go-pot/protocol/ssh/logging"
+	"github.com/ryanolee/go-pot/protocol/telnet"
+	telnetLogging "github.com/ryanolee/go-pot/protocol/telnet/logging"
 	"github.com/ryanolee/go-pot/secrets"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -35,8 +49,8 @@ import (
 // Creates the dependency injection container for the application
 func CreateContainer(conf *config.Config) *fx.App {
 
-	if !conf.FtpServer.Enabled && conf.Server.Disable {
-		fmt.Print("Both FTP and HTTP servers are disabled. There is nothing to do. Exiting.")
+	if !conf.FtpServer.Enabled && !conf.SshServer.Enabled && !conf.SmtpServer.Enabled && !conf.TelnetServer.Enabled && !conf.RedisServer.Enabled && !conf.MysqlServer.Enabled && !conf.PostgresServer.Enabled && conf.Server.Disable {
+		fmt.Print("The FTP, SSH, SMTP, Telnet, Redis, MySQL, PostgreSQL and HTTP servers are all disabled. There is nothing to do. Exiting.")
 		os.Exit(0)
 	}
 
@@ -47,6 +61,12 @@ func CreateContainer(conf *config.Config) *fx.App {
 			logging.NewLogger,
 			httpLogger.NewHttpAccessLogger,
 			ftpLogging.NewFtpCommandLogger,
+			sshLogging.NewSshCommandLogger,
+			smtpLogging.NewSmtpCommandLogger,
+			telnetLogging.NewTelnetAccessLogger,
+			redisLogging.NewRedisCommandLogger,
+			mysqlLogging.NewMysqlCommandLogger,
+			postgresLogging.NewPostgresCommandLogger,
 
 			// Metrics
 			metrics.NewTimeoutWatcher,
@@ -55,12 +75,18 @@ func CreateContainer(conf *config.Config) *fx.App {
 
 			// Generators
 			generator.NewConfigGeneratorCollection,
+			git.NewRepository,
 			secrets.NewSecretGeneratorCollection,
+			source.NewTabularSchemaCollection,
+			generator.NewStreamSourceFactory,
 
 			// Stallers
 			stall.NewStallerPool,
 			httpStall.NewHttpStallerFactory,
 			ftpStall.NewFtpFileStallerFactory,
+			stall.NewConnStallerFactory,
+			stall.NewPacerFactory,
+			stall.NewScheduler,
 
 			// Cluster Memberlist
 			fx.Annotate(handler.NewBroadcastActionHandler,
@@ -88,6 +114,24 @@ func CreateContainer(conf *config.Config) *fx.App {
 			driver.NewFtpServerDriver,
 			driver.NewFtpClientDriverFactory,
 
+			// Ssh Server
+			ssh.NewServer,
+
+			// Smtp Server
+			smtp.NewServer,
+
+			// Telnet Server
+			telnet.NewServer,
+
+			// Redis Server
+			redis.NewServer,
+
+			// Mysql Server
+			mysql.NewServer,
+
+			// Postgres Server
+			postgres.NewServer,
+
 			// Di Repositories
 			ftpDi.NewFtpRepository,
 		),
@@ -130,7 +174,7 @@ func CreateContainer(conf *config.Config) *fx.App {
 				zap.L().Info("Http is disabled")
 				return
 			}
-			zap.L().Info("Starting Http server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost))
+			zap.L().Info("Starting Http server", zap.Int("port", s.ListenPort), zap.Int("tls_port", s.TlsPort), zap.String("host", s.ListenHost))
 			go func() {
 				if err := s.Start(); err != nil {
 					zap.L().Fatal("Failed to start Http server", zap.Error(err))
@@ -152,6 +196,90 @@ func CreateContainer(conf *config.Config) *fx.App {
 			}()
 		}),
 
+		// Start Ssh server
+		fx.Invoke(func(c *config.Config, s *ssh.Server) {
+			if !conf.SshServer.Enabled {
+				zap.L().Info("Ssh is disabled")
+				return
+			}
+			zap.L().Info("Starting Ssh server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost), zap.String("mode", c.SshServer.Mode))
+			go func() {
+				if err := s.Start(); err != nil {
+					zap.L().Fatal("Failed to start Ssh server", zap.Error(err))
+				}
+			}()
+		}),
+
+		// Start Smtp server
+		fx.Invoke(func(s *smtp.Server) {
+			if !conf.SmtpServer.Enabled {
+				zap.L().Info("Smtp is disabled")
+				return
+			}
+			zap.L().Info("Starting Smtp server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost))
+			go func() {
+				if err := s.Start(); err != nil {
+					zap.L().Fatal("Failed to start Smtp server", zap.Error(err))
+				}
+			}()
+		}),
+
+		// Start Telnet server
+		fx.Invoke(func(s *telnet.Server) {
+			if !conf.TelnetServer.Enabled {
+				zap.L().Info("Telnet is disabled")
+				return
+			}
+			zap.L().Info("Starting Telnet server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost))
+			go func() {
+				if err := s.Start(); err != nil {
+					zap.L().Fatal("Failed to start Telnet server", zap.Error(err))
+				}
+			}()
+		}),
+
+		// Start Redis server
+		fx.Invoke(func(s *redis.Server) {
+			if !conf.RedisServer.Enabled {
+				zap.L().Info("Redis is disabled")
+				return
+			}
+			zap.L().Info("Starting Redis server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost))
+			go func() {
+				if err := s.Start(); err != nil {
+					zap.L().Fatal("Failed to start Redis server", zap.Error(err))
+				}
+			}()
+		}),
+
+		// Start Mysql server
+		fx.Invoke(func(s *mysql.Server) {
+			if !conf.MysqlServer.Enabled {
+				zap.L().Info("Mysql is disabled")
+				return
+			}
+			zap.L().Info("Starting Mysql server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost))
+			go func() {
+				if err := s.Start(); err != nil {
+					zap.L().Fatal("Failed to start Mysql server", zap.Error(err))
+				}
+			}()
+		}),
+
+		// Start Postgres server
+		fx.Invoke(func(s *postgres.Server) {
+			if !conf.PostgresServer.Enabled {
+				zap.L().Info("Postgres is disabled")
+				return
+			}
+			zap.L().Info("Starting Postgres server", zap.Int("port", s.ListenPort), zap.String("host", s.ListenHost))
+			go func() {
+				if err := s.Start(); err != nil {
+					zap.L().Fatal("Failed to start Postgres server", zap.Error(err))
+				}
+			}()
+		}),
+
 		fx.WithLogger(func(log *zap.Logger) fxevent.Logger {
 			if !conf.Logging.StartUpLogEnabled {
 				return &fxevent.ZapLogger{Logger: zap.NewNop()}
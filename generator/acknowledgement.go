@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type (
+	// Wraps a generator producing JSON so the stream is preceded by an acknowledgement
+	// of the request. i.e {"status":"ok","id":"...","data":[...]}
+	AcknowledgementGenerator struct {
+		generator Generator
+		status    string
+		received  int
+		stream    *StreamSource
+	}
+)
+
+func NewAcknowledgementGenerator(generator Generator, status string, received int, stream *StreamSource) *AcknowledgementGenerator {
+	return &AcknowledgementGenerator{
+		generator: generator,
+		status:    status,
+		received:  received,
+		stream:    stream,
+	}
+}
+
+func (g *AcknowledgementGenerator) Generate() []byte {
+	return g.generator.Generate()
+}
+
+func (g *AcknowledgementGenerator) Start() []byte {
+	id, err := uuid.NewRandomFromReader(g.stream.Rand.Rand)
+	if err != nil {
+		return g.generator.Start()
+	}
+
+	header, err := json.Marshal(map[string]interface{}{
+		"status":    g.status,
+		"id":        id.String(),
+		"received":  g.received,
+		"timestamp": g.stream.Now.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return g.generator.Start()
+	}
+
+	// Reopen the object so the data can be added to it
+	return append(append(header[:len(header)-1], []byte(`,"data":`)...), g.generator.Start()...)
+}
+
+func (g *AcknowledgementGenerator) GenerateChunk() []byte {
+	return g.generator.GenerateChunk()
+}
+
+func (g *AcknowledgementGenerator) ChunkSeparator() []byte {
+	return g.generator.ChunkSeparator()
+}
+
+func (g *AcknowledgementGenerator) End() []byte {
+	return append(g.generator.End(), '}')
+}
@@ -0,0 +1,300 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+)
+
+var (
+	archiveRootDirs    = []string{"backup", "site", "www", "html", "export", "dump", "prod", "public_html"}
+	archiveMemberNames = []string{
+		"db/%s.sql", "database/%s_dump.sql", "%s.sql", ".env", "%s/.env.production", "config/%s.json",
+		"secrets/%s.json", "app/config/%s.json", "backup/%s.sql", "%s/.env",
+	}
+)
+
+const (
+	minArchiveMemberChunks = 20
+	maxArchiveMemberChunks = 200
+)
+
+type (
+	// Streams a zip, tar.gz or gzip archive made up of files from the other generators. Data is flushed
+	// through the compressor after each chunk so the archive stays readable up to wherever the client disconnects
+	ArchiveGenerator struct {
+		encoder          *encoder.ArchiveEncoder
+		configGenerators *ConfigGeneratorCollection
+		tabularSchemas   *source.TabularSchemaCollection
+		secrets          *secrets.SecretGeneratorCollection
+		stream           *StreamSource
+		rand             *rand.SeededRand
+
+		out       bytes.Buffer
+		zipWriter *zip.Writer
+		tarWriter *tar.Writer
+		gzWriter  *gzip.Writer
+
+		// The archive member currently being written
+		member       Generator
+		memberWriter io.Writer
+		memberChunks int
+		root         string
+		modified     time.Time
+		closed       bool
+	}
+
+	// Flushes after every write so each chunk of a zip member can be sent straight away
+	flushingFlateWriter struct {
+		*flate.Writer
+	}
+)
+
+func NewArchiveGenerator(enc encoder.Encoder, configGenerators *ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secrets *secrets.SecretGeneratorCollection, stream *StreamSource) *ArchiveGenerator {
+	archiveEncoder, ok := enc.(*encoder.ArchiveEncoder)
+	if !ok {
+		archiveEncoder = encoder.NewZipEncoder()
+	}
+
+	random := stream.Rand
+	g := &ArchiveGenerator{
+		encoder:          archiveEncoder,
+		configGenerators: configGenerators,
+		tabularSchemas:   tabularSchemas,
+		secrets:          secrets,
+		stream:           stream,
+		rand:             random,
+		root:             fmt.Sprintf("%s-%d-%02d-%02d", random.StringChoice(&archiveRootDirs), random.RandomInt(2020, 2025), random.RandomInt(1, 13), random.RandomInt(1, 29)),
+		modified:         stream.Now.Add(-time.Duration(random.RandomInt(24, 24*180)) * time.Hour),
+	}
+
+	switch archiveEncoder.Format() {
+	case encoder.ArchiveFormatZip:
+		g.zipWriter = zip.NewWriter(&g.out)
+		g.zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			writer, err := flate.NewWriter(out, flate.DefaultCompression)
+			return &flushingFlateWriter{writer}, err
+		})
+	case encoder.ArchiveFormatTarGz:
+		g.gzWriter = gzip.NewWriter(&g.out)
+		g.tarWriter = tar.NewWriter(g.gzWriter)
+	default:
+		g.gzWriter = gzip.NewWriter(&g.out)
+		g.gzWriter.ModTime = g.modified
+		g.member = GetGeneratorForEncoder(archiveEncoder.Member(), configGenerators, tabularSchemas, secrets, stream)
+	}
+
+	return g
+}
+
+func (g *ArchiveGenerator) Generate() []byte {
+	if g.closed {
+		return nil
+	}
+
+	var err error
+	switch g.encoder.Format() {
+	case encoder.ArchiveFormatZip:
+		err = g.writeZipChunk()
+	case encoder.ArchiveFormatTarGz:
+		err = g.writeTarMember()
+	default:
+		err = g.writeGzipChunk()
+	}
+
+	if err != nil {
+		zap.L().Sugar().Error(err)
+	}
+
+	return g.flush()
+}
+
+func (g *ArchiveGenerator) Start() []byte {
+	return []byte(g.encoder.Start())
+}
+
+func (g *ArchiveGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+func (g *ArchiveGenerator) ChunkSeparator() []byte {
+	return []byte(g.encoder.Delimiter())
+}
+
+// Finishes the current member and writes whatever trailer the format needs (i.e the zip central directory)
+func (g *ArchiveGenerator) End() []byte {
+	if g.closed {
+		return nil
+	}
+	g.closed = true
+
+	var err error
+	switch g.encoder.Format() {
+	case encoder.ArchiveFormatZip:
+		if g.member != nil {
+			_, err = g.memberWriter.Write(g.member.End())
+		}
+		if err == nil {
+			err = g.zipWriter.Close()
+		}
+	case encoder.ArchiveFormatTarGz:
+		if err = g.tarWriter.Close(); err == nil {
+			err = g.gzWriter.Close()
+		}
+	default:
+		if _, err = g.gzWriter.Write(g.member.End()); err == nil {
+			err = g.gzWriter.Close()
+		}
+	}
+
+	if err != nil {
+		zap.L().Sugar().Error(err)
+	}
+
+	return g.flush()
+}
+
+// Archives are binary so must not be padded out with whitespace
+func (g *ArchiveGenerator) IsBinary() bool {
+	return true
+}
+
+// Writes the next chunk of the current zip member. Finished members are closed straight away by starting the
+// next one so their data descriptor is sent without waiting for more data
+func (g *ArchiveGenerator) writeZipChunk() error {
+	if g.member == nil {
+		if err := g.nextZipMember(); err != nil {
+			return err
+		}
+	} else if _, err := g.memberWriter.Write(g.member.ChunkSeparator()); err != nil {
+		return err
+	}
+
+	if _, err := g.memberWriter.Write(g.member.GenerateChunk()); err != nil {
+		return err
+	}
+
+	g.memberChunks--
+	if g.memberChunks > 0 {
+		return g.zipWriter.Flush()
+	}
+
+	if _, err := g.memberWriter.Write(g.member.End()); err != nil {
+		return err
+	}
+
+	return g.nextZipMember()
+}
+
+func (g *ArchiveGenerator) nextZipMember() error {
+	name, member := g.nextMember()
+	writer, err := g.zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: g.modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	g.member = member
+	g.memberWriter = writer
+	g.memberChunks = g.rand.RandomInt(minArchiveMemberChunks, maxArchiveMemberChunks)
+	if _, err := writer.Write(member.Start()); err != nil {
+		return err
+	}
+
+	return g.zipWriter.Flush()
+}
+
+// Tar headers hold the size of the member so each member is generated in full before it is written
+func (g *ArchiveGenerator) writeTarMember() error {
+	name, member := g.nextMember()
+
+	var content bytes.Buffer
+	content.Write(member.Start())
+	for i := g.rand.RandomInt(minArchiveMemberChunks, maxArchiveMemberChunks); i > 0; i-- {
+		content.Write(member.GenerateChunk())
+		if i > 1 {
+			content.Write(member.ChunkSeparator())
+		}
+	}
+	content.Write(member.End())
+
+	err := g.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o644,
+		Size:     int64(content.Len()),
+		ModTime:  g.modified,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := g.tarWriter.Write(content.Bytes()); err != nil {
+		return err
+	}
+
+	if err := g.tarWriter.Flush(); err != nil {
+		return err
+	}
+
+	return g.gzWriter.Flush()
+}
+
+func (g *ArchiveGenerator) writeGzipChunk() error {
+	if g.memberChunks == 0 {
+		if _, err := g.gzWriter.Write(g.member.Start()); err != nil {
+			return err
+		}
+	} else if _, err := g.gzWriter.Write(g.member.ChunkSeparator()); err != nil {
+		return err
+	}
+	g.memberChunks++
+
+	if _, err := g.gzWriter.Write(g.member.GenerateChunk()); err != nil {
+		return err
+	}
+
+	return g.gzWriter.Flush()
+}
+
+// Picks the name of the next member and creates a generator for it based on its extension
+func (g *ArchiveGenerator) nextMember() (string, Generator) {
+	name := strings.ReplaceAll(g.rand.StringChoice(&archiveMemberNames), "%s", g.rand.StringChoice(&mazeWords))
+	if g.rand.RandomBool() {
+		name = g.root + "/" + name
+	}
+
+	return name, GetGeneratorForPath(name, encoder.GetEncoderForPath(name), g.configGenerators, g.tabularSchemas, g.secrets, g.stream)
+}
+
+// Takes everything written to the archive since the last call
+func (g *ArchiveGenerator) flush() []byte {
+	data := bytes.Clone(g.out.Bytes())
+	g.out.Reset()
+
+	return data
+}
+
+func (w *flushingFlateWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	return n, w.Writer.Flush()
+}
@@ -2,27 +2,73 @@ package generator
 
 import (
 	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/internal/chaff"
 	"github.com/ryanolee/go-pot/secrets"
 )
 
+// Attempts at generating a value from a schema before moving on to another
+const maxSchemaAttempts = 3
+
 type (
+	// Generates structured config files. Every chunk of a stream comes from the same schema so the output reads as one document
 	ConfigGenerator struct {
-		encoder    encoder.Encoder
-		generators *ConfigGeneratorCollection
-		secrets    *secrets.SecretGeneratorCollection
+		encoder encoder.Encoder
+		values  *schemaValues
+		secrets *secrets.SecretGeneratorCollection
+	}
+
+	// Generates values from the schema picked for a stream. Some schemas generate nothing at all (i.e those
+	// with an allOf at the root) or only some of the time so another schema is picked rather than sending null
+	schemaValues struct {
+		collection *ConfigGeneratorCollection
+		schema     *chaff.RootGenerator
+		stream     *StreamSource
+		options    *chaff.GeneratorOptions
 	}
 )
 
-func NewConfigGenerator(encoder encoder.Encoder, collection *ConfigGeneratorCollection, secrets *secrets.SecretGeneratorCollection) *ConfigGenerator {
+func NewConfigGenerator(encoder encoder.Encoder, collection *ConfigGeneratorCollection, secrets *secrets.SecretGeneratorCollection, path string, stream *StreamSource) *ConfigGenerator {
 	return &ConfigGenerator{
-		encoder:    encoder,
-		generators: collection,
-		secrets:    secrets,
+		encoder: encoder,
+		values:  newSchemaValues(collection, path, stream),
+		secrets: secrets,
+	}
+}
+
+// Values are drawn from the stream including strings generated from the patterns of the schema
+func newSchemaValues(collection *ConfigGeneratorCollection, path string, stream *StreamSource) *schemaValues {
+	return &schemaValues{
+		collection: collection,
+		schema:     collection.GetGeneratorForPath(path, stream.Rand),
+		stream:     stream,
+		options: &chaff.GeneratorOptions{
+			Rand: stream.Rand,
+		},
 	}
 }
 
+// Generates the next value. The rest of the stream uses whichever schema the value came from
+func (v *schemaValues) next() interface{} {
+	for picks := 0; picks < maxSchemaAttempts; picks++ {
+		for attempt := 0; attempt < maxSchemaAttempts; attempt++ {
+			var value interface{}
+			v.stream.Fake(func() {
+				value = v.schema.Generate(v.options)
+			})
+
+			if value != nil {
+				return value
+			}
+		}
+
+		v.schema = v.collection.GetRandomGenerator(v.stream.Rand)
+	}
+
+	return map[string]interface{}{}
+}
+
 func (g *ConfigGenerator) Generate() []byte {
-	gen := g.generators.GetRandomGenerator().GenerateWithDefaults()
+	gen := g.values.next()
 	gen = secrets.InjectSecrets(g.secrets, gen)
 	data, err := g.encoder.Marshal(gen)
 	if err != nil {
@@ -2,13 +2,19 @@ package generator
 
 import (
 	"embed"
+	"errors"
 	"fmt"
-	"io/fs"
-
-	"github.com/ryanolee/go-chaff"
-	"github.com/ryanolee/go-chaff/rand"
-	"github.com/thoas/go-funk"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/internal/chaff"
+	"github.com/ryanolee/go-pot/rand"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var (
@@ -21,62 +27,187 @@ const schemaDir = "data/schema"
 type (
 	ConfigGeneratorCollection struct {
 		generators map[string]*chaff.RootGenerator
+		mappings   []schemaMapping
+
+		// Names of the schemas in order so the same random draw always picks the same schema
+		names []string
+	}
+
+	// A schema to use for paths matching the pattern
+	schemaMapping struct {
+		pattern *regexp.Regexp
+		schema  string
 	}
 )
 
-func NewConfigGeneratorCollection() (*ConfigGeneratorCollection, error) {
-	schemaDir, err := schemaFiles.ReadDir(schemaDir)
+func NewConfigGeneratorCollection(conf *config.Config) (*ConfigGeneratorCollection, error) {
+	generators := make(map[string]*chaff.RootGenerator)
+
+	if conf.Generator.Schemas.Mode != "replace" {
+		if err := loadEmbeddedSchemas(generators); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every file that fails to load is reported at once so they can all be fixed before starting again
+	var errs []error
+	for _, dir := range conf.Generator.Schemas.Directories {
+		errs = append(errs, loadSchemaDir(generators, dir)...)
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%d schema file(s) failed to load: %w", len(errs), errors.Join(errs...))
+	}
+
+	if len(generators) == 0 {
+		return nil, fmt.Errorf("no usable schemas were found to generate configs from")
+	}
 
+	mappings, err := parseSchemaMappings(conf, generators)
 	if err != nil {
 		return nil, err
 	}
 
-	logger := zap.L().Sugar()
-	generators := make(map[string]*chaff.RootGenerator)
-	for _, dirEntry := range schemaDir {
-		logger.Debug("Parsing Schema File", "filename", dirEntry.Name())
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-		generator, err := parseSchemaFile(dirEntry)
+	return &ConfigGeneratorCollection{
+		generators: generators,
+		mappings:   mappings,
+		names:      names,
+	}, nil
+}
+
+func parseSchemaMappings(conf *config.Config, generators map[string]*chaff.RootGenerator) ([]schemaMapping, error) {
+	mappings := []schemaMapping{}
+	for _, mapping := range conf.Generator.Schemas.PathMappings {
+		pattern, err := regexp.Compile(mapping.Pattern)
 		if err != nil {
-			logger.Warnw("Failed to parse schema file", "filename", dirEntry.Name(), "error", err)
+			return nil, fmt.Errorf("invalid schema path mapping pattern %s: %w", mapping.Pattern, err)
+		}
+
+		schema, ok := findSchema(generators, mapping.Schema)
+		if !ok {
+			zap.L().Sugar().Warnw("Schema for path mapping not found. Matching paths will use a random schema", "pattern", mapping.Pattern, "schema", mapping.Schema)
 			continue
 		}
 
-		for path, err := range generator.Metadata.Errors {
-			logger.Debug("Issue when parsing schema file", "filename", dirEntry.Name(), "path", path, "error", err)
+		mappings = append(mappings, schemaMapping{pattern: pattern, schema: schema})
+	}
+
+	return mappings, nil
+}
+
+// Finds a schema by file name. Paths to files in the schema directories are matched by their file name
+func findSchema(generators map[string]*chaff.RootGenerator, name string) (string, bool) {
+	if _, ok := generators[name]; ok {
+		return name, true
+	}
+
+	if _, ok := generators[filepath.Base(name)]; ok {
+		return filepath.Base(name), true
+	}
+
+	return "", false
+}
+
+func loadEmbeddedSchemas(generators map[string]*chaff.RootGenerator) error {
+	entries, err := schemaFiles.ReadDir(schemaDir)
+	if err != nil {
+		return err
+	}
+
+	for _, dirEntry := range entries {
+		if dirEntry.IsDir() {
+			zap.L().Sugar().Warnw("Failed to parse schema file", "filename", dirEntry.Name(), "error", "file is a dir")
+			continue
 		}
 
-		generators[dirEntry.Name()] = generator
+		contents, err := schemaFiles.ReadFile(fmt.Sprintf("%s/%s", schemaDir, dirEntry.Name()))
+		if err != nil {
+			return err
+		}
+
+		// Issues with the embedded schemas can not be fixed by users so they are only logged when debugging
+		if err := addSchema(generators, dirEntry.Name(), contents, zap.DebugLevel); err != nil {
+			zap.L().Sugar().Warnw("Failed to parse schema file", "filename", dirEntry.Name(), "error", err)
+		}
 	}
 
-	return &ConfigGeneratorCollection{
-		generators: generators,
-	}, nil
+	return nil
 }
 
-func parseSchemaFile(entry fs.DirEntry) (*chaff.RootGenerator, error) {
-	var contents []byte
-	var generator chaff.RootGenerator
-	var err error
+// Loads every *.json file in a user supplied directory. The errors of each file that fails to load are returned
+// Schemas are keyed by file name so they take the place of embedded schemas (or schemas from earlier directories) with the same name
+func loadSchemaDir(generators map[string]*chaff.RootGenerator, dir string) []error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read schema dir %s: %w", dir, err)}
+	}
+
+	loaded := 0
+	errs := []error{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		_, overridden := generators[entry.Name()]
+		contents, err := os.ReadFile(path)
+		if err == nil {
+			err = addSchema(generators, entry.Name(), contents, zap.WarnLevel)
+		}
+
+		if err != nil {
+			zap.L().Sugar().Errorw("Failed to load schema file", "filename", path, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
 
-	if entry.IsDir() {
-		return nil, fmt.Errorf("failed to parse schema file %s. file is a dir", entry.Name())
+		if overridden {
+			zap.L().Sugar().Infow("Schema file overrides a schema with the same name", "filename", path, "schema", entry.Name())
+		}
+
+		loaded++
 	}
 
-	if contents, err = schemaFiles.ReadFile(fmt.Sprintf("%s/%s", schemaDir, entry.Name())); err != nil {
-		return nil, err
+	zap.L().Sugar().Infow("Loaded schema dir", "dir", dir, "loaded", loaded, "failed", len(errs))
+	return errs
+}
+
+// Parses the schema adding it to the generators. Parts of the schema that could not be parsed are logged at the given level
+func addSchema(generators map[string]*chaff.RootGenerator, name string, contents []byte, issueLevel zapcore.Level) error {
+	logger := zap.L().Sugar()
+	logger.Debugw("Parsing Schema File", "filename", name)
+
+	generator, err := chaff.ParseSchemaWithDefaults(contents)
+	if err != nil {
+		return err
 	}
 
-	if generator, err = chaff.ParseSchemaWithDefaults(contents); err != nil {
-		return nil, err
+	for path, err := range generator.Metadata.Errors {
+		logger.Logw(issueLevel, "Issue when parsing schema file", "filename", name, "path", path, "error", err)
 	}
 
-	return &generator, nil
+	generators[name] = &generator
+	return nil
 }
 
-func (g *ConfigGeneratorCollection) GetRandomGenerator() *chaff.RootGenerator {
-	rnd := rand.NewRandUtilFromTime()
-	key := funk.Keys(g.generators).([]string)[rnd.RandomInt(0, len(g.generators))]
+// Gets the generator for the schema mapped to the given path falling back to a random schema drawn from rnd
+func (g *ConfigGeneratorCollection) GetGeneratorForPath(path string, rnd *rand.SeededRand) *chaff.RootGenerator {
+	for _, mapping := range g.mappings {
+		if mapping.pattern.MatchString(path) {
+			return g.generators[mapping.schema]
+		}
+	}
+
+	return g.GetRandomGenerator(rnd)
+}
 
-	return g.generators[key]
+func (g *ConfigGeneratorCollection) GetRandomGenerator(rnd *rand.SeededRand) *chaff.RootGenerator {
+	return g.generators[rnd.StringChoice(&g.names)]
 }
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/spf13/cobra"
+)
+
+const userSchema = `{"type": "string", "const": "user schema"}`
+
+// Schemas from the schema directories take the place of embedded schemas with the same file name
+func TestUserSchemasOverrideEmbeddedSchemas(t *testing.T) {
+	embedded, err := schemaFiles.ReadDir(schemaDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"package.json", "custom.json"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(userSchema), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		mode      string
+		wantCount int
+	}{
+		{mode: "merge", wantCount: len(embedded) + 1},
+		{mode: "replace", wantCount: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			conf := newTestConfig(t)
+			conf.Generator.Schemas.Mode = test.mode
+			conf.Generator.Schemas.Directories = []string{dir}
+
+			collection, err := NewConfigGeneratorCollection(conf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(collection.names) != test.wantCount {
+				t.Errorf("expected %d schemas got %d", test.wantCount, len(collection.names))
+			}
+
+			for _, name := range []string{"package.json", "custom.json"} {
+				generator, ok := collection.generators[name]
+				if !ok {
+					t.Fatalf("expected schema %s to be loaded", name)
+				}
+
+				if got := generator.GenerateWithDefaults(); got != "user schema" {
+					t.Errorf("expected %s to generate from the user schema got %v", name, got)
+				}
+			}
+
+			if _, ok := findSchema(collection.generators, filepath.Join(dir, "package.json")); !ok {
+				t.Error("expected the schema to be found by its path")
+			}
+		})
+	}
+}
+
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	conf, err := config.NewConfig(config.BindConfigFileFlags(&cobra.Command{}), config.GetStartFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conf
+}
@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+)
+
+var (
+	credentialProfiles = []string{"production", "staging", "dev", "admin", "deploy", "backup", "billing", "terraform", "ci", "legacy"}
+	credentialServices = []string{"git", "registry", "nexus", "artifactory", "packages", "repo", "builds", "mirror"}
+	credentialDomains  = []string{"acme-corp.internal", "example-inc.com", "globex.io", "initech.local", "corp.umbrella-it.net", "hooli.dev"}
+	credentialUsers    = []string{"deploy", "jenkins", "ci-bot", "admin", "svc-build", "release", "root", "devops"}
+	credentialRegions  = []string{"us-east-1", "us-east-2", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-2"}
+
+	// AWS secret access keys are 40 characters of base64
+	awsSecretKeyRunes = []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/")
+)
+
+type (
+	// Generates entries for credential files. Secrets for the rules asked for by the encoder are
+	// generated for every entry so each field gets a value in the format it expects
+	CredentialsGenerator struct {
+		encoder encoder.Encoder
+		secrets *secrets.SecretGeneratorCollection
+		rand    *rand.SeededRand
+		rules   []string
+		index   int
+	}
+)
+
+func NewCredentialsGenerator(enc encoder.Encoder, secrets *secrets.SecretGeneratorCollection, stream *StreamSource) *CredentialsGenerator {
+	rules := []string{}
+	if credentialsEncoder, ok := enc.(*encoder.CredentialsEncoder); ok {
+		rules = credentialsEncoder.SecretRules()
+	}
+
+	return &CredentialsGenerator{
+		encoder: enc,
+		secrets: secrets,
+		rand:    stream.Rand,
+		rules:   rules,
+	}
+}
+
+func (g *CredentialsGenerator) Generate() []byte {
+	entry := encoder.CredentialEntry{
+		Index:     g.index,
+		Name:      fmt.Sprintf("%s-%d", g.rand.StringChoice(&credentialProfiles), g.index),
+		Host:      g.rand.StringChoice(&credentialServices) + "." + g.rand.StringChoice(&credentialDomains),
+		Region:    g.rand.StringChoice(&credentialRegions),
+		User:      g.rand.StringChoice(&credentialUsers),
+		Password:  g.rand.RandomString(g.rand.RandomInt(16, 33)),
+		SecretKey: g.rand.RandomString(40, awsSecretKeyRunes),
+		Secrets:   map[string]string{},
+	}
+	g.index++
+
+	for _, rule := range g.rules {
+		entry.Secrets[rule] = g.secrets.GenerateSecret(rule)
+	}
+
+	data, err := g.encoder.Marshal(entry)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (g *CredentialsGenerator) Start() []byte {
+	return []byte(g.encoder.Start())
+}
+
+func (g *CredentialsGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+func (g *CredentialsGenerator) ChunkSeparator() []byte {
+	return []byte(g.encoder.Delimiter())
+}
+
+func (g *CredentialsGenerator) End() []byte {
+	return []byte(g.encoder.End())
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/secrets"
+)
+
+type (
+	// Generates dotenv files. Secrets are generated separately from the rest of the config so
+	// they can be written at the top of each chunk
+	DotenvGenerator struct {
+		encoder encoder.Encoder
+		values  *schemaValues
+		secrets *secrets.SecretGeneratorCollection
+	}
+)
+
+func NewDotenvGenerator(encoder encoder.Encoder, collection *ConfigGeneratorCollection, secrets *secrets.SecretGeneratorCollection, path string, stream *StreamSource) *DotenvGenerator {
+	return &DotenvGenerator{
+		encoder: encoder,
+		values:  newSchemaValues(collection, path, stream),
+		secrets: secrets,
+	}
+}
+
+func (g *DotenvGenerator) Generate() []byte {
+	values := secrets.InjectSecrets(g.secrets, g.values.next())
+	topSecrets, _ := secrets.InjectSecrets(g.secrets, map[string]interface{}{}).(map[string]interface{})
+
+	data, err := g.encoder.Marshal(encoder.DotenvDocument{
+		Secrets: topSecrets,
+		Values:  values,
+	})
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (g *DotenvGenerator) Start() []byte {
+	return []byte(g.encoder.Start())
+}
+
+func (g *DotenvGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+func (g *DotenvGenerator) ChunkSeparator() []byte {
+	return []byte(g.encoder.Delimiter())
+}
+
+func (g *DotenvGenerator) End() []byte {
+	return []byte(g.encoder.End())
+}
@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+)
+
+var (
+	dumpDatabases = []string{"app_production", "app", "prod", "shop", "wordpress", "crm", "main", "billing", "customers", "laravel"}
+	dumpHosts     = []string{"localhost", "127.0.0.1", "db-prod-01", "mysql.internal", "10.0.1.23"}
+)
+
+type (
+	// Streams a SQL dump of several tables in the style of mysqldump or pg_dump. Each table but the last
+	// is given a few batches of rows before moving onto the next. The last table is dumped forever
+	DumpGenerator struct {
+		encoder *encoder.SqlEncoder
+		dump    *encoder.SqlDump
+		secrets *secrets.SecretGeneratorCollection
+		rand    *rand.SeededRand
+
+		tables        []*source.TabularSchema
+		rows          *source.TabularRows
+		table         int
+		tableRows     int
+		tableRowLimit int
+		batchRows     int
+		rowsPerInsert int
+	}
+)
+
+func NewDumpGenerator(enc encoder.Encoder, tabularSchemas *source.TabularSchemaCollection, path string, secrets *secrets.SecretGeneratorCollection, stream *StreamSource) *DumpGenerator {
+	sqlEncoder, ok := enc.(*encoder.SqlEncoder)
+	if !ok {
+		sqlEncoder = encoder.NewSqlEncoder(encoder.SqlDialectMysql)
+	}
+
+	// A dialect set in the config takes priority over the one picked by the path
+	if dialect := tabularSchemas.DumpDialect(); dialect != "auto" && dialect != sqlEncoder.Dialect() {
+		sqlEncoder = encoder.NewSqlEncoder(dialect)
+	}
+
+	random := stream.Rand
+	versions := sqlEncoder.ServerVersions()
+	g := &DumpGenerator{
+		encoder: sqlEncoder,
+		dump: &encoder.SqlDump{
+			Database:      random.StringChoice(&dumpDatabases),
+			Host:          random.StringChoice(&dumpHosts),
+			ServerVersion: random.StringChoice(&versions),
+			Now:           stream.Now,
+		},
+		secrets:       secrets,
+		rand:          random,
+		tables:        tabularSchemas.DumpTables(path),
+		rowsPerInsert: tabularSchemas.DumpRowsPerInsert(),
+	}
+
+	g.startTable(0)
+	return g
+}
+
+func (g *DumpGenerator) Generate() []byte {
+	var chunk strings.Builder
+	schema := g.tables[g.table]
+
+	if g.batchRows == 0 {
+		if g.tableRows == 0 {
+			chunk.WriteString(g.encoder.StartTable(schema))
+		}
+		chunk.WriteString(g.encoder.StartInsert(schema))
+	} else {
+		chunk.WriteString(g.encoder.Delimiter())
+	}
+
+	chunk.Write(g.encoder.MarshalRow(schema, g.rows.Next()))
+	g.batchRows++
+	g.tableRows++
+
+	tableDone := g.tableRowLimit != 0 && g.tableRows >= g.tableRowLimit
+	if g.batchRows >= g.rowsPerInsert || tableDone {
+		chunk.WriteString(g.encoder.EndInsert())
+		g.batchRows = 0
+	}
+
+	if tableDone {
+		chunk.WriteString(g.encoder.EndTable(schema))
+		g.startTable(g.table + 1)
+	}
+
+	return []byte(chunk.String())
+}
+
+func (g *DumpGenerator) Start() []byte {
+	return []byte(g.encoder.StartDump(g.dump))
+}
+
+func (g *DumpGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+// Rows carry their own delimiters as statements are ended between batches
+func (g *DumpGenerator) ChunkSeparator() []byte {
+	return []byte{}
+}
+
+func (g *DumpGenerator) End() []byte {
+	var end strings.Builder
+	if g.tableRows > 0 {
+		if g.batchRows > 0 {
+			end.WriteString(g.encoder.EndInsert())
+		}
+		end.WriteString(g.encoder.EndTable(g.tables[g.table]))
+	}
+	end.WriteString(g.encoder.EndDump(g.dump))
+
+	return []byte(end.String())
+}
+
+// Starts dumping the table at the given index. Tables other than the last are given a limited number of rows
+func (g *DumpGenerator) startTable(table int) {
+	g.table = table
+	g.tableRows = 0
+	g.batchRows = 0
+	g.tableRowLimit = 0
+	if table < len(g.tables)-1 {
+		g.tableRowLimit = g.rand.RandomInt(g.rowsPerInsert, g.rowsPerInsert*4+1)
+	}
+
+	g.rows = g.tables[table].NewRows(g.rand, g.dump.Now, g.secrets)
+}
@@ -0,0 +1,69 @@
+package encoder
+
+import "fmt"
+
+const (
+	ArchiveFormatZip   = "zip"
+	ArchiveFormatTarGz = "tar.gz"
+	// A single compressed file (i.e db.sql.gz)
+	ArchiveFormatGzip = "gzip"
+)
+
+type (
+	// Describes an archive. Archives are binary streams so they are written by the archive generator
+	// rather than marshalled a value at a time
+	ArchiveEncoder struct {
+		format string
+		member Encoder
+	}
+)
+
+func NewZipEncoder() *ArchiveEncoder {
+	return &ArchiveEncoder{format: ArchiveFormatZip}
+}
+
+func NewTarGzEncoder() *ArchiveEncoder {
+	return &ArchiveEncoder{format: ArchiveFormatTarGz}
+}
+
+// Gzip streams hold a single file encoded by the given encoder
+func NewGzipEncoder(member Encoder) *ArchiveEncoder {
+	return &ArchiveEncoder{format: ArchiveFormatGzip, member: member}
+}
+
+func (e *ArchiveEncoder) Marshal(v interface{}) ([]byte, error) {
+	return nil, fmt.Errorf("%s archives can not marshal values directly", e.format)
+}
+
+func (e *ArchiveEncoder) Format() string {
+	return e.format
+}
+
+// Encoder for the file inside a gzip stream (nil for other formats)
+func (e *ArchiveEncoder) Member() Encoder {
+	return e.member
+}
+
+func (*ArchiveEncoder) Start() string {
+	return ""
+}
+
+func (*ArchiveEncoder) End() string {
+	return ""
+}
+
+func (*ArchiveEncoder) Delimiter() string {
+	return ""
+}
+
+func (e *ArchiveEncoder) ContentType() string {
+	if e.format == ArchiveFormatZip {
+		return "application/zip"
+	}
+
+	return "application/gzip"
+}
+
+func (*ArchiveEncoder) GetSupportedGenerator() string {
+	return "archive"
+}
@@ -0,0 +1,257 @@
+package encoder
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type (
+	// Encodes well known credential files (i.e ~/.aws/credentials or .npmrc). Each format asks for secrets
+	// from specific rules so generated values end up in the field a real tool would read them from
+	CredentialsEncoder struct {
+		format credentialFormat
+	}
+
+	// A single profile, host or registry entry in a credential file
+	CredentialEntry struct {
+		// Position of the entry in the file. The first entries are used for the default profile or well known hosts
+		Index    int
+		Name     string
+		Host     string
+		Region   string
+		User     string
+		Password string
+		// Secret half of a key pair for formats with no matching rule (i.e aws_secret_access_key)
+		SecretKey string
+		// Secrets keyed by the name of the rule they were generated from
+		Secrets map[string]string
+	}
+
+	credentialFormat struct {
+		rules       []string
+		start       string
+		end         string
+		delimiter   string
+		contentType string
+		marshal     func(entry CredentialEntry) string
+	}
+)
+
+const (
+	awsAccessKeyRule = "aws-access-token"
+	npmTokenRule     = "npm-access-token"
+	pypiTokenRule    = "pypi-upload-token"
+	githubTokenRule  = "github-pat"
+	gitlabTokenRule  = "gitlab-pat"
+	jwtRule          = "jwt"
+)
+
+// ~/.aws/credentials
+func NewAwsCredentialsEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{awsAccessKeyRule},
+		delimiter:   "\n",
+		contentType: "text/plain",
+		marshal: func(entry CredentialEntry) string {
+			return fmt.Sprintf("[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\nregion = %s\n",
+				profileName(entry), awsAccessKeyId(entry), entry.SecretKey, entry.Region)
+		},
+	}}
+}
+
+// .s3cfg as written by s3cmd --configure
+func NewS3cfgEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{awsAccessKeyRule},
+		delimiter:   "\n",
+		contentType: "text/plain",
+		marshal: func(entry CredentialEntry) string {
+			return fmt.Sprintf("[%s]\naccess_key = %s\nsecret_key = %s\nbucket_location = %s\nhost_base = s3.amazonaws.com\nhost_bucket = %%(bucket)s.s3.amazonaws.com\nuse_https = True\n",
+				profileName(entry), awsAccessKeyId(entry), entry.SecretKey, entry.Region)
+		},
+	}}
+}
+
+// .npmrc with auth tokens for the public and private registries
+func NewNpmrcEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{npmTokenRule},
+		delimiter:   "",
+		contentType: "text/plain",
+		marshal: func(entry CredentialEntry) string {
+			// The rule is case insensitive but real tokens always use a lower case prefix
+			token := entry.Secrets[npmTokenRule]
+			if strings.HasPrefix(strings.ToLower(token), "npm_") {
+				token = "npm_" + token[4:]
+			}
+
+			if entry.Index == 0 {
+				return fmt.Sprintf("registry=https://registry.npmjs.org/\n//registry.npmjs.org/:_authToken=%s\n", token)
+			}
+
+			return fmt.Sprintf("@%s:registry=https://%s/\n//%s/:_authToken=%s\n", entry.Name, entry.Host, entry.Host, token)
+		},
+	}}
+}
+
+// .pypirc with upload tokens for each index server
+func NewPypircEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{pypiTokenRule},
+		start:       "[distutils]\nindex-servers =\n    pypi\n    testpypi\n    private\n\n",
+		delimiter:   "\n",
+		contentType: "text/plain",
+		marshal: func(entry CredentialEntry) string {
+			switch entry.Index {
+			case 0:
+				return fmt.Sprintf("[pypi]\nusername = __token__\npassword = %s\n", entry.Secrets[pypiTokenRule])
+			case 1:
+				return fmt.Sprintf("[testpypi]\nrepository = https://test.pypi.org/legacy/\nusername = __token__\npassword = %s\n", entry.Secrets[pypiTokenRule])
+			}
+
+			name := entry.Name
+			if entry.Index == 2 {
+				name = "private"
+			}
+
+			return fmt.Sprintf("[%s]\nrepository = https://%s/simple/\nusername = %s\npassword = %s\n", name, entry.Host, entry.User, entry.Password)
+		},
+	}}
+}
+
+// .netrc as used by curl, git and ftp clients
+func NewNetrcEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{githubTokenRule, gitlabTokenRule},
+		delimiter:   "\n",
+		contentType: "text/plain",
+		marshal: func(entry CredentialEntry) string {
+			host, user, password := gitHostCredentials(entry)
+			return fmt.Sprintf("machine %s\n  login %s\n  password %s\n", host, user, password)
+		},
+	}}
+}
+
+// .git-credentials as written by the git "store" credential helper
+func NewGitCredentialsEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{githubTokenRule, gitlabTokenRule},
+		delimiter:   "",
+		contentType: "text/plain",
+		marshal: func(entry CredentialEntry) string {
+			host, user, password := gitHostCredentials(entry)
+			return fmt.Sprintf("https://%s:%s@%s\n", user, password, host)
+		},
+	}}
+}
+
+// .docker/config.json with base64 encoded auths for each registry
+func NewDockerConfigEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules:       []string{githubTokenRule, gitlabTokenRule},
+		start:       "{\n\t\"auths\": {\n",
+		end:         "\n\t}\n}\n",
+		delimiter:   ",\n",
+		contentType: "application/json",
+		marshal: func(entry CredentialEntry) string {
+			host, user, password := entry.Host, entry.User, entry.Password
+			switch entry.Index {
+			case 0:
+				host, password = "ghcr.io", entry.Secrets[githubTokenRule]
+			case 1:
+				host, password = "registry.gitlab.com", entry.Secrets[gitlabTokenRule]
+			}
+
+			registry, _ := json.Marshal(host)
+			auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+			return fmt.Sprintf("\t\t%s: {\n\t\t\t\"auth\": \"%s\"\n\t\t}", registry, auth)
+		},
+	}}
+}
+
+// kubeconfig. Users are streamed at the end of the file so the clusters and contexts can be written up front
+func NewKubeconfigEncoder() *CredentialsEncoder {
+	return &CredentialsEncoder{format: credentialFormat{
+		rules: []string{jwtRule},
+		start: "apiVersion: v1\nkind: Config\npreferences: {}\ncurrent-context: production\n" +
+			"clusters:\n- name: production\n  cluster:\n    server: https://k8s.internal:6443\n    insecure-skip-tls-verify: true\n" +
+			"contexts:\n- name: production\n  context:\n    cluster: production\n    namespace: default\n    user: admin\n" +
+			"users:\n",
+		delimiter:   "",
+		contentType: "application/yaml",
+		marshal: func(entry CredentialEntry) string {
+			name := entry.Name
+			if entry.Index == 0 {
+				name = "admin"
+			}
+
+			return fmt.Sprintf("- name: %s\n  user:\n    token: %s\n", name, entry.Secrets[jwtRule])
+		},
+	}}
+}
+
+func (e *CredentialsEncoder) Marshal(v interface{}) ([]byte, error) {
+	entry, ok := v.(CredentialEntry)
+	if !ok {
+		return nil, fmt.Errorf("credentials encoder can only marshal credential entries")
+	}
+
+	return []byte(e.format.marshal(entry)), nil
+}
+
+// Names of the secret rules the format expects to find in CredentialEntry.Secrets
+func (e *CredentialsEncoder) SecretRules() []string {
+	return e.format.rules
+}
+
+func (e *CredentialsEncoder) Start() string {
+	return e.format.start
+}
+
+func (e *CredentialsEncoder) End() string {
+	return e.format.end
+}
+
+func (e *CredentialsEncoder) Delimiter() string {
+	return e.format.delimiter
+}
+
+func (e *CredentialsEncoder) ContentType() string {
+	return e.format.contentType
+}
+
+func (*CredentialsEncoder) GetSupportedGenerator() string {
+	return "credentials"
+}
+
+func profileName(entry CredentialEntry) string {
+	if entry.Index == 0 {
+		return "default"
+	}
+
+	return entry.Name
+}
+
+// Access keys given to users always start with AKIA. The rule also covers other kinds of key ids
+func awsAccessKeyId(entry CredentialEntry) string {
+	key := entry.Secrets[awsAccessKeyRule]
+	if len(key) < 4 {
+		return key
+	}
+
+	return "AKIA" + key[4:]
+}
+
+// The first entries are for the well known git hosts using their own token formats
+func gitHostCredentials(entry CredentialEntry) (string, string, string) {
+	switch entry.Index {
+	case 0:
+		return "github.com", entry.User, entry.Secrets[githubTokenRule]
+	case 1:
+		return "gitlab.com", "oauth2", entry.Secrets[gitlabTokenRule]
+	}
+
+	return entry.Host, entry.User, entry.Password
+}
@@ -3,7 +3,6 @@ package encoder
 import (
 	"bytes"
 	"encoding/csv"
-	"strings"
 
 	"github.com/ryanolee/go-pot/generator/source"
 )
@@ -26,8 +25,18 @@ func (e *CsvEncoder) Marshal(v interface{}) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// The header is written by StartTable
 func (*CsvEncoder) Start() string {
-	return strings.Join(source.GetTabularHeaderFields(), ",") + "\n"
+	return ""
+}
+
+func (e *CsvEncoder) StartTable(schema *source.TabularSchema) string {
+	header, err := e.Marshal(schema.ColumnNames())
+	if err != nil {
+		return ""
+	}
+
+	return string(header)
 }
 
 func (*CsvEncoder) End() string {
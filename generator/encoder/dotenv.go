@@ -0,0 +1,177 @@
+package encoder
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type (
+	DotenvEncoder struct{}
+
+	// A single chunk of a dotenv file. Secrets are written first followed by the rest of the values
+	DotenvDocument struct {
+		Secrets map[string]interface{}
+		Values  interface{}
+	}
+
+	dotenvLine struct {
+		key   string
+		value string
+	}
+)
+
+var (
+	dotenvInvalidKeyChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	dotenvUnquotedValue   = regexp.MustCompile(`^[A-Za-z0-9_\-./:@,+]*$`)
+)
+
+func NewDotenvEncoder() *DotenvEncoder {
+	return &DotenvEncoder{}
+}
+
+func (e *DotenvEncoder) Marshal(v interface{}) ([]byte, error) {
+	document, ok := v.(DotenvDocument)
+	if !ok {
+		document = DotenvDocument{Values: v}
+	}
+
+	var builder strings.Builder
+	for _, line := range flattenDotenv("", document.Secrets) {
+		builder.WriteString(line.String())
+	}
+
+	values, ok := document.Values.(map[string]interface{})
+	if !ok {
+		return []byte(builder.String()), nil
+	}
+
+	// Plain values follow the secrets. Nested values each get their own commented block
+	nested := []string{}
+	for _, key := range sortedKeys(values) {
+		switch values[key].(type) {
+		case map[string]interface{}, []interface{}:
+			nested = append(nested, key)
+		default:
+			for _, line := range flattenDotenv(dotenvKey(key), values[key]) {
+				builder.WriteString(line.String())
+			}
+		}
+	}
+
+	for _, key := range nested {
+		lines := flattenDotenv(dotenvKey(key), values[key])
+		if len(lines) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&builder, "\n# %s\n", strings.ToLower(dotenvKey(key)))
+		for _, line := range lines {
+			builder.WriteString(line.String())
+		}
+	}
+
+	return []byte(builder.String()), nil
+}
+
+func (*DotenvEncoder) Start() string {
+	return ""
+}
+
+func (*DotenvEncoder) End() string {
+	return ""
+}
+
+func (*DotenvEncoder) Delimiter() string {
+	return "\n"
+}
+
+func (*DotenvEncoder) ContentType() string {
+	return "text/plain"
+}
+
+func (*DotenvEncoder) GetSupportedGenerator() string {
+	return "dotenv"
+}
+
+// Flattens nested maps and lists into upper snake case keys. i.e {"db": {"host": "x"}} becomes DB_HOST=x
+func flattenDotenv(prefix string, v interface{}) []dotenvLine {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		lines := []dotenvLine{}
+		for _, key := range sortedKeys(value) {
+			lines = append(lines, flattenDotenv(joinDotenvKey(prefix, dotenvKey(key)), value[key])...)
+		}
+		return lines
+	case []interface{}:
+		lines := []dotenvLine{}
+		for i, item := range value {
+			lines = append(lines, flattenDotenv(joinDotenvKey(prefix, fmt.Sprint(i)), item)...)
+		}
+		return lines
+	case nil:
+		if prefix == "" {
+			return nil
+		}
+		return []dotenvLine{{key: prefix, value: ""}}
+	}
+
+	if prefix == "" {
+		return nil
+	}
+
+	return []dotenvLine{{key: prefix, value: fmt.Sprint(v)}}
+}
+
+// Converts a key into upper snake case. i.e "apiKey" or "api-key" become API_KEY
+func dotenvKey(key string) string {
+	var builder strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])) {
+			builder.WriteRune('_')
+		}
+		builder.WriteRune(r)
+	}
+
+	snake := strings.Trim(dotenvInvalidKeyChars.ReplaceAllString(builder.String(), "_"), "_")
+	if snake == "" {
+		return "VALUE"
+	}
+
+	// Variable names can not start with a digit
+	if unicode.IsDigit(rune(snake[0])) {
+		snake = "_" + snake
+	}
+
+	return strings.ToUpper(snake)
+}
+
+func joinDotenvKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "_" + strings.TrimPrefix(key, "_")
+}
+
+func (l dotenvLine) String() string {
+	if dotenvUnquotedValue.MatchString(l.value) {
+		return fmt.Sprintf("%s=%s\n", l.key, l.value)
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "$", `\$`).Replace(l.value)
+	return fmt.Sprintf("%s=\"%s\"\n", l.key, escaped)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -1,5 +1,7 @@
 package encoder
 
+import "github.com/ryanolee/go-pot/generator/source"
+
 type Encoder interface {
 	GetSupportedGenerator() string
 	ContentType() string
@@ -9,3 +11,8 @@ type Encoder interface {
 	End() string
 }
 
+// Encoders of tabular data that start by describing the table rows are generated for
+type TableEncoder interface {
+	Encoder
+	StartTable(schema *source.TabularSchema) string
+}
@@ -1,6 +1,10 @@
 package encoder
 
-import "regexp"
+import (
+	"path"
+	"regexp"
+	"strings"
+)
 
 type MatchingEncoder struct {
 	encoder       Encoder
@@ -10,7 +14,90 @@ type MatchingEncoder struct {
 
 
 var defaultEncoder = NewJsonEncoder()
+var gzipRegexp = regexp.MustCompile(`\.gz$`)
+var tarGzRegexp = regexp.MustCompile(`\.(tar\.gz|tgz)$`)
 var encoders = []MatchingEncoder{
+	// Credential files come first as some of them would otherwise match on their extension (i.e .docker/config.json)
+	{
+		encoder: NewAwsCredentialsEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`\.aws/credentials$`),
+	},
+	{
+		encoder: NewS3cfgEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`\.s3cfg$`),
+	},
+	{
+		encoder: NewNpmrcEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`\.npmrc$`),
+	},
+	{
+		encoder: NewPypircEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`\.pypirc$`),
+	},
+	{
+		encoder: NewNetrcEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`[._]netrc$`),
+	},
+	{
+		encoder: NewGitCredentialsEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`\.git-credentials$`),
+	},
+	{
+		encoder: NewDockerConfigEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`\.docker/config\.json$`),
+	},
+	{
+		encoder: NewKubeconfigEncoder(),
+		generatorType: "credentials",
+		regexp:  regexp.MustCompile(`(^|/)kubeconfig$|\.kube/config$`),
+	},
+	{
+		encoder: NewPemEncoder(KeyTypeOpenSshRsa),
+		generatorType: "keys",
+		regexp:  regexp.MustCompile(`(^|/)id_(rsa|dsa|ecdsa)$`),
+	},
+	{
+		encoder: NewPemEncoder(KeyTypeOpenSshEd25519),
+		generatorType: "keys",
+		regexp:  regexp.MustCompile(`(^|/)id_ed25519$`),
+	},
+	{
+		encoder: NewPemEncoder(KeyTypeBundle),
+		generatorType: "keys",
+		regexp:  regexp.MustCompile(`\.pem$`),
+	},
+	{
+		encoder: NewPemEncoder(KeyTypePrivateKey),
+		generatorType: "keys",
+		regexp:  regexp.MustCompile(`\.key$`),
+	},
+	{
+		encoder: NewPemEncoder(KeyTypeCertificate),
+		generatorType: "keys",
+		regexp:  regexp.MustCompile(`\.(crt|cer)$`),
+	},
+	{
+		encoder: NewZipEncoder(),
+		generatorType: "archive",
+		regexp:  regexp.MustCompile(`\.zip$`),
+	},
+	{
+		encoder: NewTarGzEncoder(),
+		generatorType: "archive",
+		regexp:  tarGzRegexp,
+	},
+	{
+		encoder: NewDotenvEncoder(),
+		generatorType: "dotenv",
+		regexp:  regexp.MustCompile(`\.env(\.[\w-]+)*$`),
+	},
 	{
 		encoder: NewYamlEncoder(),
 		generatorType: "structured",
@@ -47,13 +134,28 @@ var encoders = []MatchingEncoder{
 		regexp:  regexp.MustCompile(`\.csv`),
 	},
 	{
-		encoder: NewSqlEncoder(),
-		generatorType: "tabular",
+		encoder: NewSqlEncoder(SqlDialectPostgres),
+		generatorType: "dump",
+		regexp:  regexp.MustCompile(`\.(pgsql|psql)|(pg_?dump|postgres|pgsql)[^/]*\.sql`),
+	},
+	{
+		encoder: NewSqlEncoder(SqlDialectMysql),
+		generatorType: "dump",
 		regexp:  regexp.MustCompile(`\.sql`),
 	},
+	{
+		encoder: NewHtmlEncoder(),
+		generatorType: "maze",
+		regexp:  regexp.MustCompile(`\.(html?|php)$`),
+	},
 }
 
 func GetEncoderForPath(path string) Encoder {
+	// Compressed files (i.e db.sql.gz) hold whatever the rest of the path points to
+	if gzipRegexp.MatchString(path) && !tarGzRegexp.MatchString(path) {
+		return NewGzipEncoder(GetEncoderForPath(strings.TrimSuffix(path, ".gz")))
+	}
+
 	for _, encoder := range encoders {
 		if encoder.regexp.MatchString(path) {
 			return  encoder.encoder
@@ -61,4 +163,24 @@ func GetEncoderForPath(path string) Encoder {
 	}
 
 	return defaultEncoder
-}
\ No newline at end of file
+}
+
+// Like GetEncoderForPath but paths without an extension (i.e "/admin/") are treated as pages
+func GetEncoderForUrlPath(urlPath string) Encoder {
+	if path.Ext(urlPath) == "" && !isKnownFilePath(urlPath) {
+		return NewHtmlEncoder()
+	}
+
+	return GetEncoderForPath(urlPath)
+}
+
+// Credential and key files such as ~/.aws/credentials or id_rsa have no extension but should not be served as pages
+func isKnownFilePath(path string) bool {
+	for _, encoder := range encoders {
+		if (encoder.generatorType == "credentials" || encoder.generatorType == "keys") && encoder.regexp.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -52,7 +52,8 @@ func (*HclEncoder) GetSupportedGenerator() string {
 }
 
 func mapUnknownHclBlocks(file *hclwrite.Body, value map[string]interface{}) {
-	for blockName, sectionValue := range value {
+	for _, blockName := range sortedKeys(value) {
+		sectionValue := value[blockName]
 		block := file.AppendNewBlock(blockName, make([]string, 0))
 
 		if data, ok := sectionValue.(map[string]interface{}); ok {
@@ -63,8 +64,8 @@ func mapUnknownHclBlocks(file *hclwrite.Body, value map[string]interface{}) {
 
 func mapUnknownValuesToHclBlock(block *hclwrite.Block, value map[string]interface{}) {
 	body := block.Body()
-	for key, value := range value {
-		bytes, err := json.Marshal(value)
+	for _, key := range sortedKeys(value) {
+		bytes, err := json.Marshal(value[key])
 
 		if err != nil {
 			continue
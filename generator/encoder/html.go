@@ -0,0 +1,69 @@
+package encoder
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+type (
+	HtmlEncoder struct{}
+
+	// A block of links making up part of a generated page
+	HtmlSection struct {
+		Title string
+		Links []HtmlLink
+	}
+
+	HtmlLink struct {
+		Href        string
+		Text        string
+		Description string
+	}
+)
+
+func NewHtmlEncoder() *HtmlEncoder {
+	return &HtmlEncoder{}
+}
+
+func (e *HtmlEncoder) Marshal(v interface{}) ([]byte, error) {
+	section, ok := v.(HtmlSection)
+	if !ok {
+		return nil, fmt.Errorf("html encoder can only marshal html sections")
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "<section>\n<h2>%s</h2>\n<ul>\n", html.EscapeString(section.Title))
+	for _, link := range section.Links {
+		fmt.Fprintf(&builder, "<li><a href=\"%s\">%s</a>", html.EscapeString(link.Href), html.EscapeString(link.Text))
+		if link.Description != "" {
+			fmt.Fprintf(&builder, " <small>%s</small>", html.EscapeString(link.Description))
+		}
+		builder.WriteString("</li>\n")
+	}
+	builder.WriteString("</ul>\n</section>")
+
+	return []byte(builder.String()), nil
+}
+
+func (*HtmlEncoder) Start() string {
+	return "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>Administration</title>\n" +
+		"<style>body{font-family:sans-serif;margin:2em}section{margin-bottom:1.5em}small{color:#666}</style>\n" +
+		"</head>\n<body>\n"
+}
+
+func (*HtmlEncoder) End() string {
+	return "\n</body>\n</html>\n"
+}
+
+func (*HtmlEncoder) Delimiter() string {
+	return "\n"
+}
+
+func (*HtmlEncoder) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+func (*HtmlEncoder) GetSupportedGenerator() string {
+	return "maze"
+}
@@ -52,7 +52,8 @@ func (e *IniEncoder) Marshal(v interface{}) ([]byte, error) {
 }
 
 func mapUnknownIniSections(file *ini.File, value map[string]interface{}) {
-	for sectionName, sectionValue := range value {
+	for _, sectionName := range sortedKeys(value) {
+		sectionValue := value[sectionName]
 		section, err := file.NewSection(sectionName)
 		if err != nil {
 			continue
@@ -65,8 +66,8 @@ func mapUnknownIniSections(file *ini.File, value map[string]interface{}) {
 }
 
 func mapUnknownValuesToIniSection(section *ini.Section, value map[string]interface{}) {
-	for key, value := range value {
-		bytes, err := json.Marshal(value)
+	for _, key := range sortedKeys(value) {
+		bytes, err := json.Marshal(value[key])
 
 		if err != nil {
 			continue
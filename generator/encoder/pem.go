@@ -0,0 +1,62 @@
+package encoder
+
+import (
+	"encoding/pem"
+	"fmt"
+)
+
+const (
+	KeyTypeOpenSshRsa     = "openssh-rsa"
+	KeyTypeOpenSshEd25519 = "openssh-ed25519"
+	KeyTypePrivateKey     = "private-key"
+	KeyTypeCertificate    = "certificate"
+	// Private keys followed by the certificate chain for them
+	KeyTypeBundle = "bundle"
+)
+
+type (
+	// Encodes PEM blocks (i.e private keys and certificates). The kind of key written is decided by the path
+	PemEncoder struct {
+		keyType string
+	}
+)
+
+func NewPemEncoder(keyType string) *PemEncoder {
+	return &PemEncoder{
+		keyType: keyType,
+	}
+}
+
+func (e *PemEncoder) Marshal(v interface{}) ([]byte, error) {
+	block, ok := v.(*pem.Block)
+	if !ok {
+		return nil, fmt.Errorf("pem encoder can only marshal pem blocks")
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// The kind of keys the generator should create blocks for
+func (e *PemEncoder) KeyType() string {
+	return e.keyType
+}
+
+func (*PemEncoder) Start() string {
+	return ""
+}
+
+func (*PemEncoder) End() string {
+	return ""
+}
+
+func (*PemEncoder) Delimiter() string {
+	return ""
+}
+
+func (*PemEncoder) ContentType() string {
+	return "application/x-pem-file"
+}
+
+func (*PemEncoder) GetSupportedGenerator() string {
+	return "keys"
+}
@@ -1,27 +1,248 @@
 package encoder
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/ryanolee/go-pot/generator/source"
 )
 
-type SqlEncoder struct{}
+const (
+	SqlDialectMysql    = "mysql"
+	SqlDialectPostgres = "postgres"
+)
+
+var (
+	mysqlStringEscaper = strings.NewReplacer("\\", "\\\\", "'", "\\'", "\"", "\\\"", "\n", "\\n", "\r", "\\r", "\x00", "\\0", "\x1a", "\\Z")
+
+	mysqlServerVersions    = []string{"8.0.36-0ubuntu0.22.04.1", "8.0.35", "8.0.33-0ubuntu0.20.04.2", "5.7.44-log", "10.11.6-MariaDB-0+deb12u1"}
+	postgresServerVersions = []string{"15.6 (Ubuntu 15.6-1.pgdg22.04+1)", "14.11 (Debian 14.11-1.pgdg120+2)", "16.2 (Debian 16.2-1.pgdg120+2)", "13.14"}
+
+	// Maps the MySQL style types of columns onto their PostgreSQL equivalent
+	postgresTypes = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		{regexp.MustCompile(`(?i)^tinyint(\(\d+\))?$`), "smallint"},
+		{regexp.MustCompile(`(?i)^int(eger)?(\(\d+\))?$`), "integer"},
+		{regexp.MustCompile(`(?i)^bigint(\(\d+\))?$`), "bigint"},
+		{regexp.MustCompile(`(?i)^varchar\((\d+)\)$`), "character varying($1)"},
+		{regexp.MustCompile(`(?i)^char\((\d+)\)$`), "character($1)"},
+		{regexp.MustCompile(`(?i)^decimal\((\d+),\s*(\d+)\)$`), "numeric($1,$2)"},
+		{regexp.MustCompile(`(?i)^datetime$`), "timestamp without time zone"},
+		{regexp.MustCompile(`(?i)^double$`), "double precision"},
+	}
+)
+
+type (
+	// Writes SQL dumps in the style of mysqldump or pg_dump. Rows are batched into multi row INSERT statements
+	SqlEncoder struct {
+		dialect string
+	}
+
+	// The server and database a dump claims to have been taken from
+	SqlDump struct {
+		Database      string
+		Host          string
+		ServerVersion string
+		Now           time.Time
+	}
+)
+
+func NewSqlEncoder(dialect string) *SqlEncoder {
+	return &SqlEncoder{
+		dialect: dialect,
+	}
+}
 
-func NewSqlEncoder() *SqlEncoder {
-	return &SqlEncoder{}
+func (e *SqlEncoder) Dialect() string {
+	return e.dialect
 }
 
+// Versions of the server dumps can claim to be from
+func (e *SqlEncoder) ServerVersions() []string {
+	if e.dialect == SqlDialectPostgres {
+		return postgresServerVersions
+	}
+
+	return mysqlServerVersions
+}
+
+// The header is written by StartDump
 func (*SqlEncoder) Start() string {
-	return "INSERT INTO `UserRecords` (`" + strings.Join(source.GetTabularHeaderFields(), "`, `") + "`) VALUES (\n"
+	return ""
+}
+
+func (e *SqlEncoder) StartDump(dump *SqlDump) string {
+	if e.dialect == SqlDialectPostgres {
+		return "--\n-- PostgreSQL database dump\n--\n\n" +
+			"-- Dumped from database version " + dump.ServerVersion + "\n" +
+			"-- Dumped by pg_dump version " + dump.ServerVersion + "\n\n" +
+			"SET statement_timeout = 0;\nSET lock_timeout = 0;\nSET idle_in_transaction_session_timeout = 0;\n" +
+			"SET client_encoding = 'UTF8';\nSET standard_conforming_strings = on;\n" +
+			"SELECT pg_catalog.set_config('search_path', '', false);\nSET check_function_bodies = false;\n" +
+			"SET xmloption = content;\nSET client_min_messages = warning;\nSET row_security = off;\n\n" +
+			"SET default_tablespace = '';\n\nSET default_table_access_method = heap;\n\n"
+	}
+
+	return "-- MySQL dump 10.13  Distrib " + strings.SplitN(dump.ServerVersion, "-", 2)[0] + ", for Linux (x86_64)\n--\n" +
+		"-- Host: " + dump.Host + "    Database: " + dump.Database + "\n" +
+		"-- ------------------------------------------------------\n" +
+		"-- Server version\t" + dump.ServerVersion + "\n\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_RESULTS=@@CHARACTER_SET_RESULTS */;\n" +
+		"/*!40101 SET @OLD_COLLATION_CONNECTION=@@COLLATION_CONNECTION */;\n" +
+		"/*!50503 SET NAMES utf8mb4 */;\n" +
+		"/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;\n" +
+		"/*!40103 SET TIME_ZONE='+00:00' */;\n" +
+		"/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n" +
+		"/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+		"/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;\n" +
+		"/*!40111 SET @OLD_SQL_NOTES=@@SQL_NOTES, SQL_NOTES=0 */;\n\n"
+}
+
+// Drops and creates the table in the same way as mysqldump or pg_dump before its data is dumped
+func (e *SqlEncoder) StartTable(schema *source.TabularSchema) string {
+	if e.dialect == SqlDialectPostgres {
+		return e.startPostgresTable(schema)
+	}
+
+	definitions := []string{}
+	primaryKeys := []string{}
+	for _, column := range schema.Columns {
+		if column.IsPrimaryKey() {
+			primaryKeys = append(primaryKeys, column.Name)
+			definitions = append(definitions, "  `"+column.Name+"` "+column.GetSqlType()+" NOT NULL AUTO_INCREMENT")
+			continue
+		}
+
+		definitions = append(definitions, "  `"+column.Name+"` "+column.GetSqlType()+" DEFAULT NULL")
+	}
+
+	if len(primaryKeys) > 0 {
+		definitions = append(definitions, "  PRIMARY KEY (`"+strings.Join(primaryKeys, "`,`")+"`)")
+	}
+
+	return "--\n-- Table structure for table `" + schema.Table + "`\n--\n\n" +
+		"DROP TABLE IF EXISTS `" + schema.Table + "`;\n" +
+		"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+		"/*!50503 SET character_set_client = utf8mb4 */;\n" +
+		"CREATE TABLE `" + schema.Table + "` (\n" + strings.Join(definitions, ",\n") + "\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;\n" +
+		"/*!40101 SET character_set_client = @saved_cs_client */;\n\n" +
+		"--\n-- Dumping data for table `" + schema.Table + "`\n--\n\n" +
+		"LOCK TABLES `" + schema.Table + "` WRITE;\n" +
+		"/*!40000 ALTER TABLE `" + schema.Table + "` DISABLE KEYS */;\n"
+}
+
+func (e *SqlEncoder) startPostgresTable(schema *source.TabularSchema) string {
+	definitions := []string{}
+	for _, column := range schema.Columns {
+		definition := "    " + postgresIdentifier(column.Name) + " " + postgresType(column.GetSqlType())
+		if column.IsPrimaryKey() {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+
+	table := "public." + postgresIdentifier(schema.Table)
+	return "--\n-- Name: " + schema.Table + "; Type: TABLE; Schema: public; Owner: -\n--\n\n" +
+		"DROP TABLE IF EXISTS " + table + ";\n" +
+		"CREATE TABLE " + table + " (\n" + strings.Join(definitions, ",\n") + "\n);\n\n\n" +
+		"--\n-- Data for Name: " + schema.Table + "; Type: TABLE DATA; Schema: public; Owner: -\n--\n\n"
+}
+
+// Starts a batch of rows
+func (e *SqlEncoder) StartInsert(schema *source.TabularSchema) string {
+	if e.dialect == SqlDialectPostgres {
+		return "INSERT INTO public." + postgresIdentifier(schema.Table) + " VALUES\n\t"
+	}
+
+	return "INSERT INTO `" + schema.Table + "` VALUES "
+}
+
+func (*SqlEncoder) EndInsert() string {
+	return ";\n"
+}
+
+func (e *SqlEncoder) EndTable(schema *source.TabularSchema) string {
+	if e.dialect == SqlDialectPostgres {
+		primaryKeys := []string{}
+		for _, column := range schema.Columns {
+			if column.IsPrimaryKey() {
+				primaryKeys = append(primaryKeys, postgresIdentifier(column.Name))
+			}
+		}
+
+		if len(primaryKeys) == 0 {
+			return "\n\n"
+		}
+
+		return "\n\n--\n-- Name: " + schema.Table + " " + schema.Table + "_pkey; Type: CONSTRAINT; Schema: public; Owner: -\n--\n\n" +
+			"ALTER TABLE ONLY public." + postgresIdentifier(schema.Table) + "\n" +
+			"    ADD CONSTRAINT " + postgresIdentifier(schema.Table+"_pkey") + " PRIMARY KEY (" + strings.Join(primaryKeys, ", ") + ");\n\n\n"
+	}
+
+	return "/*!40000 ALTER TABLE `" + schema.Table + "` ENABLE KEYS */;\nUNLOCK TABLES;\n\n"
+}
+
+// Only reached if the dump is ever finished
+func (e *SqlEncoder) EndDump(dump *SqlDump) string {
+	if e.dialect == SqlDialectPostgres {
+		return "--\n-- PostgreSQL database dump complete\n--\n\n"
+	}
+
+	return "/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n\n" +
+		"/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;\n" +
+		"/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n" +
+		"/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n" +
+		"/*!40101 SET CHARACTER_SET_CLIENT=@OLD_CHARACTER_SET_CLIENT */;\n" +
+		"/*!40101 SET CHARACTER_SET_RESULTS=@OLD_CHARACTER_SET_RESULTS */;\n" +
+		"/*!40101 SET COLLATION_CONNECTION=@OLD_COLLATION_CONNECTION */;\n" +
+		"/*!40111 SET SQL_NOTES=@OLD_SQL_NOTES */;\n\n" +
+		"-- Dump completed on " + dump.Now.Format(time.DateTime) + "\n"
+}
+
+// Writes a row of the table as a tuple of literals. Numeric columns are written without quotes
+func (e *SqlEncoder) MarshalRow(schema *source.TabularSchema, values []string) []byte {
+	literals := make([]string, len(values))
+	for i, value := range values {
+		numeric := i < len(schema.Columns) && schema.Columns[i].IsNumeric()
+		literals[i] = e.literal(value, numeric)
+	}
+
+	if e.dialect == SqlDialectPostgres {
+		return []byte("(" + strings.Join(literals, ", ") + ")")
+	}
+
+	return []byte("(" + strings.Join(literals, ",") + ")")
+}
+
+// Quotes and escapes a value for the dialect
+func (e *SqlEncoder) literal(value string, numeric bool) string {
+	if numeric && value != "" {
+		return value
+	}
+
+	// pg_dump relies on standard_conforming_strings so only quotes need escaping
+	if e.dialect == SqlDialectPostgres {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+
+	return "'" + mysqlStringEscaper.Replace(value) + "'"
 }
 
 func (*SqlEncoder) End() string {
-	return ")"
+	return ""
 }
 
-func (*SqlEncoder) Delimiter() string {
-	return ",\n"
+func (e *SqlEncoder) Delimiter() string {
+	if e.dialect == SqlDialectPostgres {
+		return ",\n\t"
+	}
+
+	return ","
 }
 
 func (*SqlEncoder) ContentType() string {
@@ -29,9 +250,41 @@ func (*SqlEncoder) ContentType() string {
 }
 
 func (*SqlEncoder) GetSupportedGenerator() string {
-	return "tabular"
+	return "dump"
 }
 
+// Rows without a schema are written with every value quoted
 func (e *SqlEncoder) Marshal(v interface{}) ([]byte, error) {
-	return []byte("(`" + strings.Join(source.GetTabularFieldValues(), "`, `") + "`)"), nil
+	values, ok := v.([]string)
+	if !ok {
+		return nil, fmt.Errorf("sql encoder can only marshal rows of values")
+	}
+
+	return e.MarshalRow(&source.TabularSchema{}, values), nil
+}
+
+// pg_dump only quotes identifiers that would otherwise be folded or read as keywords
+func postgresIdentifier(name string) string {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_') {
+			return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+		}
+	}
+
+	switch name {
+	case "user", "order", "group", "table", "select", "from", "where", "limit", "offset", "default", "primary":
+		return "\"" + name + "\""
+	}
+
+	return name
+}
+
+func postgresType(sqlType string) string {
+	for _, postgresType := range postgresTypes {
+		if postgresType.pattern.MatchString(sqlType) {
+			return postgresType.pattern.ReplaceAllString(sqlType, postgresType.replacement)
+		}
+	}
+
+	return sqlType
 }
@@ -34,7 +34,8 @@ func NewUnknownMap(data interface{}) UnknownMap {
 func (s UnknownMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	tokens := []xml.Token{start}
 
-	for key, value := range s {
+	for _, key := range sortedKeys(s) {
+		value := s[key]
 		t := xml.StartElement{Name: xml.Name{
 			Space: "",
 			Local: key,
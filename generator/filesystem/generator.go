@@ -18,6 +18,9 @@ var fileExtensions = []string{
 	"ini",
 	"hcl",
 	"tfvars",
+	"zip",
+	"tar.gz",
+	"sql.gz",
 }
 
 const (
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
 	"github.com/ryanolee/go-pot/secrets"
 )
 
@@ -13,12 +14,44 @@ type Generator interface {
 	End() []byte
 }
 
-func GetGeneratorForEncoder(encoder encoder.Encoder, configGenerators *ConfigGeneratorCollection, secretsGenerators *secrets.SecretGeneratorCollection) Generator {
+// Generators with a fixed amount of content. Stallers stop once Done returns true
+type FiniteGenerator interface {
+	Generator
+	Done() bool
+}
+
+// Generators writing binary data. Their output must not be padded out with whitespace
+type BinaryGenerator interface {
+	Generator
+	IsBinary() bool
+}
+
+func GetGeneratorForEncoder(encoder encoder.Encoder, configGenerators *ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretsGenerators *secrets.SecretGeneratorCollection, stream *StreamSource) Generator {
+	return GetGeneratorForPath("/", encoder, configGenerators, tabularSchemas, secretsGenerators, stream)
+}
+
+// Like GetGeneratorForEncoder but generators that can use the requested path (i.e to pick a schema, table or seed a page) are given it.
+// Secrets are weighted using the profile matching the path. Everything random is drawn from the stream
+func GetGeneratorForPath(path string, encoder encoder.Encoder, configGenerators *ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretsGenerators *secrets.SecretGeneratorCollection, stream *StreamSource) Generator {
+	secretsGenerators = secretsGenerators.ForPath(path).WithRand(stream.Rand)
+
 	switch encoder.GetSupportedGenerator() {
 	case "config":
-		return NewConfigGenerator(encoder, configGenerators, secretsGenerators)
+		return NewConfigGenerator(encoder, configGenerators, secretsGenerators, path, stream)
 	case "tabular":
-		return NewTabularGenerator(encoder)
+		return NewTabularGenerator(encoder, tabularSchemas.ForPath(path), secretsGenerators, stream)
+	case "dump":
+		return NewDumpGenerator(encoder, tabularSchemas, path, secretsGenerators, stream)
+	case "dotenv":
+		return NewDotenvGenerator(encoder, configGenerators, secretsGenerators, path, stream)
+	case "credentials":
+		return NewCredentialsGenerator(encoder, secretsGenerators, stream)
+	case "keys":
+		return NewKeyGenerator(encoder, stream)
+	case "archive":
+		return NewArchiveGenerator(encoder, configGenerators, tabularSchemas, secretsGenerators, stream)
+	case "maze":
+		return NewMazeGenerator(encoder, path)
 	default:
 		return nil
 	}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	objectBlob   = "blob"
+	objectTree   = "tree"
+	objectCommit = "commit"
+
+	modeFile = "100644"
+	modeTree = "40000"
+)
+
+type (
+	// A single object in the object database
+	object struct {
+		hash       [20]byte
+		compressed []byte
+	}
+
+	// An entry in a tree object
+	treeEntry struct {
+		mode string
+		name string
+		hash [20]byte
+	}
+
+	// A file in the working tree as recorded in the index
+	indexEntry struct {
+		path string
+		hash [20]byte
+		size int
+	}
+
+	signature struct {
+		name  string
+		email string
+		when  time.Time
+	}
+)
+
+// Encodes content as a zlib compressed loose object. The hash is over the uncompressed object
+func newObject(objectType string, content []byte) (*object, error) {
+	raw := append([]byte(fmt.Sprintf("%s %d\x00", objectType, len(content))), content...)
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	if _, err := writer.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return &object{
+		hash:       sha1.Sum(raw),
+		compressed: compressed.Bytes(),
+	}, nil
+}
+
+func (o *object) hex() string {
+	return hex.EncodeToString(o.hash[:])
+}
+
+// Path of the loose object relative to the .git directory
+func (o *object) path() string {
+	hash := o.hex()
+	return fmt.Sprintf("objects/%s/%s", hash[:2], hash[2:])
+}
+
+// Encodes the content of a tree. Entries are sorted the way git sorts them (Trees compare as if they end in "/")
+func encodeTree(entries []treeEntry) []byte {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].sortKey() < entries[j].sortKey()
+	})
+
+	var content bytes.Buffer
+	for _, entry := range entries {
+		fmt.Fprintf(&content, "%s %s\x00", entry.mode, entry.name)
+		content.Write(entry.hash[:])
+	}
+
+	return content.Bytes()
+}
+
+func (e treeEntry) sortKey() string {
+	if e.mode == modeTree {
+		return e.name + "/"
+	}
+
+	return e.name
+}
+
+func encodeCommit(tree string, parent string, author signature, message string) []byte {
+	var content strings.Builder
+	fmt.Fprintf(&content, "tree %s\n", tree)
+	if parent != "" {
+		fmt.Fprintf(&content, "parent %s\n", parent)
+	}
+	fmt.Fprintf(&content, "author %s\n", author)
+	fmt.Fprintf(&content, "committer %s\n", author)
+	fmt.Fprintf(&content, "\n%s\n", message)
+
+	return []byte(content.String())
+}
+
+func (s signature) String() string {
+	return fmt.Sprintf("%s <%s> %d +0000", s.name, s.email, s.when.Unix())
+}
+
+// Encodes a version 2 index file for the given entries
+func encodeIndex(entries []indexEntry, modified time.Time) []byte {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].path < entries[j].path
+	})
+
+	var index bytes.Buffer
+	index.WriteString("DIRC")
+	index.Write(binary.BigEndian.AppendUint32(nil, 2))
+	index.Write(binary.BigEndian.AppendUint32(nil, uint32(len(entries))))
+
+	for i, entry := range entries {
+		start := index.Len()
+		seconds := uint32(modified.Unix())
+
+		// ctime, mtime, dev, ino, mode, uid, gid and size
+		for _, value := range []uint32{seconds, 0, seconds, 0, 2049, uint32(1000 + i), 0o100644, 1000, 1000, uint32(entry.size)} {
+			index.Write(binary.BigEndian.AppendUint32(nil, value))
+		}
+
+		index.Write(entry.hash[:])
+		index.Write(binary.BigEndian.AppendUint16(nil, uint16(min(len(entry.path), 0xfff))))
+		index.WriteString(entry.path)
+
+		// Entries are padded with 1-8 null bytes to a multiple of 8 bytes
+		padding := 8 - (index.Len()-start)%8
+		index.Write(make([]byte, padding))
+	}
+
+	checksum := sha1.Sum(index.Bytes())
+	index.Write(checksum[:])
+
+	return index.Bytes()
+}
@@ -0,0 +1,257 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+)
+
+// Files making up the working tree of the fake repository. Content is generated by the encoder matching each path
+var workingTreeFiles = []string{
+	"config/database.yml",
+	"config/settings.json",
+	"config/app.ini",
+	"config/credentials.xml",
+	"deploy/main.tf",
+	"deploy/production.tfvars",
+	"deploy/docker-compose.yml",
+	"db/seed.sql",
+	"db/customers.csv",
+}
+
+var (
+	repositoryNames = []string{"platform", "backend", "billing-api", "infra", "internal-tools", "payments", "website", "auth-service"}
+	orgNames        = []string{"acme-corp", "example-inc", "globex", "initech", "umbrella-it", "hooli-eng"}
+	authorNames     = []string{"Alice Johnson", "Bob Smith", "Carol Williams", "Dave Brown", "Erin Davis", "deploy-bot"}
+)
+
+const (
+	// Number of chunks each generated file is made up of
+	chunksPerFile = 3
+
+	defaultBranch = "main"
+)
+
+type (
+	// A fake but internally consistent git repository as exposed by a web server serving a .git directory.
+	// The repository is generated once so every request sees the same history
+	Repository struct {
+		files map[string][]byte
+	}
+
+	// A directory in the working tree being built up into tree objects
+	treeNode struct {
+		files map[string][20]byte
+		dirs  map[string]*treeNode
+		hash  [20]byte
+	}
+)
+
+func NewRepository(configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretGenerators *secrets.SecretGeneratorCollection) (*Repository, error) {
+	repository := &Repository{
+		files: map[string][]byte{},
+	}
+
+	if err := repository.build(configGenerators, tabularSchemas, secretGenerators, rand.NewSeededRandFromTime()); err != nil {
+		return nil, err
+	}
+
+	return repository, nil
+}
+
+// Gets a file from the .git directory given its path relative to the .git directory
+func (r *Repository) Get(name string) ([]byte, bool) {
+	content, ok := r.files[strings.TrimPrefix(path.Clean("/"+name), "/")]
+	return content, ok
+}
+
+func (r *Repository) build(configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretGenerators *secrets.SecretGeneratorCollection, random *rand.SeededRand) error {
+	name := random.StringChoice(&repositoryNames)
+	org := random.StringChoice(&orgNames)
+	authorName := random.StringChoice(&authorNames)
+	author := signature{
+		name:  authorName,
+		email: strings.ReplaceAll(strings.ToLower(authorName), " ", ".") + "@" + org + ".com",
+		when:  time.Now().Add(-time.Duration(random.RandomInt(24*30, 24*365)) * time.Hour),
+	}
+
+	// The first commit only has the readme. The second adds everything else
+	readme := []byte(fmt.Sprintf("# %s\n\nInternal %s service. See `config/` for environment settings.\n", name, name))
+	root := newTreeNode()
+	index := []indexEntry{}
+
+	readmeHash, err := r.addObject(objectBlob, readme)
+	if err != nil {
+		return err
+	}
+	root.add("README.md", readmeHash)
+	index = append(index, indexEntry{path: "README.md", hash: readmeHash, size: len(readme)})
+
+	initialTree, err := r.writeTree(root)
+	if err != nil {
+		return err
+	}
+
+	initialCommit, err := r.addObject(objectCommit, encodeCommit(initialTree, "", author, "Initial commit"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range workingTreeFiles {
+		content := generateFile(file, configGenerators, tabularSchemas, secretGenerators)
+		hash, err := r.addObject(objectBlob, content)
+		if err != nil {
+			return err
+		}
+
+		root.add(file, hash)
+		index = append(index, indexEntry{path: file, hash: hash, size: len(content)})
+	}
+
+	tree, err := r.writeTree(root)
+	if err != nil {
+		return err
+	}
+
+	author.when = author.when.Add(time.Duration(random.RandomInt(1, 72)) * time.Hour)
+	head, err := r.addObject(objectCommit, encodeCommit(tree, hexHash(initialCommit), author, "Add production configuration"))
+	if err != nil {
+		return err
+	}
+
+	r.files["HEAD"] = []byte("ref: refs/heads/" + defaultBranch + "\n")
+	r.files["ORIG_HEAD"] = []byte(hexHash(initialCommit) + "\n")
+	r.files["refs/heads/"+defaultBranch] = []byte(hexHash(head) + "\n")
+	r.files["refs/remotes/origin/HEAD"] = []byte("ref: refs/remotes/origin/" + defaultBranch + "\n")
+	r.files["refs/remotes/origin/"+defaultBranch] = []byte(hexHash(head) + "\n")
+	r.files["packed-refs"] = []byte(fmt.Sprintf("# pack-refs with: peeled fully-peeled sorted \n%s refs/remotes/origin/%s\n", hexHash(head), defaultBranch))
+	r.files["config"] = []byte(gitConfig(name, org, author, secretGenerators))
+	r.files["description"] = []byte("Unnamed repository; edit this file 'description' to name the repository.\n")
+	r.files["info/exclude"] = []byte("# git ls-files --others --exclude-from=.git/info/exclude\n# Lines that start with '#' are comments.\n")
+	r.files["COMMIT_EDITMSG"] = []byte("Add production configuration\n")
+	r.files["index"] = encodeIndex(index, author.when)
+	r.files["objects/info/packs"] = []byte("\n")
+
+	reflog := fmt.Sprintf("%s %s %s\tcommit (initial): Initial commit\n%s %s %s\tcommit: Add production configuration\n",
+		strings.Repeat("0", 40), hexHash(initialCommit), signature{author.name, author.email, author.when.Add(-time.Hour)},
+		hexHash(initialCommit), hexHash(head), author,
+	)
+	r.files["logs/HEAD"] = []byte(reflog)
+	r.files["logs/refs/heads/"+defaultBranch] = []byte(reflog)
+
+	return nil
+}
+
+func (r *Repository) addObject(objectType string, content []byte) ([20]byte, error) {
+	obj, err := newObject(objectType, content)
+	if err != nil {
+		return [20]byte{}, err
+	}
+
+	r.files[obj.path()] = obj.compressed
+	return obj.hash, nil
+}
+
+// Writes tree objects for the node and every directory below it returning the hash of the node
+func (r *Repository) writeTree(node *treeNode) (string, error) {
+	entries := []treeEntry{}
+	for name, hash := range node.files {
+		entries = append(entries, treeEntry{mode: modeFile, name: name, hash: hash})
+	}
+
+	for name, dir := range node.dirs {
+		if _, err := r.writeTree(dir); err != nil {
+			return "", err
+		}
+		entries = append(entries, treeEntry{mode: modeTree, name: name, hash: dir.hash})
+	}
+
+	hash, err := r.addObject(objectTree, encodeTree(entries))
+	if err != nil {
+		return "", err
+	}
+
+	node.hash = hash
+	return hexHash(hash), nil
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{
+		files: map[string][20]byte{},
+		dirs:  map[string]*treeNode{},
+	}
+}
+
+func (n *treeNode) add(filePath string, hash [20]byte) {
+	dir, file := path.Split(filePath)
+	node := n
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" {
+			continue
+		}
+
+		if _, ok := node.dirs[part]; !ok {
+			node.dirs[part] = newTreeNode()
+		}
+		node = node.dirs[part]
+	}
+
+	node.files[file] = hash
+}
+
+// Generates the content of a file in the working tree using the encoder matching its path
+func generateFile(name string, configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretGenerators *secrets.SecretGeneratorCollection) []byte {
+	gen := generator.GetGeneratorForPath(name, encoder.GetEncoderForPath(name), configGenerators, tabularSchemas, secretGenerators, generator.NewStreamSource())
+
+	var content bytes.Buffer
+	content.Write(gen.Start())
+	for i := 0; i < chunksPerFile; i++ {
+		if i > 0 {
+			content.Write(gen.ChunkSeparator())
+		}
+		content.Write(gen.GenerateChunk())
+	}
+	content.Write(gen.End())
+	content.WriteString("\n")
+
+	return content.Bytes()
+}
+
+// The repository config includes a remote with credentials embedded in the url
+func gitConfig(name string, org string, author signature, secretGenerators *secrets.SecretGeneratorCollection) string {
+	token := "ghp_" + strings.Repeat("x", 36)
+	if gen := secretGenerators.GetGeneratorByName("github-pat"); gen != nil {
+		token = gen.SecretGenerator.Generate()
+	}
+
+	user := strings.SplitN(author.email, "@", 2)[0]
+	return strings.Join([]string{
+		"[core]",
+		"\trepositoryformatversion = 0",
+		"\tfilemode = true",
+		"\tbare = false",
+		"\tlogallrefupdates = true",
+		"[remote \"origin\"]",
+		fmt.Sprintf("\turl = https://%s:%s@github.com/%s/%s.git", user, token, org, name),
+		"\tfetch = +refs/heads/*:refs/remotes/origin/*",
+		fmt.Sprintf("[branch \"%s\"]", defaultBranch),
+		"\tremote = origin",
+		"\tmerge = refs/heads/" + defaultBranch,
+		"[user]",
+		"\tname = " + author.name,
+		"\temail = " + author.email,
+		"",
+	}, "\n")
+}
+
+func hexHash(hash [20]byte) string {
+	return fmt.Sprintf("%x", hash)
+}
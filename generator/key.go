@@ -0,0 +1,295 @@
+package generator
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/rand"
+	"go.uber.org/zap"
+)
+
+var (
+	keyHosts = []string{"bastion", "web01", "web02", "db-primary", "jenkins", "vpn", "build-agent", "prod-k8s-master", "backup"}
+	keyUsers = []string{"root", "ubuntu", "ec2-user", "deploy", "admin", "git", "jenkins", "ansible"}
+
+	keyCertOrganizations = []string{"Acme Corp", "Example Inc", "Globex Corporation", "Initech", "Umbrella IT", "Hooli"}
+	keyCertDomains       = []string{"acme-corp.internal", "example-inc.com", "globex.io", "initech.local", "umbrella-it.net", "hooli.dev"}
+
+	oidRsaEncryption  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+)
+
+const (
+	// Size in bytes of generated RSA moduli
+	rsaKeySize = 256
+)
+
+type (
+	// Generates PEM and OpenSSH armored keys and certificates. Keys are structurally valid so they parse
+	// as ASN.1 (or the OpenSSH wire format) but the numbers in them are random so they can never be used
+	KeyGenerator struct {
+		encoder encoder.Encoder
+		rand    *rand.SeededRand
+		now     time.Time
+		keyType string
+		index   int
+	}
+
+	pkcs1PrivateKey struct {
+		Version int
+		N       *big.Int
+		E       int
+		D       *big.Int
+		P       *big.Int
+		Q       *big.Int
+		Dp      *big.Int
+		Dq      *big.Int
+		Qinv    *big.Int
+	}
+
+	pkcs8PrivateKey struct {
+		Version    int
+		Algorithm  pkix.AlgorithmIdentifier
+		PrivateKey []byte
+	}
+
+	ecPrivateKey struct {
+		Version       int
+		PrivateKey    []byte
+		NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+		PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+	}
+)
+
+func NewKeyGenerator(enc encoder.Encoder, stream *StreamSource) *KeyGenerator {
+	keyType := encoder.KeyTypePrivateKey
+	if pemEncoder, ok := enc.(*encoder.PemEncoder); ok {
+		keyType = pemEncoder.KeyType()
+	}
+
+	return &KeyGenerator{
+		encoder: enc,
+		rand:    stream.Rand,
+		now:     stream.Now,
+		keyType: keyType,
+	}
+}
+
+func (g *KeyGenerator) Generate() []byte {
+	block, err := g.generateBlock()
+	if err != nil {
+		zap.L().Sugar().Error(err)
+		return nil
+	}
+	g.index++
+
+	data, err := g.encoder.Marshal(block)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (g *KeyGenerator) Start() []byte {
+	return []byte(g.encoder.Start())
+}
+
+func (g *KeyGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+func (g *KeyGenerator) ChunkSeparator() []byte {
+	return []byte(g.encoder.Delimiter())
+}
+
+func (g *KeyGenerator) End() []byte {
+	return []byte(g.encoder.End())
+}
+
+func (g *KeyGenerator) generateBlock() (*pem.Block, error) {
+	switch g.keyType {
+	case encoder.KeyTypeOpenSshRsa:
+		return g.openSshRsaKey(), nil
+	case encoder.KeyTypeOpenSshEd25519:
+		return g.openSshEd25519Key(), nil
+	case encoder.KeyTypeCertificate:
+		return g.certificate()
+	case encoder.KeyTypeBundle:
+		// The key comes first followed by its certificate chain
+		if g.index == 0 {
+			return g.privateKey()
+		}
+		return g.certificate()
+	default:
+		return g.privateKey()
+	}
+}
+
+// Picks between the formats private keys are usually found in
+func (g *KeyGenerator) privateKey() (*pem.Block, error) {
+	switch g.rand.RandomInt(0, 3) {
+	case 0:
+		der, err := asn1.Marshal(g.pkcs1Key())
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: der}, err
+	case 1:
+		der, err := asn1.Marshal(ecPrivateKey{
+			Version:       1,
+			PrivateKey:    g.randomBytes(32),
+			NamedCurveOID: oidNamedCurveP256,
+			PublicKey:     g.ecPublicKey(),
+		})
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, err
+	default:
+		rsaKey, err := asn1.Marshal(g.pkcs1Key())
+		if err != nil {
+			return nil, err
+		}
+
+		der, err := asn1.Marshal(pkcs8PrivateKey{
+			Algorithm:  pkix.AlgorithmIdentifier{Algorithm: oidRsaEncryption, Parameters: asn1.NullRawValue},
+			PrivateKey: rsaKey,
+		})
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, err
+	}
+}
+
+// A certificate for a made up internal host. Certificates are public so these are signed properly with a throwaway key.
+// Ed25519 keys and signatures are derived from the generator source alone so the certificate can be generated again
+func (g *KeyGenerator) certificate() (*pem.Block, error) {
+	key := ed25519.NewKeyFromSeed(g.randomBytes(ed25519.SeedSize))
+
+	host := g.rand.StringChoice(&keyHosts) + "." + g.rand.StringChoice(&keyCertDomains)
+	notBefore := g.now.AddDate(0, 0, -g.rand.RandomInt(1, 365))
+	template := &x509.Certificate{
+		SerialNumber: new(big.Int).SetBytes(g.randomBytes(16)),
+		Subject: pkix.Name{
+			CommonName:   host,
+			Organization: []string{g.rand.StringChoice(&keyCertOrganizations)},
+		},
+		DNSNames:    []string{host},
+		NotBefore:   notBefore,
+		NotAfter:    notBefore.AddDate(1, 0, 0),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(g.rand.Rand, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pem.Block{Type: "CERTIFICATE", Bytes: der}, nil
+}
+
+func (g *KeyGenerator) openSshRsaKey() *pem.Block {
+	key := g.pkcs1Key()
+	e := big.NewInt(int64(key.E))
+
+	public := sshString(nil, []byte("ssh-rsa"))
+	public = sshMpint(public, e)
+	public = sshMpint(public, key.N)
+
+	private := sshString(nil, []byte("ssh-rsa"))
+	for _, value := range []*big.Int{key.N, e, key.D, key.Qinv, key.P, key.Q} {
+		private = sshMpint(private, value)
+	}
+
+	return g.openSshKey(public, private)
+}
+
+func (g *KeyGenerator) openSshEd25519Key() *pem.Block {
+	publicKey := g.randomBytes(32)
+
+	public := sshString(nil, []byte("ssh-ed25519"))
+	public = sshString(public, publicKey)
+
+	private := sshString(nil, []byte("ssh-ed25519"))
+	private = sshString(private, publicKey)
+	private = sshString(private, append(g.randomBytes(32), publicKey...))
+
+	return g.openSshKey(public, private)
+}
+
+// Wraps a key in the unencrypted openssh-key-v1 format
+func (g *KeyGenerator) openSshKey(public []byte, private []byte) *pem.Block {
+	check := g.rand.Rand.Uint32()
+	section := binary.BigEndian.AppendUint32(nil, check)
+	section = binary.BigEndian.AppendUint32(section, check)
+	section = append(section, private...)
+	section = sshString(section, []byte(fmt.Sprintf("%s@%s", g.rand.StringChoice(&keyUsers), g.rand.StringChoice(&keyHosts))))
+
+	// The private section is padded with 1, 2, 3... up to the cipher block size (8 for "none")
+	for i := byte(1); len(section)%8 != 0; i++ {
+		section = append(section, i)
+	}
+
+	data := []byte("openssh-key-v1\x00")
+	data = sshString(data, []byte("none"))
+	data = sshString(data, []byte("none"))
+	data = sshString(data, nil)
+	data = binary.BigEndian.AppendUint32(data, 1)
+	data = sshString(data, public)
+	data = sshString(data, section)
+
+	return &pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: data}
+}
+
+// An RSA key with random numbers of the right sizes in place of real primes
+func (g *KeyGenerator) pkcs1Key() pkcs1PrivateKey {
+	return pkcs1PrivateKey{
+		N:    g.randomInt(rsaKeySize),
+		E:    65537,
+		D:    g.randomInt(rsaKeySize),
+		P:    g.randomInt(rsaKeySize / 2),
+		Q:    g.randomInt(rsaKeySize / 2),
+		Dp:   g.randomInt(rsaKeySize / 2),
+		Dq:   g.randomInt(rsaKeySize / 2),
+		Qinv: g.randomInt(rsaKeySize / 2),
+	}
+}
+
+// An uncompressed curve point made up of random coordinates
+func (g *KeyGenerator) ecPublicKey() asn1.BitString {
+	point := append([]byte{0x04}, g.randomBytes(64)...)
+	return asn1.BitString{Bytes: point, BitLength: len(point) * 8}
+}
+
+// A random odd number with the top bit set so it has exactly the given number of bytes
+func (g *KeyGenerator) randomInt(size int) *big.Int {
+	data := g.randomBytes(size)
+	data[0] |= 0x80
+	data[size-1] |= 0x01
+
+	return new(big.Int).SetBytes(data)
+}
+
+func (g *KeyGenerator) randomBytes(size int) []byte {
+	data := make([]byte, size)
+	g.rand.Rand.Read(data)
+
+	return data
+}
+
+func sshString(data []byte, value []byte) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(value)))
+	return append(data, value...)
+}
+
+// Positive numbers get a leading zero byte when their top bit is set so they are not read as negative
+func sshMpint(data []byte, value *big.Int) []byte {
+	bytes := value.Bytes()
+	if len(bytes) > 0 && bytes[0]&0x80 != 0 {
+		bytes = append([]byte{0}, bytes...)
+	}
+
+	return sshString(data, bytes)
+}
@@ -0,0 +1,151 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/rand"
+)
+
+var (
+	mazeAdminPanels = []string{
+		"admin/", "administrator/", "wp-admin/", "phpmyadmin/", "manager/html", "cpanel/",
+		"dashboard/", "console/", "portal/", "login.php", "admin.php", "setup.php", "install.php",
+	}
+
+	mazeWords = []string{
+		"admin", "backup", "config", "db", "prod", "production", "staging", "internal", "private",
+		"secret", "legacy", "old", "api", "export", "dump", "users", "customers", "billing",
+		"payroll", "keys", "credentials", "finance", "hr", "deploy", "infra", "vault", "storage",
+	}
+
+	mazeSectionTitles = []string{
+		"Administration", "Backups", "Configuration", "Database Exports", "Deployments",
+		"Internal Tools", "Reports", "Archive", "Restricted", "Recent Files",
+	}
+
+	// Extensions that map onto one of the other encoders
+	mazeDataExtensions = []string{
+		"sql", "csv", "json", "yml", "yaml", "xml", "toml", "ini", "tf", "tfvars", "hcl", "env",
+	}
+
+	mazeDescriptions = []string{
+		"last modified by root", "do not share", "nightly", "contains credentials",
+		"old - remove", "restricted", "read only", "auto generated",
+	}
+)
+
+const (
+	minMazeLinks = 4
+	maxMazeLinks = 12
+)
+
+type (
+	// Generates endless HTML pages full of links to more generated paths. Pages are seeded from
+	// the request path so the same path always links to the same places
+	MazeGenerator struct {
+		encoder encoder.Encoder
+		rand    *rand.SeededRand
+		base    string
+	}
+)
+
+func NewMazeGenerator(encoder encoder.Encoder, requestPath string) *MazeGenerator {
+	// Links are made relative to the directory the page is in. Paths without an extension are treated as directories
+	base := requestPath
+	if path.Ext(requestPath) != "" {
+		base = path.Dir(requestPath)
+	}
+
+	return &MazeGenerator{
+		encoder: encoder,
+		rand:    rand.NewSeededRandFromString(requestPath),
+		base:    path.Join("/", base),
+	}
+}
+
+func (g *MazeGenerator) Generate() []byte {
+	section := encoder.HtmlSection{
+		Title: g.rand.StringChoice(&mazeSectionTitles),
+		Links: make([]encoder.HtmlLink, 0, maxMazeLinks),
+	}
+
+	for i := g.rand.RandomInt(minMazeLinks, maxMazeLinks); i > 0; i-- {
+		section.Links = append(section.Links, g.generateLink())
+	}
+
+	data, err := g.encoder.Marshal(section)
+	if err != nil {
+		return nil
+	}
+
+	return data
+}
+
+func (g *MazeGenerator) Start() []byte {
+	header, err := g.encoder.Marshal(encoder.HtmlSection{
+		Title: "Index of " + g.base,
+		Links: []encoder.HtmlLink{
+			{Href: path.Dir(g.base), Text: "Parent Directory"},
+			{Href: "/" + g.rand.StringChoice(&mazeAdminPanels), Text: "Admin Panel"},
+		},
+	})
+	if err != nil {
+		return []byte(g.encoder.Start())
+	}
+
+	return append([]byte(g.encoder.Start()), header...)
+}
+
+func (g *MazeGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+func (g *MazeGenerator) ChunkSeparator() []byte {
+	return []byte(g.encoder.Delimiter())
+}
+
+func (g *MazeGenerator) End() []byte {
+	return []byte(g.encoder.End())
+}
+
+// Generates a link to an admin panel, a directory, another page or a data file served by another encoder
+func (g *MazeGenerator) generateLink() encoder.HtmlLink {
+	name := g.generateName()
+	var target string
+
+	switch g.rand.RandomInt(0, 5) {
+	case 0:
+		target = path.Join(g.base, g.rand.StringChoice(&mazeAdminPanels))
+	case 1:
+		target = path.Join(g.base, name) + "/"
+	case 2:
+		target = path.Join(g.base, name+".php")
+	case 3:
+		target = path.Join(g.base, fmt.Sprintf("%s-%d-%02d-%02d.%s", name, g.rand.RandomInt(2019, 2025), g.rand.RandomInt(1, 13), g.rand.RandomInt(1, 29), g.rand.StringChoice(&mazeDataExtensions)))
+	default:
+		target = path.Join(g.base, name+"."+g.rand.StringChoice(&mazeDataExtensions))
+	}
+
+	link := encoder.HtmlLink{
+		Href: target,
+		Text: strings.TrimSuffix(path.Base(target), "/"),
+	}
+
+	if g.rand.RandomInt(0, 3) == 0 {
+		link.Description = g.rand.StringChoice(&mazeDescriptions)
+	}
+
+	return link
+}
+
+func (g *MazeGenerator) generateName() string {
+	name := g.rand.StringChoice(&mazeWords)
+	if g.rand.RandomInt(0, 2) == 0 {
+		name += "_" + g.rand.StringChoice(&mazeWords)
+	}
+
+	return name
+}
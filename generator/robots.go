@@ -4,6 +4,7 @@ import (
 	"embed"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/ryanolee/go-pot/rand"
 	"github.com/thoas/go-funk"
@@ -12,40 +13,71 @@ import (
 var (
 	//go:embed data/robots.wordlist.txt
 	rulesFile embed.FS
+
+	// Entries from the robots.txt wordlist shared between every generator
+	robotEntries     []string
+	robotEntriesErr  error
+	robotEntriesOnce sync.Once
 )
 
 const robotsTxtFile = "data/robots.wordlist.txt"
 
+// Generates an endless robots.txt full of paths that look worth visiting
 type RobotsTxtGenerator struct {
 	rand         *rand.SeededRand
 	robotEntries *[]string
 }
 
 func NewRobotsTxtGenerator(rand *rand.SeededRand) (*RobotsTxtGenerator, error) {
-	rulesFile, err := rulesFile.ReadFile(robotsTxtFile)
+	entries, err := loadRobotEntries()
 	if err != nil {
 		return nil, err
 	}
 
-	robotEntries := strings.Split(string(rulesFile), "\n")
-
 	return &RobotsTxtGenerator{
 		rand:         rand,
-		robotEntries: &robotEntries,
+		robotEntries: &entries,
 	}, nil
 }
 
-func (g *RobotsTxtGenerator) Generate() string {
+func loadRobotEntries() ([]string, error) {
+	robotEntriesOnce.Do(func() {
+		rulesFile, err := rulesFile.ReadFile(robotsTxtFile)
+		if err != nil {
+			robotEntriesErr = err
+			return
+		}
+
+		robotEntries = funk.FilterString(strings.Split(string(rulesFile), "\n"), func(entry string) bool {
+			return strings.TrimSpace(entry) != ""
+		})
+	})
+
+	return robotEntries, robotEntriesErr
+}
+
+func (g *RobotsTxtGenerator) Generate() []byte {
 	entries := g.rand.StringChoiceMultiple(g.robotEntries, g.rand.RandomInt(1, 10))
-	entries = append([]string{"/"}, entries...)
 	entries = funk.Map(entries, g.formatEntry).([]string)
-	return strings.Join(entries, "")
+	return append(g.Start(), []byte(strings.Join(entries, ""))...)
+}
+
+func (g *RobotsTxtGenerator) Start() []byte {
+	return []byte("User-agent: *\n")
+}
+
+func (g *RobotsTxtGenerator) GenerateChunk() []byte {
+	return []byte(g.formatEntry(g.rand.StringChoice(g.robotEntries)))
+}
+
+func (g *RobotsTxtGenerator) ChunkSeparator() []byte {
+	return []byte{}
 }
 
-func (g *RobotsTxtGenerator) GenerateChunk() string {
-	return g.formatEntry(g.rand.StringChoice(g.robotEntries))
+func (g *RobotsTxtGenerator) End() []byte {
+	return []byte{}
 }
 
 func (g *RobotsTxtGenerator) formatEntry(entry string) string {
-	return fmt.Sprintf("User-agent: *\nDisallow: %s\n\n", entry)
+	return fmt.Sprintf("Disallow: %s\n", entry)
 }
@@ -1,86 +1,410 @@
 package source
 
 import (
+	"embed"
+	"fmt"
+	"os"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/go-faker/faker/v4"
-	"github.com/thoas/go-funk"
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/internal/regen"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	//go:embed tabular-schemas.yml
+	tabularSchemasFile embed.FS
+
+	// Faker generates from package wide sources so only one caller can use it at a time
+	fakerLock sync.Mutex
+
+	// Columns named like credentials (i.e api_key or client_secret) are filled with secrets when no source is given
+	credentialColumnPattern = regexp.MustCompile(`(?i)(^|_)(api_?keys?|access_?keys?|private_?keys?|secrets?|tokens?|credentials?|passw(or)?ds?|passwd|auth)($|_)`)
+
+	// SQL types written without quotes
+	numericSqlTypePattern = regexp.MustCompile(`(?i)^(tiny|small|medium|big)?int(eger)?\b|^(decimal|numeric|float|double|real)\b`)
+
+	// Matches the first (optionally database qualified) table named after FROM in a SQL query
+	queryTablePattern = regexp.MustCompile("(?i)\\bfrom\\s+(?:[`\"]?[\\w$]+[`\"]?\\.)?[`\"]?([\\w$]+)")
+)
+
+type (
+	// A table tabular files (i.e .csv and .sql) are generated from
+	TabularSchema struct {
+		Table   string          `yaml:"table"`
+		Pattern string          `yaml:"pattern"`
+		Columns []TabularColumn `yaml:"columns"`
+
+		pattern *regexp.Regexp
+	}
+
+	// A column of a table. Values are generated from at most one of the type, regex, secret or values. Columns
+	// without any are only allowed when named like a credential and are filled with a secret from a random rule
+	TabularColumn struct {
+		Name    string   `yaml:"name"`
+		Type    string   `yaml:"type"`
+		Regex   string   `yaml:"regex"`
+		Secret  string   `yaml:"secret"`
+		Values  []string `yaml:"values"`
+		SqlType string   `yaml:"sql_type"`
+
+		// Created once the column has been checked. Reseeded for each value
+		regex regen.SeedableGenerator
+	}
+
+	// Picks the table to serve for requested paths
+	TabularSchemaCollection struct {
+		schemas       []*TabularSchema
+		defaultSchema *TabularSchema
+
+		// SQL dumps
+		dumpDialect       string
+		dumpTables        []*TabularSchema
+		dumpRowsPerInsert int
+	}
+
+	// Generates the rows of a table. Rows are numbered so id columns count up like they would in a real table
+	TabularRows struct {
+		schema  *TabularSchema
+		source  *rand.SeededRand
+		now     time.Time
+		secrets *secrets.SecretGeneratorCollection
+		index   int
+	}
+
+	// Values shared between the columns of a row so they describe the same person, address or card
+	tabularRow struct {
+		*TabularRows
+		person  *tabularPerson
+		address *faker.RealAddress
+		card    *creditCard
+	}
 )
 
-type TabularGenerator struct {
-	Name string
-	Generate func() string
-}
-
-var tabularGeneratorFields = []TabularGenerator{
-	{
-		Name: "Id",
-		Generate: func() string {
-			return faker.UUIDHyphenated()
-		},
-	},
-	{
-		Name: "FirstName",
-		Generate: func() string {
-			return faker.FirstName()
-		},
-	},
-	{
-		Name: "LastName",
-		Generate: func() string {
-			return faker.LastName()
-		},
-	},
-	{
-		Name: "Email",
-		Generate: func() string {
-			return faker.Email()
-		},
-	},
-	{
-		Name: "Phone",
-		Generate: func() string {
-			return faker.E164PhoneNumber()
-		},
-	},
-	{
-		Name: "Postcode",
-		Generate: func() string {
-			return faker.GetRealAddress().PostalCode
-		},
-	},
-	{
-		Name: "City",
-		Generate: func() string {
-			return faker.GetRealAddress().City
-		},
-	},
-	{
-		Name: "Country",
-		Generate: func() string {
-			return "USA"
-		},
-	},
-	{
-		Name: "CcNumber",
-		Generate: func() string {
-			return faker.CCNumber()
-		},
-	},
-	{
-		Name: "CcType",
-		Generate: func() string {
-			return faker.CCType()
-		},
-	},
-}
-
-func GetTabularHeaderFields() []string {
-	return funk.Map(tabularGeneratorFields, func(field TabularGenerator) string {
-		return field.Name
-	}).([]string)
-}
-
-func GetTabularFieldValues() []string {
-	return funk.Map(tabularGeneratorFields, func(field TabularGenerator) string {
-		return field.Generate()
-	}).([]string)
-}
\ No newline at end of file
+func NewTabularSchemaCollection(conf *config.Config) (*TabularSchemaCollection, error) {
+	tabularConfig := conf.Generator.Tabular
+
+	// Schemas from the config come first followed by those from files so they are matched before the embedded schemas
+	schemas := []*TabularSchema{}
+	for _, schemaConfig := range tabularConfig.Schemas {
+		schema := &TabularSchema{
+			Table:   schemaConfig.Table,
+			Pattern: schemaConfig.Pattern,
+		}
+
+		for _, columnConfig := range schemaConfig.Columns {
+			schema.Columns = append(schema.Columns, TabularColumn{
+				Name:    columnConfig.Name,
+				Type:    columnConfig.Type,
+				Regex:   columnConfig.Regex,
+				Secret:  columnConfig.Secret,
+				Values:  columnConfig.Values,
+				SqlType: columnConfig.SqlType,
+			})
+		}
+
+		schemas = append(schemas, schema)
+	}
+
+	for _, path := range tabularConfig.SchemaFiles {
+		fileSchemas, err := LoadTabularSchemasFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		schemas = append(schemas, fileSchemas...)
+		zap.L().Sugar().Infow("Loaded tabular schemas", "path", path, "schemas", len(fileSchemas))
+	}
+
+	schemas = append(schemas, GetTabularSchemas()...)
+	for _, schema := range schemas {
+		if err := schema.compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	collection := &TabularSchemaCollection{
+		schemas:           schemas,
+		dumpDialect:       tabularConfig.Dump.Dialect,
+		dumpRowsPerInsert: tabularConfig.Dump.RowsPerInsert,
+	}
+
+	collection.defaultSchema = collection.GetSchemaByTable(tabularConfig.DefaultTable)
+	if collection.defaultSchema == nil {
+		return nil, fmt.Errorf("no tabular schema found for the default table %s", tabularConfig.DefaultTable)
+	}
+
+	for _, table := range tabularConfig.Dump.Tables {
+		schema := collection.GetSchemaByTable(table)
+		if schema == nil {
+			return nil, fmt.Errorf("no tabular schema found for the dumped table %s", table)
+		}
+
+		collection.dumpTables = append(collection.dumpTables, schema)
+	}
+
+	return collection, nil
+}
+
+// Gets the schema for the first table with a pattern matching the path falling back to the default table
+func (c *TabularSchemaCollection) ForPath(path string) *TabularSchema {
+	for _, schema := range c.schemas {
+		if schema.pattern.MatchString(path) {
+			return schema
+		}
+	}
+
+	return c.defaultSchema
+}
+
+// Gets the schema for the table a SQL query selects from. Tables are looked up by name before being matched like paths
+func (c *TabularSchemaCollection) ForQuery(query string) *TabularSchema {
+	match := queryTablePattern.FindStringSubmatch(query)
+	if match == nil {
+		return c.defaultSchema
+	}
+
+	if schema := c.GetSchemaByTable(match[1]); schema != nil {
+		return schema
+	}
+
+	return c.ForPath(match[1])
+}
+
+// Gets the schema for the table with the given name ignoring case (nil if there is no such table)
+func (c *TabularSchemaCollection) GetSchemaByTable(table string) *TabularSchema {
+	for _, schema := range c.schemas {
+		if strings.EqualFold(schema.Table, table) {
+			return schema
+		}
+	}
+
+	return nil
+}
+
+// Gets the tables dumped into SQL files requested with the given path. The table matching the path comes first
+// followed by the other dumped tables
+func (c *TabularSchemaCollection) DumpTables(path string) []*TabularSchema {
+	tables := []*TabularSchema{c.ForPath(path)}
+	for _, schema := range c.dumpTables {
+		if schema != tables[0] {
+			tables = append(tables, schema)
+		}
+	}
+
+	return tables
+}
+
+// The dialect SQL dumps are written in. "auto" leaves it to the requested path
+func (c *TabularSchemaCollection) DumpDialect() string {
+	return c.dumpDialect
+}
+
+// The number of rows in each INSERT statement of SQL dumps
+func (c *TabularSchemaCollection) DumpRowsPerInsert() int {
+	return c.dumpRowsPerInsert
+}
+
+// Gets the name of every table. Tables overridden by an earlier schema are only listed once
+func (c *TabularSchemaCollection) Tables() []string {
+	tables := []string{}
+	seen := map[string]bool{}
+	for _, schema := range c.schemas {
+		if !seen[schema.Table] {
+			seen[schema.Table] = true
+			tables = append(tables, schema.Table)
+		}
+	}
+
+	return tables
+}
+
+func (s *TabularSchema) ColumnNames() []string {
+	names := make([]string, len(s.Columns))
+	for i, column := range s.Columns {
+		names[i] = column.Name
+	}
+
+	return names
+}
+
+// Starts generating rows of the table. Dates are generated relative to now
+func (s *TabularSchema) NewRows(source *rand.SeededRand, now time.Time, secrets *secrets.SecretGeneratorCollection) *TabularRows {
+	return &TabularRows{
+		schema:  s,
+		source:  source,
+		now:     now,
+		secrets: secrets,
+	}
+}
+
+func (s *TabularSchema) compile() error {
+	pattern, err := regexp.Compile(s.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern for tabular schema %s: %w", s.Table, err)
+	}
+	s.pattern = pattern
+
+	if s.Table == "" || len(s.Columns) == 0 {
+		return fmt.Errorf("tabular schema %s must have a table name and at least one column", s.Pattern)
+	}
+
+	for i := range s.Columns {
+		if err := s.Columns[i].compile(); err != nil {
+			return fmt.Errorf("invalid column in tabular schema %s: %w", s.Table, err)
+		}
+	}
+
+	return nil
+}
+
+// Generates the next row of the table
+func (r *TabularRows) Next() []string {
+	r.index++
+	row := &tabularRow{TabularRows: r}
+
+	values := make([]string, len(r.schema.Columns))
+	Fake(r.source, func() {
+		for i, column := range r.schema.Columns {
+			values[i] = column.generate(row)
+		}
+	})
+
+	return values
+}
+
+// The type used for the column in CREATE TABLE statements
+func (c *TabularColumn) GetSqlType() string {
+	if c.SqlType != "" {
+		return c.SqlType
+	}
+
+	if columnType, ok := tabularTypes[c.Type]; ok {
+		return columnType.sqlType
+	}
+
+	return "varchar(255)"
+}
+
+// Columns of the id type count up from 1 so make up the primary key of their table
+func (c *TabularColumn) IsPrimaryKey() bool {
+	return c.Type == "id"
+}
+
+// If values of the column are numbers so should be written to SQL without quotes
+func (c *TabularColumn) IsNumeric() bool {
+	return numericSqlTypePattern.MatchString(c.GetSqlType())
+}
+
+// Checks the column and creates its regex generator
+func (c *TabularColumn) compile() error {
+	sources := 0
+	for _, set := range []bool{c.Type != "", c.Regex != "", c.Secret != "", len(c.Values) > 0} {
+		if set {
+			sources++
+		}
+	}
+
+	if c.Name == "" || sources > 1 {
+		return fmt.Errorf("column %s must have a name and at most one of a type, regex, secret or values", c.Name)
+	}
+
+	if sources == 0 && !credentialColumnPattern.MatchString(c.Name) {
+		return fmt.Errorf("column %s must have one of a type, regex, secret or values as it is not named like a credential", c.Name)
+	}
+
+	if _, ok := tabularTypes[c.Type]; c.Type != "" && !ok {
+		return fmt.Errorf("column %s has unknown type %s", c.Name, c.Type)
+	}
+
+	if c.Regex != "" {
+		regex, err := newColumnRegexGenerator(c.Regex)
+		if err != nil {
+			return fmt.Errorf("column %s has a regex that can not be generated from: %w", c.Name, err)
+		}
+		c.regex = regex
+	}
+
+	return nil
+}
+
+func (c *TabularColumn) generate(row *tabularRow) string {
+	switch {
+	case c.Regex != "":
+		// Regex generators hold their own source so it is reseeded from the row for each value. Rows
+		// are generated under the faker lock so the generator is only ever used by one row at a time
+		c.regex.Seed(row.source.Rand.Int63())
+		return c.regex.Generate()
+	case len(c.Values) > 0:
+		return row.source.StringChoice(&c.Values)
+	case c.Type != "":
+		return tabularTypes[c.Type].generate(row)
+	default:
+		// Credential columns without a named rule fall back to a random rule
+		return row.secrets.GenerateSecret(c.Secret)
+	}
+}
+
+func newColumnRegexGenerator(pattern string) (regen.SeedableGenerator, error) {
+	return regen.NewSeedableGenerator(pattern, &regen.GeneratorArgs{
+		Flags:                   syntax.PerlX,
+		MaxUnboundedRepeatCount: 16,
+	})
+}
+
+// Gets the embedded schemas
+func GetTabularSchemas() []*TabularSchema {
+	data, err := tabularSchemasFile.ReadFile("tabular-schemas.yml")
+	if err != nil {
+		panic(err)
+	}
+
+	schemas, err := ParseTabularSchemas(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return schemas
+}
+
+// Loads schemas from a YAML file in the same format as the embedded tabular-schemas.yml
+func LoadTabularSchemasFile(path string) ([]*TabularSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tabular schemas file %s: %w", path, err)
+	}
+
+	schemas, err := ParseTabularSchemas(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tabular schemas file %s: %w", path, err)
+	}
+
+	return schemas, nil
+}
+
+func ParseTabularSchemas(data []byte) ([]*TabularSchema, error) {
+	schemas := []*TabularSchema{}
+	if err := yaml.Unmarshal(data, &schemas); err != nil {
+		return nil, err
+	}
+
+	return schemas, nil
+}
+
+// Calls faker from within fn using the given source so the values it generates can be generated again
+func Fake(source *rand.SeededRand, fn func()) {
+	fakerLock.Lock()
+	defer fakerLock.Unlock()
+
+	faker.SetRandomSource(source.Source)
+	faker.SetCryptoSource(source.Rand)
+	fn()
+}
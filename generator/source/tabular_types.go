@@ -0,0 +1,209 @@
+package source
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/ryanolee/go-pot/rand"
+)
+
+type (
+	// A named type of value columns can be generated from
+	tabularType struct {
+		sqlType  string
+		generate func(row *tabularRow) string
+	}
+
+	tabularPerson struct {
+		firstName string
+		lastName  string
+	}
+
+	creditCard struct {
+		name     string
+		length   int
+		prefixes []string
+	}
+)
+
+var (
+	tabularCountries   = []string{"United States", "United Kingdom", "Canada", "Germany", "France", "Australia", "Netherlands", "Ireland", "Spain", "India", "Brazil", "Japan"}
+	tabularCurrencies  = []string{"USD", "USD", "USD", "EUR", "EUR", "GBP", "CAD", "AUD"}
+	tabularJobTitles   = []string{"Software Engineer", "Senior Software Engineer", "Account Manager", "Sales Executive", "HR Manager", "Accountant", "Financial Analyst", "Marketing Manager", "Customer Support Specialist", "Operations Manager", "Data Analyst", "Product Manager", "Office Manager", "Chief Financial Officer"}
+	tabularDepartments = []string{"Engineering", "Sales", "Marketing", "Finance", "Human Resources", "Customer Support", "Operations", "Legal", "IT"}
+	tabularUserAgents  = []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36 Edg/123.0.0.0",
+		"okhttp/4.12.0",
+		"python-requests/2.31.0",
+	}
+	tabularMailDomains = []string{"gmail.com", "gmail.com", "yahoo.com", "outlook.com", "hotmail.com", "icloud.com", "aol.com", "protonmail.com"}
+
+	// Bcrypt hashes use their own base64 alphabet
+	bcryptRunes = []rune("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789")
+
+	// Faker caches the first card type it picks for the life of the process so cards are generated here instead
+	creditCards = []creditCard{
+		{name: "VISA", length: 16, prefixes: []string{"4539", "4556", "4916", "4532", "4929", "4485", "4716"}},
+		{name: "MasterCard", length: 16, prefixes: []string{"51", "52", "53", "54", "55"}},
+		{name: "American Express", length: 15, prefixes: []string{"34", "37"}},
+		{name: "Discover", length: 16, prefixes: []string{"6011"}},
+		{name: "JCB", length: 16, prefixes: []string{"3528", "3538", "3548", "3558", "3568", "3578", "3588"}},
+		{name: "Diners Club", length: 14, prefixes: []string{"36", "38", "39"}},
+	}
+)
+
+// Types columns can be generated from by name. Anything time based is generated relative to the time of the stream
+var tabularTypes = map[string]tabularType{
+	"id": {sqlType: "int", generate: func(row *tabularRow) string {
+		return strconv.Itoa(row.index)
+	}},
+	"integer": {sqlType: "int", generate: func(row *tabularRow) string {
+		return strconv.Itoa(row.source.RandomInt(1, 10000))
+	}},
+	"boolean": {sqlType: "tinyint(1)", generate: func(row *tabularRow) string {
+		if row.source.RandomBool() {
+			return "1"
+		}
+		return "0"
+	}},
+	"uuid": {sqlType: "char(36)", generate: func(row *tabularRow) string {
+		return faker.UUIDHyphenated()
+	}},
+	"first_name": {sqlType: "varchar(100)", generate: func(row *tabularRow) string {
+		return row.getPerson().firstName
+	}},
+	"last_name": {sqlType: "varchar(100)", generate: func(row *tabularRow) string {
+		return row.getPerson().lastName
+	}},
+	"name": {sqlType: "varchar(200)", generate: func(row *tabularRow) string {
+		person := row.getPerson()
+		return person.firstName + " " + person.lastName
+	}},
+	"username": {sqlType: "varchar(64)", generate: func(row *tabularRow) string {
+		person := row.getPerson()
+		return emailSafe(person.firstName[:1] + person.lastName)
+	}},
+	"email": {sqlType: "varchar(255)", generate: func(row *tabularRow) string {
+		person := row.getPerson()
+		return emailSafe(person.firstName) + "." + emailSafe(person.lastName) + "@" + row.source.StringChoice(&tabularMailDomains)
+	}},
+	"password_hash": {sqlType: "varchar(255)", generate: func(row *tabularRow) string {
+		return "$2y$10$" + row.source.RandomString(53, bcryptRunes)
+	}},
+	"phone": {sqlType: "varchar(32)", generate: func(row *tabularRow) string {
+		return faker.E164PhoneNumber()
+	}},
+	"address": {sqlType: "varchar(255)", generate: func(row *tabularRow) string {
+		return row.getAddress().Address
+	}},
+	"city": {sqlType: "varchar(100)", generate: func(row *tabularRow) string {
+		return row.getAddress().City
+	}},
+	"state": {sqlType: "varchar(64)", generate: func(row *tabularRow) string {
+		return row.getAddress().State
+	}},
+	"postcode": {sqlType: "varchar(16)", generate: func(row *tabularRow) string {
+		return row.getAddress().PostalCode
+	}},
+	"country": {sqlType: "varchar(64)", generate: func(row *tabularRow) string {
+		return row.source.StringChoice(&tabularCountries)
+	}},
+	"ip": {sqlType: "varchar(45)", generate: func(row *tabularRow) string {
+		return faker.IPv4()
+	}},
+	"url": {sqlType: "varchar(255)", generate: func(row *tabularRow) string {
+		return faker.URL()
+	}},
+	"user_agent": {sqlType: "varchar(255)", generate: func(row *tabularRow) string {
+		return row.source.StringChoice(&tabularUserAgents)
+	}},
+	"word": {sqlType: "varchar(64)", generate: func(row *tabularRow) string {
+		return faker.Word()
+	}},
+	"sentence": {sqlType: "text", generate: func(row *tabularRow) string {
+		return faker.Sentence()
+	}},
+	"job_title": {sqlType: "varchar(100)", generate: func(row *tabularRow) string {
+		return row.source.StringChoice(&tabularJobTitles)
+	}},
+	"department": {sqlType: "varchar(100)", generate: func(row *tabularRow) string {
+		return row.source.StringChoice(&tabularDepartments)
+	}},
+	"salary": {sqlType: "decimal(10,2)", generate: func(row *tabularRow) string {
+		return strconv.Itoa(row.source.RandomInt(35, 180)*1000) + ".00"
+	}},
+	"amount": {sqlType: "decimal(10,2)", generate: func(row *tabularRow) string {
+		return fmt.Sprintf("%.2f", row.source.RandomFloat(1, 2000))
+	}},
+	"currency": {sqlType: "char(3)", generate: func(row *tabularRow) string {
+		return row.source.StringChoice(&tabularCurrencies)
+	}},
+	"date": {sqlType: "date", generate: func(row *tabularRow) string {
+		return row.pastTime().Format(time.DateOnly)
+	}},
+	"datetime": {sqlType: "datetime", generate: func(row *tabularRow) string {
+		return row.pastTime().Format(time.DateTime)
+	}},
+	"cc_number": {sqlType: "varchar(19)", generate: func(row *tabularRow) string {
+		card := row.getCreditCard()
+		prefix := row.source.StringChoice(&card.prefixes)
+		return prefix + row.source.RandomString(card.length-len(prefix), rand.Numbers)
+	}},
+	"cc_type": {sqlType: "varchar(32)", generate: func(row *tabularRow) string {
+		return row.getCreditCard().name
+	}},
+	"cc_expiry": {sqlType: "char(5)", generate: func(row *tabularRow) string {
+		return row.now.AddDate(0, row.source.RandomInt(1, 60), 0).Format("01/06")
+	}},
+}
+
+// Lower cases a name dropping anything not allowed in the local part of an email address (i.e O'Conner)
+func emailSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r
+		}
+		return -1
+	}, strings.ToLower(name))
+}
+
+// A time within the last three years
+func (r *tabularRow) pastTime() time.Time {
+	return r.now.Add(-time.Duration(r.source.RandomInt(0, 3*365*24*60*60)) * time.Second)
+}
+
+func (r *tabularRow) getPerson() *tabularPerson {
+	if r.person == nil {
+		r.person = &tabularPerson{
+			firstName: faker.FirstName(),
+			lastName:  faker.LastName(),
+		}
+	}
+
+	return r.person
+}
+
+func (r *tabularRow) getAddress() *faker.RealAddress {
+	if r.address == nil {
+		address := faker.GetRealAddress()
+		r.address = &address
+	}
+
+	return r.address
+}
+
+func (r *tabularRow) getCreditCard() *creditCard {
+	if r.card == nil {
+		r.card = &creditCards[r.source.RandomInt(0, len(creditCards))]
+	}
+
+	return r.card
+}
@@ -0,0 +1,40 @@
+package generator
+
+type (
+	// Streams a fixed piece of content once
+	StaticGenerator struct {
+		data []byte
+		done bool
+	}
+)
+
+func NewStaticGenerator(data []byte) *StaticGenerator {
+	return &StaticGenerator{
+		data: data,
+	}
+}
+
+func (g *StaticGenerator) Generate() []byte {
+	return g.data
+}
+
+func (g *StaticGenerator) Start() []byte {
+	return []byte{}
+}
+
+func (g *StaticGenerator) GenerateChunk() []byte {
+	g.done = true
+	return g.data
+}
+
+func (g *StaticGenerator) ChunkSeparator() []byte {
+	return []byte{}
+}
+
+func (g *StaticGenerator) End() []byte {
+	return []byte{}
+}
+
+func (g *StaticGenerator) Done() bool {
+	return g.done
+}
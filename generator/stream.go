@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/rand"
+)
+
+// Layout of the day streams are numbered within
+const StreamDateLayout = "2006-01-02"
+
+type (
+	// The randomness and time a stream is generated from. Generators given equal sources generate the same bytes
+	StreamSource struct {
+		Rand *rand.SeededRand
+		Now  time.Time
+
+		replayable bool
+	}
+
+	// Identifies a single stream served to a client
+	StreamKey struct {
+		Protocol string
+		Client   string
+		Path     string
+
+		// The UTC day the stream was served on
+		Date time.Time
+
+		// Streams are numbered from 1 for each client and day
+		Number uint64
+	}
+
+	// Hands out the source of each stream. In replay mode streams are seeded from the node secret and the key
+	// of the stream so go-pot replay can generate them again. Otherwise streams are seeded from the time
+	StreamSourceFactory struct {
+		secret string
+
+		lock     sync.Mutex
+		day      string
+		counters map[string]uint64
+	}
+)
+
+// Creates a source that can not be replayed
+func NewStreamSource() *StreamSource {
+	return &StreamSource{
+		Rand: rand.NewSeededRandFromTime(),
+		Now:  time.Now(),
+	}
+}
+
+// Creates the source for the stream with the given key. Streams are dated at the start of the day they were served
+// on so the key holds everything needed to generate them again
+func NewReplayStreamSource(secret string, key StreamKey) *StreamSource {
+	date := key.Date.UTC().Truncate(24 * time.Hour)
+	return &StreamSource{
+		Rand: rand.NewSeededRandFromSecret([]byte(secret), key.Protocol, key.Client, date.Format(StreamDateLayout), key.Path, strconv.FormatUint(key.Number, 10)),
+		Now:  date,
+
+		replayable: true,
+	}
+}
+
+// Runs fn with faker drawing from the stream. Faker is shared by every stream so it is only locked
+// to replayable streams. Other streams are left to generate alongside each other
+func (s *StreamSource) Fake(fn func()) {
+	if !s.replayable {
+		fn()
+		return
+	}
+
+	source.Fake(s.Rand, fn)
+}
+
+func NewStreamSourceFactory(conf *config.Config) *StreamSourceFactory {
+	factory := &StreamSourceFactory{
+		counters: map[string]uint64{},
+	}
+
+	if conf.Generator.Replay.Enabled {
+		factory.secret = conf.Generator.Replay.Secret
+	}
+
+	return factory
+}
+
+// Gets the source for the next stream served to the client. The key is only returned in replay mode
+func (f *StreamSourceFactory) Next(protocol string, client string, path string) (*StreamSource, *StreamKey) {
+	if f.secret == "" {
+		return NewStreamSource(), nil
+	}
+
+	key := &StreamKey{
+		Protocol: protocol,
+		Client:   client,
+		Path:     path,
+		Date:     time.Now().UTC().Truncate(24 * time.Hour),
+	}
+	key.Number = f.nextNumber(protocol+"-"+client, key.Date.Format(StreamDateLayout))
+
+	return NewReplayStreamSource(f.secret, *key), key
+}
+
+// Counters are reset every day so only clients seen today are kept track of
+func (f *StreamSourceFactory) nextNumber(client string, day string) uint64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.day != day {
+		f.day = day
+		f.counters = map[string]uint64{}
+	}
+
+	f.counters[client]++
+	return f.counters[client]
+}
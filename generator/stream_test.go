@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/secrets"
+)
+
+const testReplaySecret = "replay secret"
+
+// Streams generated from the same key are the same byte for byte so go-pot replay can generate them again
+func TestReplayStreamsAreDeterministic(t *testing.T) {
+	conf := newTestConfig(t)
+	configGenerators, err := NewConfigGeneratorCollection(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tabularSchemas, err := source.NewTabularSchemaCollection(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretGenerators, err := secrets.NewSecretGeneratorCollection(conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func(key StreamKey) []byte {
+		enc := encoder.GetEncoderForPath(key.Path)
+		gen := GetGeneratorForPath(key.Path, enc, configGenerators, tabularSchemas, secretGenerators, NewReplayStreamSource(testReplaySecret, key))
+
+		var data bytes.Buffer
+		data.Write(gen.Start())
+		for i := 0; i < 20; i++ {
+			if finite, ok := gen.(FiniteGenerator); ok && finite.Done() {
+				break
+			}
+
+			data.Write(gen.GenerateChunk())
+			data.Write(gen.ChunkSeparator())
+		}
+		data.Write(gen.End())
+
+		return data.Bytes()
+	}
+
+	served := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	for _, path := range []string{"/package.json", "/config.yml", "/settings.xml", "/.env", "/users.csv", "/backup.sql", "/credentials", "/id_rsa", "/backup.zip"} {
+		t.Run(path, func(t *testing.T) {
+			key := StreamKey{Protocol: "http", Client: "203.0.113.7", Path: path, Date: served, Number: 3}
+			first := generate(key)
+			if len(first) == 0 {
+				t.Fatal("expected the stream to have content")
+			}
+
+			// Replays are run later in the day the stream was served on
+			replayKey := key
+			replayKey.Date = served.Add(time.Hour * 8)
+			if replayed := generate(replayKey); !bytes.Equal(first, replayed) {
+				t.Errorf("expected the replayed stream to match the served stream. They differ from byte %d", firstDifference(first, replayed))
+			}
+
+			nextKey := key
+			nextKey.Number++
+			if next := generate(nextKey); bytes.Equal(first, next) {
+				t.Error("expected the next stream to the client to differ")
+			}
+		})
+	}
+}
+
+func firstDifference(a []byte, b []byte) int {
+	for i := range min(len(a), len(b)) {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return min(len(a), len(b))
+}
+
+func TestStreamSourceFactoryNext(t *testing.T) {
+	conf := newTestConfig(t)
+	if _, key := NewStreamSourceFactory(conf).Next("http", "203.0.113.7", "/"); key != nil {
+		t.Errorf("expected no key outside of replay mode got %+v", key)
+	}
+
+	conf.Generator.Replay.Enabled = true
+	conf.Generator.Replay.Secret = testReplaySecret
+	factory := NewStreamSourceFactory(conf)
+
+	for _, test := range []struct {
+		protocol   string
+		client     string
+		wantNumber uint64
+	}{
+		{protocol: "http", client: "203.0.113.7", wantNumber: 1},
+		{protocol: "http", client: "203.0.113.7", wantNumber: 2},
+		{protocol: "http", client: "203.0.113.8", wantNumber: 1},
+		{protocol: "ftp", client: "203.0.113.7", wantNumber: 1},
+	} {
+		stream, key := factory.Next(test.protocol, test.client, "/")
+		if key == nil || key.Number != test.wantNumber {
+			t.Fatalf("expected stream %d for %s over %s got %+v", test.wantNumber, test.client, test.protocol, key)
+		}
+
+		replayed := NewReplayStreamSource(testReplaySecret, *key)
+		if stream.Rand.RandomString(16) != replayed.Rand.RandomString(16) || !stream.Now.Equal(replayed.Now) {
+			t.Errorf("expected the stream %+v to be replayable", key)
+		}
+	}
+}
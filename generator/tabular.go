@@ -3,23 +3,28 @@ package generator
 import (
 	"github.com/ryanolee/go-pot/generator/encoder"
 	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/secrets"
 	"go.uber.org/zap"
 )
 
 type (
 	TabularGenerator struct {
 		encoder encoder.Encoder
+		schema  *source.TabularSchema
+		rows    *source.TabularRows
 	}
 )
 
-func NewTabularGenerator(encoder encoder.Encoder) *TabularGenerator {
+func NewTabularGenerator(encoder encoder.Encoder, schema *source.TabularSchema, secrets *secrets.SecretGeneratorCollection, stream *StreamSource) *TabularGenerator {
 	return &TabularGenerator{
 		encoder: encoder,
+		schema:  schema,
+		rows:    schema.NewRows(stream.Rand, stream.Now, secrets),
 	}
 }
 
 func (g *TabularGenerator) Generate() []byte {
-	data := source.GetTabularFieldValues()
+	data := g.rows.Next()
 
 	marshalledData, err := g.encoder.Marshal(data)
 	if err != nil {
@@ -30,7 +35,12 @@ func (g *TabularGenerator) Generate() []byte {
 	return marshalledData
 }
 
+// Encoders that describe the table (i.e with a header or CREATE TABLE statement) are given the schema
 func (g *TabularGenerator) Start() []byte {
+	if tableEncoder, ok := g.encoder.(encoder.TableEncoder); ok {
+		return []byte(tableEncoder.StartTable(g.schema))
+	}
+
 	return []byte(g.encoder.Start())
 }
 
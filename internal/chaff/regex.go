@@ -0,0 +1,43 @@
+package chaff
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ryanolee/go-pot/internal/regen"
+)
+
+const fullStringLiteral = "[~{FULL_STOP_LITERAL}~]"
+
+type (
+	// Generates strings matching a pattern. The generator is shared by every value generated from the schema
+	// so it is reseeded from the options of each generation under a lock, keeping the strings it generates in
+	// step with the rest of the generation
+	patternGenerator struct {
+		lock      sync.Mutex
+		generator regen.SeedableGenerator
+	}
+)
+
+func newRegexGenerator(pattern string, opts *regen.GeneratorArgs) (*patternGenerator, error) {
+	if opts.SuppressRandomBytes {
+		pattern = strings.ReplaceAll(pattern, "\\.", fullStringLiteral)
+		pattern = strings.ReplaceAll(pattern, ".", "\\w")
+		pattern = strings.ReplaceAll(pattern, fullStringLiteral, "\\.")
+	}
+
+	generator, err := regen.NewSeedableGenerator(pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &patternGenerator{generator: generator}, nil
+}
+
+func (g *patternGenerator) Generate(opts *GeneratorOptions) string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.generator.Seed(opts.Rand.Rand.Int63())
+	return g.generator.Generate()
+}
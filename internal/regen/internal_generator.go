@@ -54,8 +54,9 @@ func init() {
 	}
 }
 
+// The regexp is only formatted when the generator is so generators for large expressions stay small
 type internalGenerator struct {
-	Name         string
+	Regexp       *syntax.Regexp
 	GenerateFunc func() string
 }
 
@@ -64,7 +65,7 @@ func (gen *internalGenerator) Generate() string {
 }
 
 func (gen *internalGenerator) String() string {
-	return gen.Name
+	return gen.Regexp.String()
 }
 
 // Create a new generator for each expression in regexps.
@@ -98,28 +99,28 @@ func newGenerator(regexp *syntax.Regexp, args *GeneratorArgs) (generator *intern
 
 // Generator that does nothing.
 func noop(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		return ""
 	}}, nil
 }
 
 func opEmptyMatch(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
 	enforceOp(regexp, syntax.OpEmptyMatch)
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		return ""
 	}}, nil
 }
 
 func opLiteral(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
 	enforceOp(regexp, syntax.OpLiteral)
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		return runesToString(regexp.Rune...)
 	}}, nil
 }
 
 func opAnyChar(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
 	enforceOp(regexp, syntax.OpAnyChar)
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		return runesToString(rune(args.rng.Int31()))
 	}}, nil
 }
@@ -127,7 +128,7 @@ func opAnyChar(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator,
 func opAnyCharNotNl(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
 	enforceOp(regexp, syntax.OpAnyCharNotNL)
 	charClass := newCharClass(1, rune(math.MaxInt32))
-	return createCharClassGenerator(regexp.String(), charClass, args)
+	return createCharClassGenerator(regexp, charClass, args)
 }
 
 func opQuest(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
@@ -155,7 +156,7 @@ func opRepeat(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, e
 func opCharClass(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator, error) {
 	enforceOp(regexp, syntax.OpCharClass)
 	charClass := parseCharClass(regexp.Rune)
-	return createCharClassGenerator(regexp.String(), charClass, args)
+	return createCharClassGenerator(regexp, charClass, args)
 }
 
 func opConcat(regexp *syntax.Regexp, genArgs *GeneratorArgs) (*internalGenerator, error) {
@@ -166,7 +167,7 @@ func opConcat(regexp *syntax.Regexp, genArgs *GeneratorArgs) (*internalGenerator
 		return nil, generatorError(err, "error creating generators for concat pattern /%s/", regexp)
 	}
 
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		var result bytes.Buffer
 		for _, generator := range generators {
 			result.WriteString(generator.Generate())
@@ -185,7 +186,7 @@ func opAlternate(regexp *syntax.Regexp, genArgs *GeneratorArgs) (*internalGenera
 
 	numGens := len(generators)
 
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		i := genArgs.rng.Intn(numGens)
 		generator := generators[i]
 		return generator.Generate()
@@ -208,7 +209,7 @@ func opCapture(regexp *syntax.Regexp, args *GeneratorArgs) (*internalGenerator,
 	// Group indices are 0-based, but index 0 is the whole expression.
 	index := regexp.Cap - 1
 
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		return args.CaptureGroupHandler(index, regexp.Name, groupRegexp, generator, args)
 	}}, nil
 }
@@ -233,8 +234,8 @@ func enforceSingleSub(regexp *syntax.Regexp) error {
 	return nil
 }
 
-func createCharClassGenerator(name string, charClass *tCharClass, args *GeneratorArgs) (*internalGenerator, error) {
-	return &internalGenerator{name, func() string {
+func createCharClassGenerator(regexp *syntax.Regexp, charClass *tCharClass, args *GeneratorArgs) (*internalGenerator, error) {
+	return &internalGenerator{regexp, func() string {
 		i := args.rng.Int31n(charClass.TotalSize)
 		r := charClass.GetRuneAt(i)
 		return runesToString(r)
@@ -259,7 +260,7 @@ func createRepeatingGenerator(regexp *syntax.Regexp, genArgs *GeneratorArgs, min
 		max = int(genArgs.MaxUnboundedRepeatCount)
 	}
 
-	return &internalGenerator{regexp.String(), func() string {
+	return &internalGenerator{regexp, func() string {
 		n := min + genArgs.rng.Intn(max-min+1)
 
 		var result bytes.Buffer
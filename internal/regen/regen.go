@@ -207,6 +207,31 @@ func Generate(pattern string) (string, error) {
 // NewGenerator creates a generator that returns random strings that match the regular expression in pattern.
 // If args is nil, default values are used.
 func NewGenerator(pattern string, inputArgs *GeneratorArgs) (generator Generator, err error) {
+	return NewSeedableGenerator(pattern, inputArgs)
+}
+
+// SeedableGenerator is a Generator whose random number generator can be reseeded.
+type SeedableGenerator interface {
+	Generator
+
+	// Seed reseeds the generator. Generators given the same seed generate the same strings.
+	Seed(seed int64)
+}
+
+type seedableGenerator struct {
+	*internalGenerator
+	rng *rand.Rand
+}
+
+func (gen *seedableGenerator) Seed(seed int64) {
+	gen.rng.Seed(seed)
+}
+
+/*
+NewSeedableGenerator is like NewGenerator but the generator returned can be reseeded so a generator
+created once can generate the same strings again.
+*/
+func NewSeedableGenerator(pattern string, inputArgs *GeneratorArgs) (generator SeedableGenerator, err error) {
 	args := GeneratorArgs{}
 
 	// Copy inputArgs so the caller can't change them.
@@ -229,5 +254,5 @@ func NewGenerator(pattern string, inputArgs *GeneratorArgs) (generator Generator
 		return
 	}
 
-	return gen, nil
+	return &seedableGenerator{gen, args.rng}, nil
 }
@@ -4,7 +4,6 @@ import (
 	"hash/crc64"
 	"io"
 	"os"
-	"time"
 
 	ftpserver "github.com/fclairamb/ftpserverlib"
 	"github.com/ryanolee/go-pot/generator/filesystem"
@@ -33,7 +32,6 @@ type FtpFile struct {
 
 	// Config
 	transferChunkSize int
-	transferDelay     time.Duration
 	fileSize          int
 }
 
@@ -48,7 +46,6 @@ func NewFtpFile(name string, gen *filesystem.FilesystemGenerator, ctx ftpserver.
 		ctx:               ctx,
 		stall:             repo.GetFtpStallFactory().FromName(ctx, name, fileSize),
 		transferChunkSize: repo.GetConfig().FtpServer.Transfer.ChunkSize,
-		transferDelay:     time.Duration(repo.GetConfig().FtpServer.Transfer.ChunkSendRate) * time.Millisecond,
 		fileSize:          fileSize,
 		logger:            logger,
 	}
@@ -62,7 +59,6 @@ func (f *FtpFile) Close() error {
 
 func (f *FtpFile) Read(p []byte) (n int, err error) {
 	f.logger.Log("read_file", zap.String("path", f.name), zap.Int("data_requested", len(p)))
-	time.Sleep(f.transferDelay)
 	return f.stall.Read(p)
 }
 
@@ -6,6 +6,7 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ryanolee/go-pot/config"
 	stallLib "github.com/ryanolee/go-pot/core/stall"
@@ -20,8 +21,10 @@ type (
 		encoder   encoder.Encoder
 		generator generator.Generator
 
-		// Size of each chunk to send in calls to
-		chunkSendSize int
+		// Decides how long to wait before each read and how much data to send in it
+		pacer     stallLib.Pacer
+		scheduler *stallLib.Scheduler
+		waker     *stallLib.Waker
 
 		// Number of bytes generated. N.b this does not include the start, delimiter, padding or end, just the data
 		bytesGenerated int
@@ -47,6 +50,8 @@ type (
 		deregisterChan chan stallLib.Staller
 		forcedEOF      bool
 		closed         bool
+		closeChan      chan struct{}
+		closeOnce      sync.Once
 	}
 
 	NewFtpFileStallerArgs struct {
@@ -65,21 +70,39 @@ type (
 		// The associated generator for the staller
 		Generator generator.Generator
 
+		// The pacer data is sent at. Without one data is sent as fast as it is read (i.e when replaying a stream)
+		Pacer stallLib.Pacer
+
+		// The scheduler waking the staller when it is next due to send data. Without one reads are never delayed
+		Scheduler *stallLib.Scheduler
+
 		// Number of bytes to send as part of the staller action
 		BytesToSend int
 	}
 )
 
 func NewFtpFileStall(args *NewFtpFileStallerArgs) *FtpFileStaller {
-	return &FtpFileStaller{
-		id:            args.Id,
-		groupId:       args.GroupId,
-		encoder:       args.Encoder,
-		generator:     args.Generator,
-		bytesToSend:   args.BytesToSend,
-		chunkSendSize: args.Config.FtpServer.Transfer.ChunkSize,
-		readMutex:     sync.Mutex{},
+	if args.Pacer == nil {
+		args.Pacer = stallLib.NewConstantPacer(stallLib.Pace{ChunkSize: args.Config.FtpServer.Transfer.ChunkSize})
 	}
+
+	staller := &FtpFileStaller{
+		id:          args.Id,
+		groupId:     args.GroupId,
+		encoder:     args.Encoder,
+		generator:   args.Generator,
+		bytesToSend: args.BytesToSend,
+		pacer:       args.Pacer,
+		scheduler:   args.Scheduler,
+		readMutex:   sync.Mutex{},
+		closeChan:   make(chan struct{}),
+	}
+
+	if args.Scheduler != nil {
+		staller.waker = args.Scheduler.NewWaker()
+	}
+
+	return staller
 }
 
 // io.Reader interface implementation
@@ -88,6 +111,11 @@ func (f *FtpFileStaller) Read(p []byte) (n int, err error) {
 		return 0, io.EOF
 	}
 
+	delay, chunkSize := f.nextSend(len(p))
+	if f.waker != nil && !f.waker.Wait(delay, f.closeChan) {
+		return 0, io.EOF
+	}
+
 	f.readMutex.Lock()
 	defer f.readMutex.Unlock()
 
@@ -108,7 +136,7 @@ func (f *FtpFileStaller) Read(p []byte) (n int, err error) {
 		return f.sendData(f.delaminate(), p)
 	}
 
-	nextChunk := f.getBytesToSend()
+	nextChunk := f.getBytesToSend(chunkSize)
 
 	// Reset the current chunk if we have read all of it
 	if f.currentChunkRead >= f.currentChunkSize {
@@ -119,6 +147,15 @@ func (f *FtpFileStaller) Read(p []byte) (n int, err error) {
 
 }
 
+// Gets the next send from the pacer. Sends closer together than a tick of the scheduler are combined
+func (f *FtpFileStaller) nextSend(limit int) (time.Duration, int) {
+	if f.scheduler == nil {
+		return f.pacer.Next()
+	}
+
+	return f.scheduler.NextSend(f.pacer, limit)
+}
+
 // Sends first chunk of data
 func (f *FtpFileStaller) start() []byte {
 	return []byte(f.encoder.Start())
@@ -142,9 +179,9 @@ func (f *FtpFileStaller) sendData(source []byte, buffer []byte) (n int, err erro
 	return bytesCopied, nil
 }
 
-func (f *FtpFileStaller) getBytesToSend() []byte {
+func (f *FtpFileStaller) getBytesToSend(chunkSize int) []byte {
 	lowerBound := f.currentChunkRead
-	upperBound := int(math.Min(float64(f.currentChunkRead+f.chunkSendSize), float64(f.currentChunkSize)))
+	upperBound := int(math.Min(float64(f.currentChunkRead+chunkSize), float64(f.currentChunkSize)))
 
 	toSend := f.currentChunk[lowerBound:int(upperBound)]
 	f.currentChunkRead += len(toSend)
@@ -179,6 +216,15 @@ func (f *FtpFileStaller) setNextChunk() {
 func (f *FtpFileStaller) setLastChunk() {
 	end := f.generator.End()
 
+	// Binary data (i.e archives) would be corrupted by padding so the file is left short instead
+	if binary, ok := f.generator.(generator.BinaryGenerator); ok && binary.IsBinary() {
+		f.currentChunk = end
+		f.currentChunkSize = len(end)
+		f.currentChunkRead = 0
+		f.bytesToSend = f.bytesSent + len(end)
+		return
+	}
+
 	paddingToGenerate := f.bytesToSend - (f.bytesSent + len(end))
 
 	// Generate spaces as padding
@@ -192,8 +238,8 @@ func (f *FtpFileStaller) setLastChunk() {
 }
 
 func (f *FtpFileStaller) String() string {
-	return fmt.Sprintf("FtpFileStaller{bytesToSend: %d, bytesSent: %d, bytesGenerated: %d, chunkSendSize: %d, currentChunkSize: %d, currentChunkRead: %d}",
-		f.bytesToSend, f.bytesSent, f.bytesGenerated, f.chunkSendSize, f.currentChunkSize, f.currentChunkRead)
+	return fmt.Sprintf("FtpFileStaller{bytesToSend: %d, bytesSent: %d, bytesGenerated: %d, currentChunkSize: %d, currentChunkRead: %d}",
+		f.bytesToSend, f.bytesSent, f.bytesGenerated, f.currentChunkSize, f.currentChunkRead)
 }
 
 // Staller interface impl
@@ -206,6 +252,9 @@ func (f *FtpFileStaller) BindToPool(deregisterChan chan stallLib.Staller) {
 func (f *FtpFileStaller) Close() {
 	f.forcedEOF = true
 	f.closed = true
+	f.closeOnce.Do(func() {
+		close(f.closeChan)
+	})
 }
 
 func (f *FtpFileStaller) Halt() {
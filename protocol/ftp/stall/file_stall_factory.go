@@ -3,12 +3,15 @@ package stall
 import (
 	"fmt"
 	"hash/crc64"
+	"time"
 
 	ftpserver "github.com/fclairamb/ftpserverlib"
 	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
 	"github.com/ryanolee/go-pot/core/stall"
 	"github.com/ryanolee/go-pot/generator"
 	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
 	"github.com/ryanolee/go-pot/secrets"
 	"go.uber.org/zap"
 )
@@ -20,7 +23,11 @@ type (
 		config           *config.Config
 		stallerPool      *stall.StallerPool
 		configGenerators *generator.ConfigGeneratorCollection
+		tabularSchemas   *source.TabularSchemaCollection
 		secretGenerators *secrets.SecretGeneratorCollection
+		streams          *generator.StreamSourceFactory
+		pacers           *stall.PacerFactory
+		scheduler        *stall.Scheduler
 	}
 )
 
@@ -28,28 +35,62 @@ func NewFtpFileStallerFactory(
 	config *config.Config,
 	stallerPool *stall.StallerPool,
 	configGenerators *generator.ConfigGeneratorCollection,
+	tabularSchemas *source.TabularSchemaCollection,
 	secretGenerators *secrets.SecretGeneratorCollection,
+	streams *generator.StreamSourceFactory,
+	pacers *stall.PacerFactory,
+	scheduler *stall.Scheduler,
 ) *FtpFileStallerFactory {
 	return &FtpFileStallerFactory{
 		config:           config,
 		stallerPool:      stallerPool,
 		configGenerators: configGenerators,
+		tabularSchemas:   tabularSchemas,
 		secretGenerators: secretGenerators,
+		streams:          streams,
+		pacers:           pacers,
+		scheduler:        scheduler,
 	}
 }
 
 // Creates a single file stalling handle
 func (f *FtpFileStallerFactory) FromName(ctx ftpserver.ClientContext, name string, size int) *FtpFileStaller {
+	groupId := fmt.Sprintf("ftp-%d", ctx.ID())
+	ip := logging.GetHost(ctx.RemoteAddr())
+	secretGenerators := f.secretGenerators.ForClient(secrets.HoneytokenClient{
+		Protocol:  "ftp",
+		Ip:        ip,
+		RequestId: groupId,
+	})
+
+	stream, key := f.streams.Next("ftp", ip, name)
+	if key != nil {
+		zap.L().Sugar().Infow("Serving replayable stream", "protocol", key.Protocol, "ip", ip, "path", name, "stream_number", key.Number)
+	}
+
 	encoderInstance := encoder.GetEncoderForPath(name)
-	generatorInstance := generator.GetGeneratorForEncoder(encoderInstance, f.configGenerators, f.secretGenerators)
+	generatorInstance := generator.GetGeneratorForPath(name, encoderInstance, f.configGenerators, f.tabularSchemas, secretGenerators, stream)
 	stallerId := crc64.Checksum([]byte(name), crc64Table)
 
+	// FTP clients have no user agent though some announce themselves with the CLNT command
+	transferConfig := f.config.FtpServer.Transfer
+	pacer := f.pacers.ForClient(stall.PacerClient{
+		Protocol:  "ftp",
+		Path:      name,
+		UserAgent: ctx.GetClientVersion(),
+	}, stall.Pace{
+		Interval:  time.Duration(transferConfig.ChunkSendRate) * time.Millisecond,
+		ChunkSize: transferConfig.ChunkSize,
+	})
+
 	staller := NewFtpFileStall(&NewFtpFileStallerArgs{
 		Config:      f.config,
 		Id:          stallerId,
-		GroupId:     fmt.Sprintf("ftp-%d", ctx.ID()),
+		GroupId:     groupId,
 		Encoder:     encoderInstance,
 		Generator:   generatorInstance,
+		Pacer:       pacer,
+		Scheduler:   f.scheduler,
 		BytesToSend: size,
 	})
 
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+)
+
+// Loads the certificate to serve HTTPS with from disk, or generates one in memory if no certificate is provided
+func getCertificate(c *config.Config) (tls.Certificate, error) {
+	if c.Server.Tls.CertFile != "" {
+		return tls.LoadX509KeyPair(c.Server.Tls.CertFile, c.Server.Tls.KeyFile)
+	}
+
+	return getGeneratedCert(c)
+}
+
+// Generates a certificate issued by a made up certificate authority so the certificate
+// looks like one belonging to a real corporate host rather than being self signed
+func getGeneratedCert(c *config.Config) (tls.Certificate, error) {
+	tlsConfig := c.Server.Tls
+	notBefore := time.Now().AddDate(0, 0, -30)
+
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber: randomSerialNumber(),
+		Subject: pkix.Name{
+			CommonName:         tlsConfig.Issuer.CommonName,
+			Organization:       nonEmpty(tlsConfig.Issuer.Organization),
+			OrganizationalUnit: nonEmpty(tlsConfig.Issuer.OrganizationalUnit),
+			Country:            nonEmpty(tlsConfig.Issuer.Country),
+		},
+		NotBefore:             notBefore.AddDate(-2, 0, 0),
+		NotAfter:              notBefore.AddDate(8, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	certTemplate := &x509.Certificate{
+		SerialNumber: randomSerialNumber(),
+		Subject: pkix.Name{
+			CommonName:   tlsConfig.CommonName,
+			Organization: nonEmpty(tlsConfig.Organization),
+		},
+		DNSNames:              tlsConfig.DnsNames,
+		IPAddresses:           parseIpAddresses(tlsConfig.IpAddresses),
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(0, 0, tlsConfig.ValidDays),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	caCert, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	parsedCaCert, err := x509.ParseCertificate(caCert)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	cert, err := x509.CreateCertificate(rand.Reader, certTemplate, parsedCaCert, key.Public(), caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{cert, caCert},
+		PrivateKey:  key,
+	}, nil
+}
+
+func randomSerialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	if err != nil {
+		return big.NewInt(1)
+	}
+
+	return serial
+}
+
+func parseIpAddresses(addresses []string) []net.IP {
+	ips := make([]net.IP, 0, len(addresses))
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+
+	return ips
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return []string{value}
+}
@@ -19,6 +19,7 @@ type (
 		mainLogger  *zap.Logger
 		fieldsToLog []string
 		loggingMode string
+		maxBodySize int
 		uaParser    *uaparser.Parser
 	}
 
@@ -26,6 +27,7 @@ type (
 		Context        *fiber.Ctx
 		Duration       time.Duration
 		uaDetails      *uaparser.Client
+		body           string
 		phase          string
 		resolvedFields map[string]string
 	}
@@ -33,6 +35,9 @@ type (
 	fieldLoggers map[string]func(*HttpAccessLogEntry) string
 )
 
+// Request local holding the number of the stream served to the client in replay mode
+const StreamNumberLocal = "stream_number"
+
 // Lookup table for pulling fields from the http request
 var startFieldAccessors fieldLoggers = fieldLoggers{
 	// Timestamp and request metadata
@@ -57,6 +62,12 @@ var startFieldAccessors fieldLoggers = fieldLoggers{
 	"qs": func(entry *HttpAccessLogEntry) string {
 		return entry.Context.Context().QueryArgs().String()
 	},
+	"stream_number": func(entry *HttpAccessLogEntry) string {
+		if number, ok := entry.Context.Locals(StreamNumberLocal).(uint64); ok {
+			return strconv.FormatUint(number, 10)
+		}
+		return ""
+	},
 	"dest_port": func(entry *HttpAccessLogEntry) string {
 		host := string(entry.Context.Request().Host())
 		_, port, err := net.SplitHostPort(host)
@@ -77,6 +88,12 @@ var startFieldAccessors fieldLoggers = fieldLoggers{
 	"host": func(entry *HttpAccessLogEntry) string {
 		return string(entry.Context.Request().Host())
 	},
+	"body": func(entry *HttpAccessLogEntry) string {
+		return entry.body
+	},
+	"body_size": func(entry *HttpAccessLogEntry) string {
+		return strconv.Itoa(len(entry.Context.Body()))
+	},
 
 	// Parameters derived from the User-Agent header
 	"user_agent": func(entry *HttpAccessLogEntry) string {
@@ -171,6 +188,7 @@ func NewHttpAccessLogger(cfg *config.Config, mainLogger *zap.Logger) (*HttpAcces
 		fieldsToLog: cfg.Server.AccessLog.FieldsToLog,
 		uaParser:    uaparser.NewFromSaved(),
 		loggingMode: cfg.Server.AccessLog.Mode,
+		maxBodySize: cfg.Server.AccessLog.MaxBodySize,
 	}, nil
 }
 
@@ -183,6 +201,7 @@ func (l *HttpAccessLogger) Start(ctx *fiber.Ctx) *HttpAccessLogEntry {
 	entry := &HttpAccessLogEntry{
 		Context: ctx,
 		phase:   "start",
+		body:    l.captureBody(ctx),
 	}
 
 	entry = l.resolveFields(startFieldAccessors, entry)
@@ -211,6 +230,16 @@ func (l *HttpAccessLogger) End(entry *HttpAccessLogEntry, duration time.Duration
 	return entry
 }
 
+// Copies the request body (up to the max body size) so it can be logged after the request has been handled
+func (l *HttpAccessLogger) captureBody(ctx *fiber.Ctx) string {
+	body := ctx.Body()
+	if l.maxBodySize > 0 && len(body) > l.maxBodySize {
+		body = body[:l.maxBodySize]
+	}
+
+	return string(body)
+}
+
 func (l *HttpAccessLogger) resolveFields(accessors fieldLoggers, entry *HttpAccessLogEntry) *HttpAccessLogEntry {
 
 	if entry.uaDetails == nil {
@@ -3,6 +3,8 @@ package http
 import (
 	"context"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,6 +12,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/generator/git"
 	"github.com/ryanolee/go-pot/protocol/http/logging"
 	"github.com/ryanolee/go-pot/protocol/http/stall"
 )
@@ -21,7 +24,12 @@ type (
 		ListenHost string
 		Logger     *zap.Logger
 
+		// Port to serve HTTPS on (0 if HTTPS is disabled)
+		TlsPort int
+
 		stallerFactory *stall.HttpStallerFactory
+		repository     *git.Repository
+		config         *config.Config
 	}
 )
 
@@ -31,6 +39,7 @@ func NewServer(
 	cfg *config.Config,
 	logging logging.IServerLogger,
 	stallerFactory *stall.HttpStallerFactory,
+	repository *git.Repository,
 ) *Server {
 	// Only enable the trusted proxy check if we have trusted proxies
 	trustedProxyCheck := len(cfg.Server.TrustedProxies) > 0
@@ -45,6 +54,7 @@ func NewServer(
 			ProxyHeader:             cfg.Server.ProxyHeader,
 			TrustedProxies:          cfg.Server.TrustedProxies,
 			EnableTrustedProxyCheck: trustedProxyCheck,
+			RequestMethods:          stall.SupportedMethods,
 			ErrorHandler: func(c *fiber.Ctx, err error) error {
 				// All is always ok even if we have an error. Just log it and return an empty response
 				zap.L().Error("Error in request", zap.Error(err))
@@ -56,6 +66,15 @@ func NewServer(
 		ListenHost: cfg.Server.Host,
 
 		stallerFactory: stallerFactory,
+		repository:     repository,
+		config:         cfg,
+	}
+
+	// Stallers take over connections once their request has been handled and close them once done
+	server.App.Server().KeepHijackedConns = true
+
+	if cfg.Server.Tls.Enabled {
+		server.TlsPort = cfg.Server.Tls.Port
 	}
 
 	lf.Append(fx.Hook{
@@ -69,12 +88,62 @@ func NewServer(
 }
 
 func (s *Server) Start() error {
-	// Setup routes
+	s.setupRoutes()
+
+	if s.TlsPort != 0 {
+		cert, err := getCertificate(s.config)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := s.App.ListenTLSWithCertificate(fmt.Sprintf("%s:%d", s.ListenHost, s.TlsPort), cert); err != nil {
+				zap.L().Fatal("Failed to start Https listener", zap.Error(err))
+			}
+		}()
+	}
+
+	return s.App.Listen(fmt.Sprintf("%s:%d", s.ListenHost, s.ListenPort))
+}
+
+// Serves plain HTTP on the given listener instead of the configured ports (i.e an in memory listener when benchmarking)
+func (s *Server) Serve(ln net.Listener) error {
+	s.setupRoutes()
+	return s.App.Listener(ln)
+}
+
+func (s *Server) setupRoutes() {
 	s.App.Get("/robots.txt", func(c *fiber.Ctx) error {
-		return c.SendString("User-agent: *\nDisallow: /")
+		staller, err := s.stallerFactory.RobotsTxtFromFiberContext(c)
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header.SetContentType(staller.GetContentType())
+
+		return staller.StallContextBuffer(c)
 	})
 
-	s.App.Get("/*", func(c *fiber.Ctx) error {
+	// Emulates an exposed .git directory. Paths that are not part of the fake repository are not found
+	s.App.Get("/.git/*", func(c *fiber.Ctx) error {
+		content, ok := s.repository.Get(c.Params("*"))
+		if !ok {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+
+		staller, err := s.stallerFactory.StaticFromFiberContext(c, content, fiber.MIMEOctetStream)
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header.SetContentType(staller.GetContentType())
+
+		return staller.StallContextBuffer(c)
+	})
+
+	s.App.All("/*", func(c *fiber.Ctx) error {
+		setMethodHeaders(c)
+
 		staller, err := s.stallerFactory.FromFiberContext(c)
 		if err != nil {
 			return err
@@ -85,6 +154,17 @@ func (s *Server) Start() error {
 
 		return staller.StallContextBuffer(c)
 	})
+}
 
-	return s.App.Listen(fmt.Sprintf("%s:%d", s.ListenHost, s.ListenPort))
+// Sets the status and headers expected for the method of the request
+func setMethodHeaders(c *fiber.Ctx) {
+	switch c.Method() {
+	case fiber.MethodPost, fiber.MethodPut:
+		c.Status(fiber.StatusCreated)
+	case stall.MethodPropfind:
+		c.Status(fiber.StatusMultiStatus)
+	case fiber.MethodOptions:
+		c.Set(fiber.HeaderAllow, strings.Join(stall.SupportedMethods, ", "))
+		c.Set("DAV", "1, 2")
+	}
 }
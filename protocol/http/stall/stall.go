@@ -1,64 +1,98 @@
 package stall
 
 import (
-	"bufio"
-	"context"
 	"errors"
-	"math"
+	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/ryanolee/go-pot/core/metrics"
 	"github.com/ryanolee/go-pot/core/stall"
 	"github.com/ryanolee/go-pot/generator"
-	"go.uber.org/zap"
 )
 
 const (
 	// Rate at which staller will report on wasted time to the given telemetry instance
 	StallerReportInterval = time.Second * 30
+
+	// How long a write may block a scheduler worker for. Writes only block once the socket buffer is full, which only
+	// happens to clients that have stopped reading. Anything not written in time is sent with the next chunk
+	StallerWriteTimeout = time.Millisecond * 10
+
+	// Clients are given up on once this much data is waiting on them to read it
+	StallerMaxUnflushed = 64 * 1024
+
+	// Least amount of data sent in a chunk. The size and line endings framing each chunk take up to 6 bytes
+	// so sending a byte at a time would send several times more than the pacer allows
+	StallerMinChunkSize = 16
+
+	// Longest a chunk is held back for while enough is allowed to be sent for it. Pacers sending a chunk
+	// just before clients time out (i.e keepalive) still send once this long has passed
+	StallerMaxBatchDelay = time.Second * 5
 )
 
+var errClientNotReading = errors.New("client is not reading the response")
+
 type (
-	// Represents a single open connection to the honeypot actively being stalled
+	// Represents a single open connection to the honeypot actively being stalled. Once the request has been
+	// handled the staller takes over the connection from fasthttp and the response is written by the scheduler
+	// so nothing beyond the staller itself is held while the connection is stalled
 	HttpStaller struct {
-		id           uint64
-		ipAddress    string
-		generator    generator.Generator
-		transferRate time.Duration
-		ticker       *time.Ticker
-		timeout      time.Duration
-		startTime    time.Time
-		endTime      time.Time
-		onTimeout    func(*HttpStaller)
-		onClose      func(*HttpStaller)
-		contentType  string
-
-		running     bool
-		runningLock sync.Mutex
+		id          uint64
+		ipAddress   string
+		generator   generator.Generator
+		pacer       stall.Pacer
+		scheduler   *stall.Scheduler
+		task        *stall.Task
+		timeout     time.Duration
+		startTime   time.Time
+		endTime     time.Time
+		lastReport  time.Time
+		onTimeout   func(*HttpStaller)
+		onClose     func(*HttpStaller)
+		contentType string
+
+		// Data waiting to be paced out to the client and how much of it is sent next. The budget is
+		// what the pacer has allowed to be sent so far including the framing of each chunk
+		pending   []byte
+		separator []byte
+		sendSize  int
+		budget    int
+		ending    bool
+
+		// Data written in part as the client is not keeping up
+		unflushed []byte
+
+		conn       net.Conn
+		connLock   sync.Mutex
+		tls        bool
+		closed     atomic.Bool
+		haltOnce   sync.Once
+		reportOnce sync.Once
 
 		deregisterChan chan stall.Staller
-
-		telemetryTicker *time.Ticker
-		telemetry       *metrics.Telemetry
+		telemetry      *metrics.Telemetry
 	}
 
 	HttpStallerOptions struct {
-		ContentType  string
-		Request      *fiber.Ctx
-		Generator    generator.Generator
-		TransferRate time.Duration
-		Timeout      time.Duration
-		OnTimeout    func(*HttpStaller)
-		OnClose      func(*HttpStaller)
-		Telemetry    *metrics.Telemetry
+		ContentType string
+		Request     *fiber.Ctx
+		Generator   generator.Generator
+		Pacer       stall.Pacer
+		Scheduler   *stall.Scheduler
+		Timeout     time.Duration
+		OnTimeout   func(*HttpStaller)
+		OnClose     func(*HttpStaller)
+		Telemetry   *metrics.Telemetry
 	}
 )
 
 func NewHttpStaller(opts *HttpStallerOptions) *HttpStaller {
-	if opts.TransferRate == 0 {
-		opts.TransferRate = time.Millisecond * 75
+	if opts.Pacer == nil {
+		opts.Pacer = stall.NewConstantPacer(stall.Pace{Interval: time.Millisecond * 75, ChunkSize: 1})
 	}
 
 	if opts.Timeout == 0 {
@@ -73,177 +107,235 @@ func NewHttpStaller(opts *HttpStallerOptions) *HttpStaller {
 		opts.OnTimeout = func(_ *HttpStaller) {}
 	}
 
-	return &HttpStaller{
-		runningLock:  sync.Mutex{},
-		running:      true,
-		contentType:  opts.ContentType,
-		generator:    opts.Generator,
-		transferRate: opts.TransferRate,
-		timeout:      opts.Timeout,
-		ipAddress:    opts.Request.IP(),
-		id:           opts.Request.Context().ConnID(),
-		onTimeout:    opts.OnTimeout,
-		onClose:      opts.OnClose,
-		telemetry:    opts.Telemetry,
+	staller := &HttpStaller{
+		contentType: opts.ContentType,
+		generator:   opts.Generator,
+		pacer:       opts.Pacer,
+		scheduler:   opts.Scheduler,
+		timeout:     opts.Timeout,
+		ipAddress:   opts.Request.IP(),
+		id:          opts.Request.Context().ConnID(),
+		tls:         opts.Request.Context().IsTLS(),
+		onTimeout:   opts.OnTimeout,
+		onClose:     opts.OnClose,
+		telemetry:   opts.Telemetry,
 	}
+	staller.task = stall.NewTask(staller.send)
+
+	return staller
 }
 
 func (s *HttpStaller) BindToPool(deregisterChan chan stall.Staller) {
 	s.deregisterChan = deregisterChan
 }
 
-// StallBuffer stalls the buffer by writing a chunk of data every N milliseconds
+// StallContextBuffer stalls the request by hijacking the connection once the handler returns.
+// The response headers and start of the body are sent straight away and the rest by the scheduler
 func (s *HttpStaller) StallContextBuffer(ctx *fiber.Ctx) error {
 	s.startTime = time.Now()
+	s.lastReport = s.startTime
+
+	fctx := ctx.Context()
+	if fctx.IsHead() {
+		// Nothing beyond the headers is sent in response to HEAD requests
+		s.handleClose()
+		s.Halt()
+		return nil
+	}
+
+	// The body is chunked as its length is unknown. The connection is closed once the body has been sent
+	fctx.Response.SetConnectionClose()
+	fctx.Response.Header.SetContentLength(-1)
+	s.unflushed = appendChunk(append([]byte(nil), fctx.Response.Header.Header()...), s.generator.Start())
+
+	fctx.HijackSetNoResponse(true)
+	fctx.Hijack(s.attach)
+	return nil
+}
+
+// Takes over the connection from fasthttp. Called from a goroutine of its own which returns once the start is sent.
+// The body is generated by the scheduler workers so only as many connections as there are workers generate at once
+func (s *HttpStaller) attach(conn net.Conn) {
+	s.connLock.Lock()
+	s.conn = conn
+	s.connLock.Unlock()
+
+	if s.closed.Load() {
+		conn.Close()
+		return
+	}
 
-	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
-		s.ticker = time.NewTicker(s.transferRate)
-		s.telemetryTicker = time.NewTicker(StallerReportInterval)
-		logger := zap.L().Sugar()
+	if err := s.flush(); err != nil {
+		s.abort()
+		return
+	}
 
-		closeContext := context.Background()
-		closeContext, cancelContext := context.WithTimeout(closeContext, s.timeout)
+	s.scheduler.Schedule(s.task, 0)
+}
 
-		err := s.writeDataToClient(w, s.generator.Start())
-		if err != nil {
-			logger.Error("Failed first write!", "connId", s.id, "err", err)
-			s.handleTimeout()
-			s.Halt()
-			cancelContext()
+// Run by the scheduler once the next chunk is due
+func (s *HttpStaller) send() {
+	if s.closed.Load() {
+		return
+	}
+
+	if s.ending {
+		s.end()
+		return
+	}
+
+	n := min(s.sendSize, len(s.pending))
+	s.unflushed = appendChunk(s.unflushed, s.pending[:n])
+	s.pending = s.pending[n:]
+	if n > 0 {
+		s.budget = max(s.budget-n-chunkFraming(n), 0)
+	}
+	if err := s.flush(); err != nil {
+		s.abort()
+		return
+	}
+
+	s.reportWastedTime()
+	s.scheduleNext()
+}
+
+// Schedules the next send at the pace given by the pacer. Once the timeout is up the rest of the data is flushed along with the end
+func (s *HttpStaller) scheduleNext() {
+	if len(s.pending) == 0 && len(s.separator) > 0 {
+		s.pending, s.separator = s.separator, nil
+	} else if len(s.pending) == 0 {
+		// Generators with a fixed amount of content are done once everything has been sent
+		if finite, ok := s.generator.(generator.FiniteGenerator); ok && finite.Done() {
+			s.end()
 			return
 		}
 
-		for {
-			if errors.Is(closeContext.Err(), context.DeadlineExceeded) {
-				// Flush the rest of the data to the client in the case we are closing
-				_, err := w.Write(s.generator.End())
-				if err != nil {
-					logger.Warn("Failed to write end!", "connId", s.id, "err", err)
-				}
-
-				w.Flush()
-				s.Halt()
-				s.handleClose()
-				cancelContext()
-				return
-			}
-
-			data := s.generator.GenerateChunk()
-
-			continueWriting, _ := s.PushDataToClient(closeContext, w, data)
-
-			if !continueWriting {
-				time.Sleep(time.Millisecond * 500)
-				s.Halt()
-				cancelContext()
-				return
-			}
-
-			if _, err := s.PushDataToClient(closeContext, w, s.generator.ChunkSeparator()); err != nil {
-				logger.Warn("Failed to write chunk separator!", "connId", s.id, "err", err)
-				s.Halt()
-				cancelContext()
-				return
-			}
+		// The separator is kept apart from the chunk rather than appended so the chunk is not copied
+		s.pending, s.separator = s.generator.GenerateChunk(), s.generator.ChunkSeparator()
+		if len(s.pending) == 0 {
+			s.pending, s.separator = s.separator, nil
 		}
-	})
-	return nil
-}
+	}
 
-func (s *HttpStaller) PushDataToClient(ctx context.Context, w *bufio.Writer, data []byte) (bool, error) {
-	var dataToWrite []byte
-	for i := 0; i < len(data); i++ {
-		select {
-		case <-s.ticker.C:
-			if !s.running {
-				return false, nil
-			}
-
-			if string(data[i:i+1]) == "\\n" {
-				dataToWrite = []byte("\\n")
-				i++
-			} else {
-				dataToWrite = []byte{data[i]}
-			}
-
-			if err := s.writeDataToClient(w, dataToWrite); err != nil {
-				s.handleTimeout()
-				return false, err
-			}
-
-		case <-s.telemetryTicker.C:
-			if s.telemetry == nil {
-				continue
-			}
-
-			s.telemetry.TrackWastedTime(StallerReportInterval)
-		case <-ctx.Done():
-			// Flush the rest of the data to the client in the case we are closing
-			if _, err := w.Write(data[i:]); err != nil {
-				zap.L().Sugar().Warn("Failed rest if data", "connId", s.id, "err", err)
-
-			}
-			w.Flush()
-
-			if _, err := w.Write(s.generator.End()); err != nil {
-				zap.L().Sugar().Warn("Failed to write end of data", "connId", s.id, "err", err)
-			}
-			w.Flush()
-
-			s.handleClose()
-			return false, nil
-		}
+	// The framing of each chunk is counted against what the pacer allows. Chunks are held back until
+	// enough has been allowed for a few bytes of data to go with the framing or everything pending to be sent
+	want := min(len(s.pending), StallerMinChunkSize)
+	want += chunkFraming(want)
+	var delay time.Duration
+	for s.budget < want && delay < StallerMaxBatchDelay {
+		nextDelay, size := s.scheduler.NextSend(s.pacer, want-s.budget)
+		delay += nextDelay
+		s.budget += size
+	}
+
+	if remaining := s.timeout - time.Since(s.startTime); delay >= remaining {
+		delay = remaining
+		s.ending = true
 	}
 
-	return true, nil
+	s.sendSize = max(sendableBytes(s.budget), 1)
+	s.scheduler.Schedule(s.task, delay)
 }
 
-func (s *HttpStaller) Halt() {
-	if s.ticker != nil {
-		s.ticker.Stop()
+// Sends the rest of the data and the end of the stream then closes the connection
+func (s *HttpStaller) end() {
+	s.unflushed = appendChunk(appendChunk(appendChunk(s.unflushed, s.pending), s.separator), s.generator.End())
+	s.unflushed = append(s.unflushed, "0\r\n\r\n"...)
+	s.pending, s.separator = nil, nil
+	if err := s.flush(); err != nil {
+		s.abort()
+		return
 	}
 
-	if s.telemetryTicker != nil {
-		s.telemetryTicker.Stop()
-	}
-	s.deregisterChan <- s
-	s.Close()
+	s.handleClose()
+	s.Halt()
 }
 
-func (s *HttpStaller) writeDataToClient(w *bufio.Writer, dataToWrite []byte) error {
-	if _, err := w.Write(dataToWrite); err != nil {
+// Writes as much of the unflushed data as the client takes without blocking for long. Whatever
+// is left is sent with the next chunk unless too much has built up for the client to be reading
+func (s *HttpStaller) flush() error {
+	if len(s.unflushed) == 0 {
+		return nil
+	}
+
+	if err := s.conn.SetWriteDeadline(time.Now().Add(StallerWriteTimeout)); err != nil {
 		return err
 	}
 
-	if err := w.Flush(); err != nil {
+	n, err := s.conn.Write(s.unflushed)
+	s.unflushed = s.unflushed[:copy(s.unflushed, s.unflushed[n:])]
+	if len(s.unflushed) == 0 {
+		// Nothing is held on to between sends once the client has everything
+		s.unflushed = nil
+	}
+
+	var timeoutErr interface{ Timeout() bool }
+	if err != nil && !(errors.As(err, &timeoutErr) && timeoutErr.Timeout()) {
 		return err
 	}
 
+	// TLS connections can not be written to again once a write has timed out so the client is given up on
+	if err != nil && s.tls {
+		return errClientNotReading
+	}
+
+	if len(s.unflushed) > StallerMaxUnflushed {
+		return errClientNotReading
+	}
+
 	return nil
 }
 
-func (s *HttpStaller) handleTimeout() {
-	s.endTime = time.Now()
-	go s.onTimeout(s)
-	if s.telemetry != nil {
-		s.telemetry.TrackWastedTime(s.GetRemainingTimeToReport())
+// Called once the client has given up on the response. Writes failing as the staller has been closed are not reported
+func (s *HttpStaller) abort() {
+	if s.closed.Load() {
+		return
 	}
+
+	s.handleTimeout()
+	s.Halt()
 }
 
-func (s *HttpStaller) handleClose() {
-	s.endTime = time.Now()
-	go s.onClose(s)
-	if s.telemetry != nil {
-		s.telemetry.TrackWastedTime(s.GetRemainingTimeToReport())
+func (s *HttpStaller) Halt() {
+	s.haltOnce.Do(func() {
+		if s.deregisterChan != nil {
+			s.deregisterChan <- s
+		}
+
+		s.Close()
+	})
+}
+
+func (s *HttpStaller) reportWastedTime() {
+	if s.telemetry == nil || time.Since(s.lastReport) < StallerReportInterval {
+		return
 	}
+
+	s.telemetry.TrackWastedTime(StallerReportInterval)
+	s.lastReport = s.lastReport.Add(StallerReportInterval)
 }
 
-func (s *HttpStaller) GetElapsedTime() time.Duration {
-	return s.endTime.Sub(s.startTime)
+func (s *HttpStaller) handleTimeout() {
+	s.report(s.onTimeout)
+}
+
+func (s *HttpStaller) handleClose() {
+	s.report(s.onClose)
+}
+
+// Reports the end of the stall. Only the first of the client giving up or the staller closing the stream is reported
+func (s *HttpStaller) report(callback func(*HttpStaller)) {
+	s.reportOnce.Do(func() {
+		s.endTime = time.Now()
+		go callback(s)
+		if s.telemetry != nil {
+			s.telemetry.TrackWastedTime(s.endTime.Sub(s.lastReport))
+		}
+	})
 }
 
-func (s *HttpStaller) GetRemainingTimeToReport() time.Duration {
-	return time.Duration(math.Mod(float64(s.GetElapsedTime()), float64(StallerReportInterval)))
+func (s *HttpStaller) GetElapsedTime() time.Duration {
+	return s.endTime.Sub(s.startTime)
 }
 
 func (s *HttpStaller) GetContentType() string {
@@ -251,7 +343,17 @@ func (s *HttpStaller) GetContentType() string {
 }
 
 func (s *HttpStaller) Close() {
-	s.setRunning(false)
+	if s.closed.Swap(true) {
+		return
+	}
+
+	s.scheduler.Cancel(s.task)
+
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
 }
 
 func (s *HttpStaller) GetGroupIdentifier() string {
@@ -262,8 +364,29 @@ func (s *HttpStaller) GetIdentifier() uint64 {
 	return s.id
 }
 
-func (s *HttpStaller) setRunning(running bool) {
-	s.runningLock.Lock()
-	defer s.runningLock.Unlock()
-	s.running = running
+// Bytes taken up by the size and line endings framing a chunk of n bytes
+func chunkFraming(n int) int {
+	return len(strconv.FormatInt(int64(n), 16)) + 4
+}
+
+// Most data that can be sent in a single chunk with the given budget
+func sendableBytes(budget int) int {
+	n := budget - chunkFraming(budget)
+	for n+1+chunkFraming(n+1) <= budget {
+		n++
+	}
+
+	return max(n, 0)
+}
+
+// Appends the data as a single chunk of a chunked response body
+func appendChunk(dst []byte, data []byte) []byte {
+	if len(data) == 0 {
+		return dst
+	}
+
+	dst = strconv.AppendInt(dst, int64(len(data)), 16)
+	dst = append(dst, "\r\n"...)
+	dst = append(dst, data...)
+	return append(dst, "\r\n"...)
 }
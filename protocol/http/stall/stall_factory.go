@@ -9,10 +9,27 @@ import (
 	"github.com/ryanolee/go-pot/core/stall"
 	"github.com/ryanolee/go-pot/generator"
 	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/generator/source"
 	"github.com/ryanolee/go-pot/protocol/http/logging"
 	"github.com/ryanolee/go-pot/secrets"
 )
 
+// WebDAV methods not known to fiber by default
+const (
+	MethodPropfind  = "PROPFIND"
+	MethodProppatch = "PROPPATCH"
+	MethodMkcol     = "MKCOL"
+	MethodCopy      = "COPY"
+	MethodMove      = "MOVE"
+	MethodLock      = "LOCK"
+	MethodUnlock    = "UNLOCK"
+)
+
+// Every method requests are accepted for
+var SupportedMethods = append(append([]string{}, fiber.DefaultMethods...),
+	MethodPropfind, MethodProppatch, MethodMkcol, MethodCopy, MethodMove, MethodLock, MethodUnlock,
+)
+
 type HttpStallerFactory struct {
 	// Services
 	pool              *stall.StallerPool
@@ -20,12 +37,17 @@ type HttpStallerFactory struct {
 	timeoutWatcher    *metrics.TimeoutWatcher
 	secretsGenerators *secrets.SecretGeneratorCollection
 	configGenerators  *generator.ConfigGeneratorCollection
+	tabularSchemas    *source.TabularSchemaCollection
+	streams           *generator.StreamSourceFactory
+	pacers            *stall.PacerFactory
+	scheduler         *stall.Scheduler
 
 	// Logger
 	logger *logging.HttpAccessLogger
 
 	// Config
 	bytesPerSecond int
+	longestTimeout time.Duration
 }
 
 func NewHttpStallerFactory(
@@ -35,6 +57,10 @@ func NewHttpStallerFactory(
 	telemetry *metrics.Telemetry,
 	secretsGeneratorCollection *secrets.SecretGeneratorCollection,
 	configGeneratorCollection *generator.ConfigGeneratorCollection,
+	tabularSchemas *source.TabularSchemaCollection,
+	streams *generator.StreamSourceFactory,
+	pacers *stall.PacerFactory,
+	scheduler *stall.Scheduler,
 	logger *logging.HttpAccessLogger,
 ) *HttpStallerFactory {
 	return &HttpStallerFactory{
@@ -43,32 +69,69 @@ func NewHttpStallerFactory(
 		timeoutWatcher:    timeoutWatcher,
 		secretsGenerators: secretsGeneratorCollection,
 		configGenerators:  configGeneratorCollection,
+		tabularSchemas:    tabularSchemas,
+		streams:           streams,
+		pacers:            pacers,
+		scheduler:         scheduler,
 		logger:            logger,
 
 		bytesPerSecond: config.Staller.BytesPerSecond,
+		longestTimeout: time.Duration(config.TimeoutWatcher.LongestTimeout) * time.Millisecond,
 	}
 }
 
 func (f *HttpStallerFactory) FromFiberContext(c *fiber.Ctx) (*HttpStaller, error) {
-	entry := f.logger.Start(c)
+	gen, contentType := f.getGeneratorForRequest(c)
+	return f.newStaller(c, gen, contentType)
+}
+
+// Creates a staller streaming an endless robots.txt. Every path listed is served by the normal staller
+func (f *HttpStallerFactory) RobotsTxtFromFiberContext(c *fiber.Ctx) (*HttpStaller, error) {
+	stream, key := f.streams.Next("http", c.IP(), c.Path())
+	if key != nil {
+		c.Locals(logging.StreamNumberLocal, key.Number)
+	}
+
+	gen, err := generator.NewRobotsTxtGenerator(stream.Rand)
+	if err != nil {
+		return nil, err
+	}
 
-	encoderInstance := encoder.GetEncoderForPath(c.Path())
-	gen := generator.GetGeneratorForEncoder(encoderInstance, f.configGenerators, f.secretsGenerators)
+	return f.newStaller(c, gen, "text/plain; charset=utf-8")
+}
+
+// Creates a staller slowly sending a fixed piece of content before closing the request
+func (f *HttpStallerFactory) StaticFromFiberContext(c *fiber.Ctx, data []byte, contentType string) (*HttpStaller, error) {
+	return f.newStaller(c, generator.NewStaticGenerator(data), contentType)
+}
+
+func (f *HttpStallerFactory) newStaller(c *fiber.Ctx, gen generator.Generator, contentType string) (*HttpStaller, error) {
+	entry := f.logger.Start(c)
 	ip := c.IP()
 	identifier := "http-" + ip
+	pacer := f.pacers.ForClient(stall.PacerClient{
+		Protocol:  "http",
+		Path:      c.Path(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}, stall.Pace{
+		Interval:  time.Second / time.Duration(f.bytesPerSecond),
+		ChunkSize: 1,
+	})
+
 	opts := &HttpStallerOptions{
-		Request:      c,
-		Generator:    gen,
-		TransferRate: time.Second / time.Duration(f.bytesPerSecond),
-		Timeout:      f.timeoutWatcher.GetTimeout(identifier),
-		ContentType:  encoderInstance.ContentType(),
+		Request:     c,
+		Generator:   gen,
+		Pacer:       pacer,
+		Scheduler:   f.scheduler,
+		Timeout:     f.getTimeout(identifier),
+		ContentType: contentType,
 		OnTimeout: func(stl *HttpStaller) {
 			f.logger.End(entry, stl.GetElapsedTime())
-			f.timeoutWatcher.RecordResponse(identifier, stl.GetElapsedTime(), false)
+			f.recordResponse(identifier, stl.GetElapsedTime(), false)
 		},
 		OnClose: func(stl *HttpStaller) {
 			f.logger.End(entry, stl.GetElapsedTime())
-			f.timeoutWatcher.RecordResponse(identifier, stl.GetElapsedTime(), true)
+			f.recordResponse(identifier, stl.GetElapsedTime(), true)
 		},
 		Telemetry: f.telemetry,
 	}
@@ -79,3 +142,59 @@ func (f *HttpStallerFactory) FromFiberContext(c *fiber.Ctx) (*HttpStaller, error
 
 	return staller, nil
 }
+
+func (f *HttpStallerFactory) getTimeout(identifier string) time.Duration {
+	// With the timeout watcher disabled every request is stalled for as long as possible
+	if f.timeoutWatcher == nil {
+		return f.longestTimeout
+	}
+
+	return f.timeoutWatcher.GetTimeout(identifier)
+}
+
+func (f *HttpStallerFactory) recordResponse(identifier string, elapsed time.Duration, successful bool) {
+	if f.timeoutWatcher == nil {
+		return
+	}
+
+	f.timeoutWatcher.RecordResponse(identifier, elapsed, successful)
+}
+
+// Gets the stream the response is generated from and picks the generator for the request
+func (f *HttpStallerFactory) getGeneratorForRequest(c *fiber.Ctx) (generator.Generator, string) {
+	stream, key := f.streams.Next("http", c.IP(), c.Path())
+	if key != nil {
+		c.Locals(logging.StreamNumberLocal, key.Number)
+	}
+
+	secretsGenerators := f.secretsGenerators.ForClient(secrets.HoneytokenClient{
+		Protocol: "http",
+		Ip:       c.IP(),
+		Path:     c.Path(),
+	})
+
+	return GetGeneratorForRequest(c.Method(), c.Path(), len(c.Body()), f.configGenerators, f.tabularSchemas, secretsGenerators, stream)
+}
+
+// Picks the shape of the response based on the method of the request. Requests sending data
+// are acknowledged with JSON and WebDAV requests are answered with XML. Everything else is
+// answered based on the requested path
+func GetGeneratorForRequest(method string, path string, bodySize int, configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretsGenerators *secrets.SecretGeneratorCollection, stream *generator.StreamSource) (generator.Generator, string) {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		encoderInstance := encoder.NewJsonEncoder()
+		gen := generator.GetGeneratorForEncoder(encoderInstance, configGenerators, tabularSchemas, secretsGenerators, stream)
+		return generator.NewAcknowledgementGenerator(gen, "ok", bodySize, stream), encoderInstance.ContentType()
+	case MethodPropfind:
+		encoderInstance := encoder.NewXmlEncoder()
+		return generator.GetGeneratorForEncoder(encoderInstance, configGenerators, tabularSchemas, secretsGenerators, stream), encoderInstance.ContentType()
+	}
+
+	return GetGeneratorForUrlPath(path, configGenerators, tabularSchemas, secretsGenerators, stream)
+}
+
+// Creates the generator and content type requests for the path are answered with
+func GetGeneratorForUrlPath(path string, configGenerators *generator.ConfigGeneratorCollection, tabularSchemas *source.TabularSchemaCollection, secretsGenerators *secrets.SecretGeneratorCollection, stream *generator.StreamSource) (generator.Generator, string) {
+	encoderInstance := encoder.GetEncoderForUrlPath(path)
+	return generator.GetGeneratorForPath(path, encoderInstance, configGenerators, tabularSchemas, secretsGenerators, stream), encoderInstance.ContentType()
+}
@@ -0,0 +1,114 @@
+package stall
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type (
+	// A connection accepting at most the given number of bytes per write and timing out on the rest
+	slowConn struct {
+		net.Conn
+		accept  int
+		written bytes.Buffer
+	}
+)
+
+func (c *slowConn) Write(data []byte) (int, error) {
+	n := min(c.accept, len(data))
+	c.written.Write(data[:n])
+	if n < len(data) {
+		return n, os.ErrDeadlineExceeded
+	}
+
+	return n, nil
+}
+
+func (c *slowConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func TestChunkFraming(t *testing.T) {
+	for _, n := range []int{0, 1, 15, 16, 255, 256, 4095, 4096} {
+		chunk := appendChunk(nil, bytes.Repeat([]byte{'a'}, n))
+		if n == 0 {
+			continue
+		}
+
+		if framing := len(chunk) - n; framing != chunkFraming(n) {
+			t.Errorf("expected framing of %d for %d bytes got %d", framing, n, chunkFraming(n))
+		}
+	}
+}
+
+// The most that fits in a budget is sent. A single byte more would go over it
+func TestSendableBytes(t *testing.T) {
+	for budget := 0; budget < 70000; budget++ {
+		n := sendableBytes(budget)
+		if n < 0 {
+			t.Fatalf("expected no negative sizes got %d for a budget of %d", n, budget)
+		}
+
+		if n > 0 && n+chunkFraming(n) > budget {
+			t.Fatalf("chunk of %d bytes goes over a budget of %d", n, budget)
+		}
+
+		if next := n + 1; next+chunkFraming(next) <= budget {
+			t.Fatalf("chunk of %d bytes fits a budget of %d but only %d were sent", next, budget, n)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	tests := []struct {
+		name          string
+		tls           bool
+		accept        int
+		unflushed     int
+		wantErr       error
+		wantRemaining int
+	}{
+		{name: "everything written", accept: 100, unflushed: 10},
+		{name: "partial write carried over", accept: 4, unflushed: 10, wantRemaining: 6},
+		{name: "partial write over tls", tls: true, accept: 4, unflushed: 10, wantErr: errClientNotReading},
+		{name: "everything written over tls", tls: true, accept: 100, unflushed: 10},
+		{name: "too much carried over", accept: 0, unflushed: StallerMaxUnflushed + 1, wantErr: errClientNotReading},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn := &slowConn{accept: test.accept}
+			staller := &HttpStaller{
+				conn:      conn,
+				tls:       test.tls,
+				unflushed: bytes.Repeat([]byte{'a'}, test.unflushed),
+			}
+
+			if err := staller.flush(); !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantErr == nil && len(staller.unflushed) != test.wantRemaining {
+				t.Errorf("expected %d bytes to be carried over got %d", test.wantRemaining, len(staller.unflushed))
+			}
+
+			if conn.written.Len()+len(staller.unflushed) != test.unflushed && test.wantErr == nil {
+				t.Errorf("expected nothing to be lost got %d written and %d carried over", conn.written.Len(), len(staller.unflushed))
+			}
+		})
+	}
+}
+
+// Chunks written one after another form a valid chunked body
+func TestAppendChunk(t *testing.T) {
+	body := appendChunk(appendChunk(appendChunk(nil, []byte("hello")), nil), bytes.Repeat([]byte{'b'}, 20))
+	want := "5\r\nhello\r\n" + strconv.FormatInt(20, 16) + "\r\n" + string(bytes.Repeat([]byte{'b'}, 20)) + "\r\n"
+	if string(body) != want {
+		t.Errorf("expected %q got %q", want, body)
+	}
+}
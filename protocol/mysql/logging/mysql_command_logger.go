@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+)
+
+type (
+	MysqlCommandLogger struct {
+		*logging.ConnCommandLogger
+	}
+)
+
+// Commands that are not included in the "all" command group
+// but are verbose enough to be included in the "all_detailed" group
+var overlyVerboseCommands = []string{
+	"ping",
+}
+
+func NewMysqlCommandLogger(config *config.Config) (*MysqlCommandLogger, error) {
+	logger, err := logging.NewConnCommandLogger(&logging.ConnCommandLoggerOptions{
+		Protocol:         "mysql",
+		Path:             config.MysqlServer.CommandLog.Path,
+		FallbackPath:     config.Logging.Path,
+		CommandsToLog:    config.MysqlServer.CommandLog.CommandsToLog,
+		VerboseCommands:  overlyVerboseCommands,
+		AdditionalFields: config.MysqlServer.CommandLog.AdditionalFields,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MysqlCommandLogger{logger}, nil
+}
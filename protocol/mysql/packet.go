@@ -0,0 +1,233 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Capability flags (https://dev.mysql.com/doc/dev/mysql-server/latest/group__group__cs__capabilities__flags.html)
+const (
+	clientLongPassword               uint32 = 0x00000001
+	clientFoundRows                  uint32 = 0x00000002
+	clientLongFlag                   uint32 = 0x00000004
+	clientConnectWithDb              uint32 = 0x00000008
+	clientProtocol41                 uint32 = 0x00000200
+	clientTransactions               uint32 = 0x00002000
+	clientSecureConnection           uint32 = 0x00008000
+	clientMultiStatements            uint32 = 0x00010000
+	clientMultiResults               uint32 = 0x00020000
+	clientPluginAuth                 uint32 = 0x00080000
+	clientPluginAuthLenencClientData uint32 = 0x00200000
+)
+
+// Capabilities advertised by the server. SSL and CLIENT_DEPRECATE_EOF are left out on purpose
+// so clients stay in plain text and result sets are terminated by classic EOF packets
+const serverCapabilities = clientLongPassword | clientFoundRows | clientLongFlag | clientConnectWithDb |
+	clientProtocol41 | clientTransactions | clientSecureConnection | clientMultiStatements |
+	clientMultiResults | clientPluginAuth | clientPluginAuthLenencClientData
+
+const (
+	comQuit        byte = 0x01
+	comInitDb      byte = 0x02
+	comQuery       byte = 0x03
+	comFieldList   byte = 0x04
+	comPing        byte = 0x0e
+	comStmtPrepare byte = 0x16
+)
+
+const (
+	// utf8mb4_general_ci
+	charsetUtf8mb4 byte = 45
+
+	statusAutocommit uint16 = 0x0002
+
+	columnTypeVarString byte = 0xfd
+
+	nativePasswordPlugin = "mysql_native_password"
+
+	// Packets larger than this are not accepted from clients
+	maxPacketLength = 1024 * 1024
+)
+
+var (
+	errPacketTooLarge      = errors.New("packet too large")
+	errAuthResponseTooLong = errors.New("auth response longer than the handshake response")
+)
+
+type (
+	// Builds up the payload of a single packet
+	packetBuilder struct {
+		bytes.Buffer
+	}
+
+	// The parts of the HandshakeResponse41 packet that are of interest
+	handshakeResponse struct {
+		capabilities uint32
+		user         string
+		authResponse []byte
+		database     string
+		authPlugin   string
+	}
+)
+
+// Reads a single packet returning its sequence id and payload
+func readPacket(reader io.Reader) (byte, []byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	if length > maxPacketLength {
+		return 0, nil, errPacketTooLarge
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, nil, err
+	}
+
+	return header[3], payload, nil
+}
+
+// Frames a payload as a packet with the given sequence id
+func framePacket(sequence byte, payload []byte) []byte {
+	length := len(payload)
+	return append([]byte{byte(length), byte(length >> 8), byte(length >> 16), sequence}, payload...)
+}
+
+func (b *packetBuilder) int1(value byte) *packetBuilder {
+	b.WriteByte(value)
+	return b
+}
+
+func (b *packetBuilder) int2(value uint16) *packetBuilder {
+	b.Write(binary.LittleEndian.AppendUint16(nil, value))
+	return b
+}
+
+func (b *packetBuilder) int4(value uint32) *packetBuilder {
+	b.Write(binary.LittleEndian.AppendUint32(nil, value))
+	return b
+}
+
+func (b *packetBuilder) lenencInt(value uint64) *packetBuilder {
+	switch {
+	case value < 251:
+		b.WriteByte(byte(value))
+	case value < 1<<16:
+		b.WriteByte(0xfc)
+		b.int2(uint16(value))
+	case value < 1<<24:
+		b.WriteByte(0xfd)
+		b.Write([]byte{byte(value), byte(value >> 8), byte(value >> 16)})
+	default:
+		b.WriteByte(0xfe)
+		b.Write(binary.LittleEndian.AppendUint64(nil, value))
+	}
+	return b
+}
+
+func (b *packetBuilder) lenencString(value string) *packetBuilder {
+	b.lenencInt(uint64(len(value)))
+	b.WriteString(value)
+	return b
+}
+
+func (b *packetBuilder) nulString(value string) *packetBuilder {
+	b.WriteString(value)
+	b.WriteByte(0)
+	return b
+}
+
+func (b *packetBuilder) raw(value []byte) *packetBuilder {
+	b.Write(value)
+	return b
+}
+
+// Reads the parts of a HandshakeResponse41 packet that are of interest. Malformed packets
+// are read as far as possible
+func parseHandshakeResponse(payload []byte) (*handshakeResponse, error) {
+	if len(payload) < 32 {
+		return nil, errors.New("handshake response too short")
+	}
+
+	response := &handshakeResponse{
+		capabilities: binary.LittleEndian.Uint32(payload[0:4]),
+	}
+
+	// Skip over capabilities (4), max packet size (4), character set (1) and filler (23)
+	rest := payload[32:]
+	response.user, rest = readNulString(rest)
+
+	switch {
+	case response.capabilities&clientPluginAuthLenencClientData != 0:
+		// The length is compared before converting it as lengths from the client can overflow an int
+		length, remaining := readLenencInt(rest)
+		if length > uint64(len(remaining)) {
+			return nil, errAuthResponseTooLong
+		}
+		response.authResponse, rest = readFixed(remaining, int(length))
+	case response.capabilities&clientSecureConnection != 0 && len(rest) > 0:
+		response.authResponse, rest = readFixed(rest[1:], int(rest[0]))
+	default:
+		var authResponse string
+		authResponse, rest = readNulString(rest)
+		response.authResponse = []byte(authResponse)
+	}
+
+	if response.capabilities&clientConnectWithDb != 0 {
+		response.database, rest = readNulString(rest)
+	}
+
+	if response.capabilities&clientPluginAuth != 0 {
+		response.authPlugin, _ = readNulString(rest)
+	}
+
+	return response, nil
+}
+
+func readNulString(data []byte) (string, []byte) {
+	end := bytes.IndexByte(data, 0)
+	if end == -1 {
+		return string(data), nil
+	}
+
+	return string(data[:end]), data[end+1:]
+}
+
+func readFixed(data []byte, length int) ([]byte, []byte) {
+	if length < 0 || length > len(data) {
+		length = len(data)
+	}
+
+	return data[:length], data[length:]
+}
+
+func readLenencInt(data []byte) (uint64, []byte) {
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	switch data[0] {
+	case 0xfc:
+		if len(data) < 3 {
+			return 0, nil
+		}
+		return uint64(binary.LittleEndian.Uint16(data[1:3])), data[3:]
+	case 0xfd:
+		if len(data) < 4 {
+			return 0, nil
+		}
+		return uint64(data[1]) | uint64(data[2])<<8 | uint64(data[3])<<16, data[4:]
+	case 0xfe:
+		if len(data) < 9 {
+			return 0, nil
+		}
+		return binary.LittleEndian.Uint64(data[1:9]), data[9:]
+	}
+
+	return uint64(data[0]), data[1:]
+}
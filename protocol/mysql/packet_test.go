@@ -0,0 +1,150 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// Builds a HandshakeResponse41 packet with the given capabilities followed by the rest of the payload
+func handshakePayload(capabilities uint32, rest ...[]byte) []byte {
+	payload := binary.LittleEndian.AppendUint32(nil, capabilities)
+	payload = append(payload, make([]byte, 28)...)
+	for _, part := range rest {
+		payload = append(payload, part...)
+	}
+
+	return payload
+}
+
+func TestReadPacket(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []byte
+		wantPayload []byte
+		wantErr     error
+	}{
+		{name: "query", input: framePacket(0, []byte{comQuery, 's'}), wantPayload: []byte{comQuery, 's'}},
+		{name: "empty payload", input: framePacket(0, nil), wantPayload: []byte{}},
+		{name: "empty input", input: []byte{}, wantErr: io.EOF},
+		{name: "truncated header", input: []byte{1, 0}, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated payload", input: []byte{10, 0, 0, 0, comQuery}, wantErr: io.ErrUnexpectedEOF},
+		{name: "oversized length", input: []byte{0xff, 0xff, 0xff, 0}, wantErr: errPacketTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, payload, err := readPacket(bytes.NewReader(test.input))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantErr == nil && !bytes.Equal(payload, test.wantPayload) {
+				t.Errorf("expected payload %q got %q", test.wantPayload, payload)
+			}
+		})
+	}
+}
+
+func TestParseHandshakeResponse(t *testing.T) {
+	lenencHuge := append([]byte{0xfe}, binary.LittleEndian.AppendUint64(nil, 0x8000000000000001)...)
+
+	tests := []struct {
+		name             string
+		payload          []byte
+		wantUser         string
+		wantAuthResponse []byte
+		wantDatabase     string
+		wantPlugin       string
+		wantErr          bool
+	}{
+		{
+			name:             "lenenc auth response",
+			payload:          handshakePayload(clientProtocol41|clientPluginAuthLenencClientData|clientConnectWithDb|clientPluginAuth, []byte("root\x00"), []byte{2, 'a', 'b'}, []byte("db\x00"), []byte(nativePasswordPlugin+"\x00")),
+			wantUser:         "root",
+			wantAuthResponse: []byte("ab"),
+			wantDatabase:     "db",
+			wantPlugin:       nativePasswordPlugin,
+		},
+		{
+			name:             "secure connection auth response",
+			payload:          handshakePayload(clientProtocol41|clientSecureConnection, []byte("root\x00"), []byte{2, 'a', 'b'}),
+			wantUser:         "root",
+			wantAuthResponse: []byte("ab"),
+		},
+		{
+			name:             "nul terminated auth response",
+			payload:          handshakePayload(clientProtocol41, []byte("root\x00"), []byte("pass\x00")),
+			wantUser:         "root",
+			wantAuthResponse: []byte("pass"),
+		},
+		{
+			name:             "secure connection auth response longer than the packet",
+			payload:          handshakePayload(clientProtocol41|clientSecureConnection, []byte("root\x00"), []byte{200, 'a'}),
+			wantUser:         "root",
+			wantAuthResponse: []byte("a"),
+		},
+		{
+			name:    "lenenc auth response longer than the packet",
+			payload: handshakePayload(clientProtocol41|clientPluginAuthLenencClientData, []byte("root\x00"), []byte{0xfc, 0xff, 0xff, 'a'}),
+			wantErr: true,
+		},
+		{
+			name:    "lenenc auth response length overflowing an int",
+			payload: handshakePayload(clientProtocol41|clientPluginAuthLenencClientData, []byte("root\x00"), lenencHuge),
+			wantErr: true,
+		},
+		{
+			name:     "truncated lenenc auth response length",
+			payload:  handshakePayload(clientProtocol41|clientPluginAuthLenencClientData, []byte("root\x00"), []byte{0xfe, 1}),
+			wantUser: "root",
+		},
+		{
+			name:     "unterminated user",
+			payload:  handshakePayload(clientProtocol41|clientConnectWithDb|clientPluginAuth, []byte("root")),
+			wantUser: "root",
+		},
+		{
+			name:    "too short",
+			payload: make([]byte, 31),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response, err := parseHandshakeResponse(test.payload)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %t got %v", test.wantErr, err)
+			}
+
+			if test.wantErr {
+				return
+			}
+
+			if response.user != test.wantUser || !bytes.Equal(response.authResponse, test.wantAuthResponse) ||
+				response.database != test.wantDatabase || response.authPlugin != test.wantPlugin {
+				t.Errorf("unexpected response %+v", response)
+			}
+		})
+	}
+}
+
+func TestLenencIntRoundTrip(t *testing.T) {
+	for _, value := range []uint64{0, 250, 251, 1<<16 - 1, 1 << 16, 1<<24 - 1, 1 << 24, 1<<64 - 1} {
+		encoded := (&packetBuilder{}).lenencInt(value).Bytes()
+		decoded, rest := readLenencInt(encoded)
+		if decoded != value || len(rest) != 0 {
+			t.Errorf("expected %d to round trip got %d with %d bytes left", value, decoded, len(rest))
+		}
+
+		// Lengths cut short are read as nothing rather than past the end of the data
+		if len(encoded) > 1 {
+			if decoded, rest := readLenencInt(encoded[:len(encoded)-1]); decoded != 0 || rest != nil {
+				t.Errorf("expected truncated %d to read as nothing got %d", value, decoded)
+			}
+		}
+	}
+}
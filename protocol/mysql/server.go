@@ -0,0 +1,96 @@
+package mysql
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/listener"
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/protocol/mysql/logging"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	Server struct {
+		ListenPort int
+		ListenHost string
+
+		serverVersion string
+		listener      *listener.TcpListener
+		connCount     atomic.Uint64
+
+		// Services
+		stallerFactory   *stall.ConnStallerFactory
+		tabularSchemas   *source.TabularSchemaCollection
+		secretGenerators *secrets.SecretGeneratorCollection
+		logger           *logging.MysqlCommandLogger
+	}
+)
+
+func NewServer(
+	lf fx.Lifecycle,
+	cfg *config.Config,
+	stallerFactory *stall.ConnStallerFactory,
+	tabularSchemas *source.TabularSchemaCollection,
+	secretGenerators *secrets.SecretGeneratorCollection,
+	logger *logging.MysqlCommandLogger,
+) (*Server, error) {
+	if !cfg.MysqlServer.Enabled {
+		return nil, nil
+	}
+
+	server := &Server{
+		ListenPort: cfg.MysqlServer.Port,
+		ListenHost: cfg.MysqlServer.Host,
+
+		serverVersion: cfg.MysqlServer.ServerVersion,
+
+		stallerFactory:   stallerFactory,
+		tabularSchemas:   tabularSchemas,
+		secretGenerators: secretGenerators,
+		logger:           logger,
+	}
+
+	server.listener = listener.NewTcpListener("mysql", server.ListenHost, server.ListenPort, server.handleConn)
+
+	lf.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			zap.L().Sugar().Info("Shutting down MySQL server")
+			return server.listener.Stop()
+		},
+	})
+
+	return server, nil
+}
+
+// Starts listening for MySQL connections. Blocks until the server is stopped
+func (s *Server) Start() error {
+	return s.listener.Start()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	ctx := coreLogging.NewConnContext(s.connCount.Add(1), conn)
+	logger := s.logger.WithContext(ctx)
+	startTime := time.Now()
+
+	logger.Log("client_connected")
+	defer func() {
+		logger.Log("client_disconnected", zap.Duration("duration", time.Since(startTime)))
+	}()
+
+	staller, err := s.stallerFactory.FromConn("mysql", conn, conn, nil)
+	if err != nil {
+		zap.L().Warn("Failed to create MySQL staller", zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	newSession(s, conn, staller, ctx, logger).run()
+}
@@ -0,0 +1,395 @@
+package mysql
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-faker/faker/v4"
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+)
+
+// Databases that exist on every MySQL server. These are given out before the made up ones
+var systemDatabases = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+var nameSuffixes = []string{"prod", "live", "backup", "archive", "old", "staging", "data", "export"}
+
+var (
+	showDatabasesPattern = regexp.MustCompile(`(?i)^show\s+(databases|schemas)\b`)
+	showTablesPattern    = regexp.MustCompile(`(?i)^show\s+(full\s+)?tables\b`)
+	selectPattern        = regexp.MustCompile(`(?i)^select\b`)
+	fromPattern          = regexp.MustCompile(`(?i)\bfrom\b`)
+	useDbPattern         = regexp.MustCompile("(?i)^use\\s+`?([^`\\s;]+)")
+)
+
+// Values for the system variables and functions clients query when connecting
+var systemValues = map[string]func(s *session) string{
+	"@@version_comment":                  func(*session) string { return "(Ubuntu)" },
+	"@@version":                          func(s *session) string { return s.server.serverVersion },
+	"version()":                          func(s *session) string { return s.server.serverVersion },
+	"database()":                         func(s *session) string { return s.database },
+	"schema()":                           func(s *session) string { return s.database },
+	"user()":                             func(s *session) string { return s.user + "@localhost" },
+	"current_user()":                     func(s *session) string { return s.user + "@%" },
+	"connection_id()":                    func(s *session) string { return fmt.Sprint(s.ctx.Id) },
+	"@@session.auto_increment_increment": func(*session) string { return "1" },
+	"@@max_allowed_packet":               func(*session) string { return "67108864" },
+	"@@tx_isolation":                     func(*session) string { return "REPEATABLE-READ" },
+	"@@transaction_isolation":            func(*session) string { return "REPEATABLE-READ" },
+	"@@hostname":                         func(*session) string { return "db-prod-01" },
+	"@@datadir":                          func(*session) string { return "/var/lib/mysql/" },
+	"now()":                              func(*session) string { return time.Now().Format(time.DateTime) },
+}
+
+type (
+	// A single connection to the MySQL server. Every packet is dripped to the client through the staller
+	session struct {
+		server  *Server
+		conn    net.Conn
+		reader  *bufio.Reader
+		staller *stall.ConnStaller
+		ctx     *logging.ConnContext
+		logger  logging.CommandLogger
+		random  *rand.SeededRand
+		secrets *secrets.SecretGeneratorCollection
+
+		sequence byte
+		scramble []byte
+		user     string
+		database string
+	}
+)
+
+func newSession(server *Server, conn net.Conn, staller *stall.ConnStaller, ctx *logging.ConnContext, logger logging.CommandLogger) *session {
+	sessionSecrets := server.secretGenerators.ForClient(secrets.HoneytokenClient{
+		Protocol:  "mysql",
+		Ip:        logging.GetHost(conn.RemoteAddr()),
+		RequestId: fmt.Sprintf("mysql-%d", ctx.Id),
+	})
+
+	return &session{
+		server:  server,
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		staller: staller,
+		ctx:     ctx,
+		logger:  logger,
+		random:  rand.NewSeededRandFromTime(),
+		secrets: sessionSecrets,
+	}
+}
+
+func (s *session) run() {
+	s.staller.Halt(s.serve())
+}
+
+func (s *session) serve() error {
+	if err := s.handshake(); err != nil {
+		return err
+	}
+
+	for {
+		payload, err := s.readPacket()
+		if err != nil {
+			return err
+		}
+
+		if len(payload) == 0 {
+			continue
+		}
+
+		if err := s.handleCommand(payload[0], payload[1:]); err != nil {
+			return err
+		}
+	}
+}
+
+// Sends the initial handshake and accepts whatever credentials the client sends back
+func (s *session) handshake() error {
+	s.scramble = s.newScramble()
+
+	handshake := (&packetBuilder{}).
+		int1(10).
+		nulString(s.server.serverVersion).
+		int4(uint32(s.ctx.Id)).
+		raw(s.scramble[:8]).
+		int1(0).
+		int2(uint16(serverCapabilities & 0xffff)).
+		int1(charsetUtf8mb4).
+		int2(statusAutocommit).
+		int2(uint16(serverCapabilities >> 16)).
+		int1(byte(len(s.scramble) + 1)).
+		raw(make([]byte, 10)).
+		raw(s.scramble[8:]).
+		int1(0).
+		nulString(nativePasswordPlugin)
+
+	if err := s.writePacket(handshake.Bytes()); err != nil {
+		return err
+	}
+
+	payload, err := s.readPacket()
+	if err != nil {
+		return err
+	}
+
+	response, err := parseHandshakeResponse(payload)
+	if err != nil {
+		s.logger.Log("handshake_failed", zap.Error(err))
+		return err
+	}
+
+	// Clients defaulting to another plugin (i.e caching_sha2_password) are asked to switch over
+	// so the scrambled password can be logged in a well known format
+	authResponse := response.authResponse
+	if response.authPlugin != "" && response.authPlugin != nativePasswordPlugin {
+		authSwitch := (&packetBuilder{}).int1(0xfe).nulString(nativePasswordPlugin).raw(s.scramble).int1(0)
+		if err := s.writePacket(authSwitch.Bytes()); err != nil {
+			return err
+		}
+
+		if authResponse, err = s.readPacket(); err != nil {
+			return err
+		}
+	}
+
+	s.user = response.user
+	s.database = response.database
+	s.logger.Log("auth_user",
+		zap.String("user", response.user),
+		zap.String("database", response.database),
+		zap.String("auth_plugin", response.authPlugin),
+		zap.String("scramble", hex.EncodeToString(s.scramble)),
+		zap.String("auth_response", hex.EncodeToString(authResponse)),
+	)
+
+	return s.writeOk(0)
+}
+
+func (s *session) handleCommand(command byte, data []byte) error {
+	switch command {
+	case comQuit:
+		s.logger.Log("command", zap.String("command", "quit"))
+		return io.EOF
+	case comPing:
+		s.logger.Log("ping")
+		return s.writeOk(0)
+	case comInitDb:
+		s.database = string(data)
+		s.logger.Log("init_db", zap.String("database", s.database))
+		return s.writeOk(0)
+	case comQuery:
+		return s.handleQuery(string(data))
+	case comFieldList:
+		s.logger.Log("command", zap.String("command", "field_list"), zap.String("table", string(data)))
+		return s.writeEof()
+	case comStmtPrepare:
+		s.logger.Log("query", zap.String("query", string(data)), zap.Bool("prepared", true))
+		return s.writeError(1295, "HY000", "This command is not supported in the prepared statement protocol yet")
+	}
+
+	s.logger.Log("command", zap.String("command", fmt.Sprintf("0x%02x", command)))
+	return s.writeError(1047, "08S01", "Unknown command")
+}
+
+func (s *session) handleQuery(query string) error {
+	s.logger.Log("query", zap.String("query", query), zap.String("database", s.database))
+	trimmed := strings.TrimSpace(query)
+
+	switch {
+	case showDatabasesPattern.MatchString(trimmed):
+		return s.streamNames("Database", systemDatabases, func() string {
+			return s.randomName()
+		})
+	case showTablesPattern.MatchString(trimmed):
+		return s.streamNames("Tables_in_"+s.databaseOrDefault(), s.server.tabularSchemas.Tables(), func() string {
+			return s.randomName()
+		})
+	case selectPattern.MatchString(trimmed) && !fromPattern.MatchString(trimmed):
+		return s.selectValues(trimmed)
+	case selectPattern.MatchString(trimmed):
+		schema := s.server.tabularSchemas.ForQuery(trimmed)
+		return s.streamRows(schema.ColumnNames(), schema.NewRows(s.random, time.Now(), s.secrets).Next)
+	}
+
+	if match := useDbPattern.FindStringSubmatch(trimmed); match != nil {
+		s.database = match[1]
+	}
+
+	return s.writeOk(uint64(s.random.RandomInt(0, 5)))
+}
+
+// Answers queries like "SELECT @@version_comment LIMIT 1" that clients send on connect with a single row
+func (s *session) selectValues(query string) error {
+	expressions := strings.Split(strings.TrimSpace(query[len("select"):]), ",")
+	columns := make([]string, 0, len(expressions))
+	values := make([]string, 0, len(expressions))
+
+	for _, expression := range expressions {
+		expression = strings.TrimSpace(expression)
+		if i := strings.Index(strings.ToLower(expression), " limit "); i != -1 {
+			expression = expression[:i]
+		}
+
+		value := ""
+		if valueFunc, ok := systemValues[strings.ToLower(expression)]; ok {
+			value = valueFunc(s)
+		}
+
+		columns = append(columns, expression)
+		values = append(values, value)
+	}
+
+	if err := s.writeColumns(columns); err != nil {
+		return err
+	}
+
+	if err := s.writeRow(values); err != nil {
+		return err
+	}
+
+	return s.writeEof()
+}
+
+// A name for a made up database or table
+func (s *session) randomName() string {
+	word := ""
+	source.Fake(s.random, func() {
+		word = faker.Word()
+	})
+
+	return fmt.Sprintf("%s_%s", strings.ToLower(word), nameSuffixes[s.random.RandomInt(0, len(nameSuffixes))])
+}
+
+// Streams a single column result set starting with the given names followed by generated ones forever
+func (s *session) streamNames(column string, names []string, next func() string) error {
+	i := 0
+	return s.streamRows([]string{column}, func() []string {
+		i++
+		if i <= len(names) {
+			return []string{names[i-1]}
+		}
+		return []string{next()}
+	})
+}
+
+// Sends the given columns followed by rows from "next" until the staller runs out of time
+func (s *session) streamRows(columns []string, next func() []string) error {
+	if err := s.writeColumns(columns); err != nil {
+		return err
+	}
+
+	for {
+		if err := s.writeRow(next()); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *session) writeColumns(columns []string) error {
+	if err := s.writePacket((&packetBuilder{}).lenencInt(uint64(len(columns))).Bytes()); err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		definition := (&packetBuilder{}).
+			lenencString("def").
+			lenencString(s.databaseOrDefault()).
+			lenencString("").
+			lenencString("").
+			lenencString(column).
+			lenencString(column).
+			lenencInt(0x0c).
+			int2(uint16(charsetUtf8mb4)).
+			int4(1024).
+			int1(columnTypeVarString).
+			int2(0).
+			int1(0).
+			int2(0)
+
+		if err := s.writePacket(definition.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return s.writeEof()
+}
+
+func (s *session) writeRow(values []string) error {
+	row := &packetBuilder{}
+	for _, value := range values {
+		row.lenencString(value)
+	}
+
+	return s.writePacket(row.Bytes())
+}
+
+func (s *session) writeOk(affectedRows uint64) error {
+	return s.writePacket((&packetBuilder{}).int1(0x00).lenencInt(affectedRows).lenencInt(0).int2(statusAutocommit).int2(0).Bytes())
+}
+
+func (s *session) writeEof() error {
+	return s.writePacket((&packetBuilder{}).int1(0xfe).int2(0).int2(statusAutocommit).Bytes())
+}
+
+func (s *session) writeError(code uint16, state string, message string) error {
+	return s.writePacket((&packetBuilder{}).int1(0xff).int2(code).int1('#').raw([]byte(state)).raw([]byte(message)).Bytes())
+}
+
+// Slowly writes a packet to the client
+func (s *session) writePacket(payload []byte) error {
+	_, err := s.staller.Write(framePacket(s.sequence, payload))
+	s.sequence++
+	return err
+}
+
+// Reads a packet from the client. Reads are bound by the time the staller has left
+// so clients that go quiet are dropped once the staller runs out of time
+func (s *session) readPacket() ([]byte, error) {
+	if err := s.conn.SetReadDeadline(time.Now().Add(s.staller.GetRemainingTime())); err != nil {
+		return nil, err
+	}
+
+	sequence, payload, err := readPacket(s.reader)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil, stall.ErrStallDeadlineReached
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.sequence = sequence + 1
+	return payload, nil
+}
+
+func (s *session) databaseOrDefault() string {
+	if s.database == "" {
+		return "production"
+	}
+
+	return s.database
+}
+
+// Generates the 20 byte scramble used for mysql_native_password. Zero bytes are avoided
+// as the scramble is sent as a null terminated string
+func (s *session) newScramble() []byte {
+	scramble := make([]byte, 20)
+	for i := range scramble {
+		scramble[i] = byte(s.random.RandomInt(1, 127))
+	}
+
+	return scramble
+}
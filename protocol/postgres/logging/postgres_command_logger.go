@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+)
+
+type (
+	PostgresCommandLogger struct {
+		*logging.ConnCommandLogger
+	}
+)
+
+// Commands that are not included in the "all" command group
+// but are verbose enough to be included in the "all_detailed" group
+var overlyVerboseCommands = []string{}
+
+func NewPostgresCommandLogger(config *config.Config) (*PostgresCommandLogger, error) {
+	logger, err := logging.NewConnCommandLogger(&logging.ConnCommandLoggerOptions{
+		Protocol:         "postgres",
+		Path:             config.PostgresServer.CommandLog.Path,
+		FallbackPath:     config.Logging.Path,
+		CommandsToLog:    config.PostgresServer.CommandLog.CommandsToLog,
+		VerboseCommands:  overlyVerboseCommands,
+		AdditionalFields: config.PostgresServer.CommandLog.AdditionalFields,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresCommandLogger{logger}, nil
+}
@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Codes sent in place of a protocol version in the startup packet
+const (
+	protocolVersion3  uint32 = 196608
+	sslRequestCode    uint32 = 80877103
+	cancelRequestCode uint32 = 80877102
+	gssEncRequestCode uint32 = 80877104
+)
+
+const (
+	authOk        uint32 = 0
+	authCleartext uint32 = 3
+	authMd5       uint32 = 5
+
+	textTypeOid     uint32 = 25
+	transactionIdle byte   = 'I'
+
+	errorSeverityFatal = "FATAL"
+	errorSeverityError = "ERROR"
+
+	// Messages larger than these are not accepted from clients
+	maxStartupLength = 10 * 1024
+	maxMessageLength = 1024 * 1024
+)
+
+var errMessageTooLarge = errors.New("message too large")
+
+type (
+	// Builds up the body of a single backend message
+	messageBuilder struct {
+		bytes.Buffer
+	}
+)
+
+// Reads the startup packet (which has no type byte) returning its code and body
+func readStartupPacket(reader io.Reader) (uint32, []byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(binary.BigEndian.Uint32(header[0:4]))
+	if length < 8 || length > maxStartupLength {
+		return 0, nil, errMessageTooLarge
+	}
+
+	body := make([]byte, length-8)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, err
+	}
+
+	return binary.BigEndian.Uint32(header[4:8]), body, nil
+}
+
+// Reads a single frontend message returning its type and body
+func readMessage(reader io.Reader) (byte, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(binary.BigEndian.Uint32(header[1:5]))
+	if length < 4 || length > maxMessageLength {
+		return 0, nil, errMessageTooLarge
+	}
+
+	body := make([]byte, length-4)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header[0], body, nil
+}
+
+func (b *messageBuilder) int16(value int16) *messageBuilder {
+	b.Write(binary.BigEndian.AppendUint16(nil, uint16(value)))
+	return b
+}
+
+func (b *messageBuilder) int32(value int32) *messageBuilder {
+	b.Write(binary.BigEndian.AppendUint32(nil, uint32(value)))
+	return b
+}
+
+func (b *messageBuilder) string(value string) *messageBuilder {
+	b.WriteString(value)
+	b.WriteByte(0)
+	return b
+}
+
+func (b *messageBuilder) raw(value []byte) *messageBuilder {
+	b.Write(value)
+	return b
+}
+
+// Frames the body built so far as a message of the given type
+func (b *messageBuilder) message(messageType byte) []byte {
+	length := uint32(b.Len() + 4)
+	return append(binary.BigEndian.AppendUint32([]byte{messageType}, length), b.Bytes()...)
+}
+
+func authenticationMessage(code uint32, extra []byte) []byte {
+	return (&messageBuilder{}).int32(int32(code)).raw(extra).message('R')
+}
+
+func parameterStatusMessage(name string, value string) []byte {
+	return (&messageBuilder{}).string(name).string(value).message('S')
+}
+
+func readyForQueryMessage() []byte {
+	return (&messageBuilder{}).raw([]byte{transactionIdle}).message('Z')
+}
+
+func errorMessage(severity string, code string, text string) []byte {
+	return (&messageBuilder{}).
+		raw([]byte{'S'}).string(severity).
+		raw([]byte{'V'}).string(severity).
+		raw([]byte{'C'}).string(code).
+		raw([]byte{'M'}).string(text).
+		raw([]byte{0}).
+		message('E')
+}
+
+func rowDescriptionMessage(columns []string) []byte {
+	builder := (&messageBuilder{}).int16(int16(len(columns)))
+	for _, column := range columns {
+		builder.string(column).
+			int32(0).
+			int16(0).
+			int32(int32(textTypeOid)).
+			int16(-1).
+			int32(-1).
+			int16(0)
+	}
+
+	return builder.message('T')
+}
+
+func dataRowMessage(values []string) []byte {
+	builder := (&messageBuilder{}).int16(int16(len(values)))
+	for _, value := range values {
+		builder.int32(int32(len(value))).raw([]byte(value))
+	}
+
+	return builder.message('D')
+}
+
+// Splits a body made up of null terminated strings
+func readStrings(body []byte) []string {
+	values := []string{}
+	for len(body) > 0 {
+		end := bytes.IndexByte(body, 0)
+		if end == -1 {
+			values = append(values, string(body))
+			break
+		}
+
+		values = append(values, string(body[:end]))
+		body = body[end+1:]
+	}
+
+	return values
+}
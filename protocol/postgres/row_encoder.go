@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"github.com/ryanolee/go-pot/generator/source"
+)
+
+type (
+	// Encodes tabular data as PostgreSQL backend messages so rows can be streamed through
+	// a tabular generator. Each chunk generated is a single DataRow message
+	rowEncoder struct{}
+)
+
+func newRowEncoder() *rowEncoder {
+	return &rowEncoder{}
+}
+
+// The row description is sent by StartTable
+func (*rowEncoder) Start() string {
+	return ""
+}
+
+func (*rowEncoder) StartTable(schema *source.TabularSchema) string {
+	return string(rowDescriptionMessage(schema.ColumnNames()))
+}
+
+// Never reached as rows are streamed until the client is dropped
+func (*rowEncoder) End() string {
+	return ""
+}
+
+func (*rowEncoder) Delimiter() string {
+	return ""
+}
+
+func (*rowEncoder) ContentType() string {
+	return "application/octet-stream"
+}
+
+func (*rowEncoder) GetSupportedGenerator() string {
+	return "tabular"
+}
+
+func (*rowEncoder) Marshal(v interface{}) ([]byte, error) {
+	values, ok := v.([]string)
+	if !ok {
+		return nil, nil
+	}
+
+	return dataRowMessage(values), nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/listener"
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/protocol/postgres/logging"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	Server struct {
+		ListenPort int
+		ListenHost string
+
+		serverVersion string
+		authMethod    string
+		listener      *listener.TcpListener
+		connCount     atomic.Uint64
+
+		// Services
+		stallerFactory   *stall.ConnStallerFactory
+		tabularSchemas   *source.TabularSchemaCollection
+		secretGenerators *secrets.SecretGeneratorCollection
+		logger           *logging.PostgresCommandLogger
+	}
+)
+
+func NewServer(
+	lf fx.Lifecycle,
+	cfg *config.Config,
+	stallerFactory *stall.ConnStallerFactory,
+	tabularSchemas *source.TabularSchemaCollection,
+	secretGenerators *secrets.SecretGeneratorCollection,
+	logger *logging.PostgresCommandLogger,
+) (*Server, error) {
+	if !cfg.PostgresServer.Enabled {
+		return nil, nil
+	}
+
+	server := &Server{
+		ListenPort: cfg.PostgresServer.Port,
+		ListenHost: cfg.PostgresServer.Host,
+
+		serverVersion: cfg.PostgresServer.ServerVersion,
+		authMethod:    cfg.PostgresServer.AuthMethod,
+
+		stallerFactory:   stallerFactory,
+		tabularSchemas:   tabularSchemas,
+		secretGenerators: secretGenerators,
+		logger:           logger,
+	}
+
+	server.listener = listener.NewTcpListener("postgres", server.ListenHost, server.ListenPort, server.handleConn)
+
+	lf.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			zap.L().Sugar().Info("Shutting down PostgreSQL server")
+			return server.listener.Stop()
+		},
+	})
+
+	return server, nil
+}
+
+// Starts listening for PostgreSQL connections. Blocks until the server is stopped
+func (s *Server) Start() error {
+	return s.listener.Start()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	ctx := coreLogging.NewConnContext(s.connCount.Add(1), conn)
+	logger := s.logger.WithContext(ctx)
+	startTime := time.Now()
+
+	logger.Log("client_connected")
+	defer func() {
+		logger.Log("client_disconnected", zap.Duration("duration", time.Since(startTime)))
+	}()
+
+	staller, err := s.stallerFactory.FromConn("postgres", conn, conn, nil)
+	if err != nil {
+		zap.L().Warn("Failed to create PostgreSQL staller", zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	newSession(s, conn, staller, ctx, logger).run()
+}
@@ -0,0 +1,326 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+)
+
+var parameterPattern = regexp.MustCompile(`\$(\d+)`)
+
+type (
+	// A single connection to the PostgreSQL server. Every message is dripped to the client through the staller
+	session struct {
+		server  *Server
+		conn    net.Conn
+		reader  *bufio.Reader
+		staller *stall.ConnStaller
+		ctx     *logging.ConnContext
+		logger  logging.CommandLogger
+		random  *rand.SeededRand
+		secrets *secrets.SecretGeneratorCollection
+
+		user     string
+		database string
+
+		// Statements and portals created through the extended query protocol
+		statements map[string]string
+		portals    map[string]string
+	}
+)
+
+func newSession(server *Server, conn net.Conn, staller *stall.ConnStaller, ctx *logging.ConnContext, logger logging.CommandLogger) *session {
+	sessionSecrets := server.secretGenerators.ForClient(secrets.HoneytokenClient{
+		Protocol:  "postgres",
+		Ip:        logging.GetHost(conn.RemoteAddr()),
+		RequestId: fmt.Sprintf("postgres-%d", ctx.Id),
+	})
+
+	return &session{
+		server:     server,
+		conn:       conn,
+		reader:     bufio.NewReader(conn),
+		staller:    staller,
+		ctx:        ctx,
+		logger:     logger,
+		random:     rand.NewSeededRandFromTime(),
+		secrets:    sessionSecrets,
+		statements: map[string]string{},
+		portals:    map[string]string{},
+	}
+}
+
+func (s *session) run() {
+	s.staller.Halt(s.serve())
+}
+
+func (s *session) serve() error {
+	if err := s.startup(); err != nil {
+		return err
+	}
+
+	if err := s.authenticate(); err != nil {
+		return err
+	}
+
+	for {
+		messageType, body, err := s.readMessage()
+		if err != nil {
+			return err
+		}
+
+		if err := s.handleMessage(messageType, body); err != nil {
+			return err
+		}
+	}
+}
+
+// Reads the startup packet. SSL and GSS encryption requests are refused so clients carry on in plain text
+func (s *session) startup() error {
+	for {
+		code, body, err := s.readStartupPacket()
+		if err != nil {
+			return err
+		}
+
+		switch code {
+		case sslRequestCode, gssEncRequestCode:
+			s.logger.Log("ssl_request", zap.Bool("gss", code == gssEncRequestCode))
+			if err := s.write([]byte{'N'}); err != nil {
+				return err
+			}
+			continue
+		case cancelRequestCode:
+			s.logger.Log("command", zap.String("command", "cancel_request"))
+			return io.EOF
+		case protocolVersion3:
+		default:
+			s.write(errorMessage(errorSeverityFatal, "0A000", fmt.Sprintf("unsupported frontend protocol %d.%d", code>>16, code&0xffff)))
+			return fmt.Errorf("unsupported protocol version %d", code)
+		}
+
+		params := map[string]string{}
+		values := readStrings(body)
+		for i := 0; i+1 < len(values); i += 2 {
+			params[values[i]] = values[i+1]
+		}
+
+		s.user = params["user"]
+		s.database = params["database"]
+		if s.database == "" {
+			s.database = s.user
+		}
+
+		s.logger.Log("startup", zap.String("user", s.user), zap.String("database", s.database), zap.Any("params", params))
+		return nil
+	}
+}
+
+// Asks for a password accepting whatever is sent back
+func (s *session) authenticate() error {
+	salt := []byte{}
+	if s.server.authMethod == "md5" {
+		salt = []byte{byte(s.random.RandomInt(0, 256)), byte(s.random.RandomInt(0, 256)), byte(s.random.RandomInt(0, 256)), byte(s.random.RandomInt(0, 256))}
+		if err := s.write(authenticationMessage(authMd5, salt)); err != nil {
+			return err
+		}
+	} else if err := s.write(authenticationMessage(authCleartext, nil)); err != nil {
+		return err
+	}
+
+	messageType, body, err := s.readMessage()
+	if err != nil {
+		return err
+	}
+
+	if messageType != 'p' {
+		s.write(errorMessage(errorSeverityFatal, "08P01", "expected password response"))
+		return fmt.Errorf("unexpected message type %q during authentication", messageType)
+	}
+
+	password, _, _ := bytes.Cut(body, []byte{0})
+	fields := []zap.Field{
+		zap.String("user", s.user),
+		zap.String("database", s.database),
+		zap.String("auth_method", s.server.authMethod),
+	}
+
+	// MD5 passwords are sent as "md5" + md5(md5(password + user) + salt)
+	if s.server.authMethod == "md5" {
+		fields = append(fields, zap.String("hash", string(password)), zap.String("salt", hex.EncodeToString(salt)))
+	} else {
+		fields = append(fields, zap.String("pass", string(password)))
+	}
+	s.logger.Log("auth_user", fields...)
+
+	var response bytes.Buffer
+	response.Write(authenticationMessage(authOk, nil))
+	response.Write(parameterStatusMessage("server_version", s.server.serverVersion))
+	response.Write(parameterStatusMessage("server_encoding", "UTF8"))
+	response.Write(parameterStatusMessage("client_encoding", "UTF8"))
+	response.Write(parameterStatusMessage("DateStyle", "ISO, MDY"))
+	response.Write(parameterStatusMessage("integer_datetimes", "on"))
+	response.Write(parameterStatusMessage("TimeZone", "Etc/UTC"))
+	response.Write(parameterStatusMessage("standard_conforming_strings", "on"))
+	response.Write((&messageBuilder{}).int32(int32(s.random.RandomInt(1000, 60000))).int32(int32(s.random.RandomInt(0, 1<<30))).message('K'))
+	response.Write(readyForQueryMessage())
+
+	return s.write(response.Bytes())
+}
+
+func (s *session) handleMessage(messageType byte, body []byte) error {
+	switch messageType {
+	case 'Q':
+		query := firstString(body)
+		s.logger.Log("query", zap.String("query", query), zap.String("database", s.database))
+
+		if strings.Trim(strings.TrimSpace(query), ";") == "" {
+			return s.write(append((&messageBuilder{}).message('I'), readyForQueryMessage()...))
+		}
+
+		return s.streamRows(query, true)
+	case 'P':
+		values := readStrings(body)
+		name, query := valueAt(values, 0), valueAt(values, 1)
+		s.statements[name] = query
+		s.logger.Log("parse", zap.String("statement", name), zap.String("query", query), zap.String("database", s.database))
+		return s.write((&messageBuilder{}).message('1'))
+	case 'B':
+		values := readStrings(body)
+		s.portals[valueAt(values, 0)] = s.statements[valueAt(values, 1)]
+		return s.write((&messageBuilder{}).message('2'))
+	case 'D':
+		if len(body) == 0 {
+			return s.write(append(errorMessage(errorSeverityError, "08P01", "invalid message format"), readyForQueryMessage()...))
+		}
+
+		if body[0] == 'S' {
+			query := s.statements[firstString(body[1:])]
+			return s.write(append(s.parameterDescription(query), s.rowDescription(query)...))
+		}
+		return s.write(s.rowDescription(s.portals[firstString(body[1:])]))
+	case 'E':
+		return s.streamRows(s.portals[firstString(body)], false)
+	case 'C':
+		return s.write((&messageBuilder{}).message('3'))
+	case 'S':
+		return s.write(readyForQueryMessage())
+	case 'H':
+		return nil
+	case 'X':
+		s.logger.Log("command", zap.String("command", "terminate"))
+		return io.EOF
+	}
+
+	s.logger.Log("command", zap.String("command", string(messageType)))
+	return s.write(append(errorMessage(errorSeverityError, "0A000", "feature not supported"), readyForQueryMessage()...))
+}
+
+// Describes the rows of the table the query selects from
+func (s *session) rowDescription(query string) []byte {
+	return []byte(newRowEncoder().StartTable(s.server.tabularSchemas.ForQuery(query)))
+}
+
+// Streams rows of the table the query selects from to the client forever. Each row is one chunk of a tabular generator
+func (s *session) streamRows(query string, withDescription bool) error {
+	gen := generator.NewTabularGenerator(newRowEncoder(), s.server.tabularSchemas.ForQuery(query), s.secrets, generator.NewStreamSource())
+
+	if withDescription {
+		if err := s.write(gen.Start()); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if err := s.write(gen.GenerateChunk()); err != nil {
+			return err
+		}
+
+		if err := s.write(gen.ChunkSeparator()); err != nil {
+			return err
+		}
+	}
+}
+
+// Describes the parameters of a prepared statement. Every parameter is given as text
+func (s *session) parameterDescription(query string) []byte {
+	count := 0
+	for _, match := range parameterPattern.FindAllStringSubmatch(query, -1) {
+		if index, err := strconv.Atoi(match[1]); err == nil && index > count {
+			count = index
+		}
+	}
+
+	builder := (&messageBuilder{}).int16(int16(count))
+	for i := 0; i < count; i++ {
+		builder.int32(int32(textTypeOid))
+	}
+
+	return builder.message('t')
+}
+
+// Slowly writes data to the client
+func (s *session) write(data []byte) error {
+	_, err := s.staller.Write(data)
+	return err
+}
+
+func (s *session) readStartupPacket() (uint32, []byte, error) {
+	if err := s.setReadDeadline(); err != nil {
+		return 0, nil, err
+	}
+
+	code, body, err := readStartupPacket(s.reader)
+	return code, body, s.mapReadError(err)
+}
+
+func (s *session) readMessage() (byte, []byte, error) {
+	if err := s.setReadDeadline(); err != nil {
+		return 0, nil, err
+	}
+
+	messageType, body, err := readMessage(s.reader)
+	return messageType, body, s.mapReadError(err)
+}
+
+// Reads are bound by the time the staller has left so clients that go quiet are dropped once the staller runs out of time
+func (s *session) setReadDeadline() error {
+	return s.conn.SetReadDeadline(time.Now().Add(s.staller.GetRemainingTime()))
+}
+
+func (s *session) mapReadError(err error) error {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return stall.ErrStallDeadlineReached
+	}
+
+	return err
+}
+
+func firstString(body []byte) string {
+	value, _, _ := bytes.Cut(body, []byte{0})
+	return string(value)
+}
+
+func valueAt(values []string, index int) string {
+	if index >= len(values) {
+		return ""
+	}
+
+	return values[index]
+}
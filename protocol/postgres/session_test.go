@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type (
+	// Collects what is written to the client failing once enough has been written so endless streams return
+	limitedWriter struct {
+		bytes.Buffer
+		limit int
+	}
+
+	nopCommandLogger struct{}
+)
+
+var errWriteLimitReached = errors.New("write limit reached")
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	if w.Len()+len(data) > w.limit {
+		return 0, errWriteLimitReached
+	}
+
+	return w.Buffer.Write(data)
+}
+
+func (w *limitedWriter) Close() error {
+	return nil
+}
+
+func (nopCommandLogger) Log(string, ...zap.Field) {}
+
+// Frames a frontend message with the given type and length. The length is given separately so malformed lengths can be sent
+func frontendMessage(messageType byte, length uint32, body []byte) []byte {
+	return append(binary.BigEndian.AppendUint32([]byte{messageType}, length), body...)
+}
+
+func TestReadMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantType byte
+		wantBody []byte
+		wantErr  error
+	}{
+		{name: "query", input: frontendMessage('Q', 10, []byte("select")), wantType: 'Q', wantBody: []byte("select")},
+		{name: "empty body", input: frontendMessage('D', 4, nil), wantType: 'D', wantBody: []byte{}},
+		{name: "empty input", input: []byte{}, wantErr: io.EOF},
+		{name: "truncated header", input: []byte{'Q', 0, 0}, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated body", input: frontendMessage('Q', 100, []byte("select")), wantErr: io.ErrUnexpectedEOF},
+		{name: "length shorter than itself", input: frontendMessage('Q', 3, nil), wantErr: errMessageTooLarge},
+		{name: "oversized length", input: frontendMessage('Q', maxMessageLength+1, nil), wantErr: errMessageTooLarge},
+		{name: "length with high bit set", input: frontendMessage('Q', 0xffffffff, nil), wantErr: errMessageTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			messageType, body, err := readMessage(bytes.NewReader(test.input))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantErr != nil {
+				return
+			}
+
+			if messageType != test.wantType || !bytes.Equal(body, test.wantBody) {
+				t.Errorf("expected %q %q got %q %q", test.wantType, test.wantBody, messageType, body)
+			}
+		})
+	}
+}
+
+func TestReadStartupPacket(t *testing.T) {
+	packet := func(length uint32, code uint32, body []byte) []byte {
+		return append(binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, length), code), body...)
+	}
+
+	tests := []struct {
+		name     string
+		input    []byte
+		wantCode uint32
+		wantErr  error
+	}{
+		{name: "startup", input: packet(13, protocolVersion3, []byte("user\x00")), wantCode: protocolVersion3},
+		{name: "ssl request", input: packet(8, sslRequestCode, nil), wantCode: sslRequestCode},
+		{name: "truncated header", input: []byte{0, 0, 0, 8, 0}, wantErr: io.ErrUnexpectedEOF},
+		{name: "truncated body", input: packet(64, protocolVersion3, []byte("user")), wantErr: io.ErrUnexpectedEOF},
+		{name: "length shorter than header", input: packet(7, protocolVersion3, nil), wantErr: errMessageTooLarge},
+		{name: "oversized length", input: packet(maxStartupLength+1, protocolVersion3, nil), wantErr: errMessageTooLarge},
+		{name: "length with high bit set", input: packet(0x80000000, protocolVersion3, nil), wantErr: errMessageTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, _, err := readStartupPacket(bytes.NewReader(test.input))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v got %v", test.wantErr, err)
+			}
+
+			if test.wantErr == nil && code != test.wantCode {
+				t.Errorf("expected code %d got %d", test.wantCode, code)
+			}
+		})
+	}
+}
+
+func TestReadStrings(t *testing.T) {
+	tests := []struct {
+		input []byte
+		want  []string
+	}{
+		{input: nil, want: []string{}},
+		{input: []byte("a\x00b\x00"), want: []string{"a", "b"}},
+		{input: []byte("\x00\x00"), want: []string{"", ""}},
+		{input: []byte("unterminated"), want: []string{"unterminated"}},
+	}
+
+	for _, test := range tests {
+		got := readStrings(test.input)
+		if len(got) != len(test.want) {
+			t.Fatalf("readStrings(%q) expected %q got %q", test.input, test.want, got)
+		}
+
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("readStrings(%q) expected %q got %q", test.input, test.want, got)
+			}
+		}
+	}
+}
+
+// Malformed messages from an authenticated client are answered rather than taking down the server
+func TestHandleMessageMalformed(t *testing.T) {
+	tests := []struct {
+		name         string
+		messageType  byte
+		body         []byte
+		wantResponse byte
+	}{
+		{name: "describe without a body", messageType: 'D', body: []byte{}, wantResponse: 'E'},
+		{name: "describe statement without a name", messageType: 'D', body: []byte{'S'}, wantResponse: 't'},
+		{name: "describe portal without a name", messageType: 'D', body: []byte{'P'}, wantResponse: 'T'},
+		{name: "parse without a body", messageType: 'P', body: []byte{}, wantResponse: '1'},
+		{name: "bind without a body", messageType: 'B', body: []byte{}, wantResponse: '2'},
+		{name: "execute without a body", messageType: 'E', body: []byte{}, wantResponse: 'D'},
+		{name: "query without a terminator", messageType: 'Q', body: []byte(";"), wantResponse: 'I'},
+		{name: "unknown message", messageType: 0xff, body: []byte{0xff}, wantResponse: 'E'},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, output := newTestSession(t)
+			err := s.handleMessage(test.messageType, test.body)
+			if err != nil && !errors.Is(err, errWriteLimitReached) {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			if output.Len() == 0 || output.Bytes()[0] != test.wantResponse {
+				t.Errorf("expected a %q response got %q", test.wantResponse, output.Bytes())
+			}
+		})
+	}
+}
+
+func newTestSession(t *testing.T) (*session, *limitedWriter) {
+	t.Helper()
+
+	conf, err := config.NewConfig(config.BindConfigFileFlags(&cobra.Command{}), config.GetPostgresFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tabularSchemas, err := source.NewTabularSchemaCollection(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretGenerators, err := secrets.NewSecretGeneratorCollection(conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheduler := stall.NewSchedulerWithOptions(&stall.SchedulerOptions{Tick: time.Millisecond})
+	scheduler.Start()
+	t.Cleanup(scheduler.Stop)
+
+	output := &limitedWriter{limit: 16 * 1024}
+	staller := stall.NewConnStaller(&stall.ConnStallerOptions{
+		Conn:         output,
+		TransferRate: time.Microsecond,
+		Scheduler:    scheduler,
+	})
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+
+	return &session{
+		server: &Server{
+			serverVersion:    conf.PostgresServer.ServerVersion,
+			tabularSchemas:   tabularSchemas,
+			secretGenerators: secretGenerators,
+		},
+		conn:       server,
+		reader:     bufio.NewReader(server),
+		staller:    staller,
+		logger:     nopCommandLogger{},
+		random:     rand.NewSeededRand(1),
+		secrets:    secretGenerators,
+		statements: map[string]string{},
+		portals:    map[string]string{},
+	}, output
+}
@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+)
+
+type (
+	RedisCommandLogger struct {
+		*logging.ConnCommandLogger
+	}
+)
+
+// Commands that are not included in the "all" command group
+// but are verbose enough to be included in the "all_detailed" group
+var overlyVerboseCommands = []string{
+	"ping",
+}
+
+func NewRedisCommandLogger(config *config.Config) (*RedisCommandLogger, error) {
+	logger, err := logging.NewConnCommandLogger(&logging.ConnCommandLoggerOptions{
+		Protocol:         "redis",
+		Path:             config.RedisServer.CommandLog.Path,
+		FallbackPath:     config.Logging.Path,
+		CommandsToLog:    config.RedisServer.CommandLog.CommandsToLog,
+		VerboseCommands:  overlyVerboseCommands,
+		AdditionalFields: config.RedisServer.CommandLog.AdditionalFields,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisCommandLogger{logger}, nil
+}
@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Limits on what clients can send. Anything past these is treated as a protocol error
+	maxInlineLength  = 64 * 1024
+	maxBulkLength    = 512 * 1024
+	maxArrayElements = 1024
+
+	// Arguments preallocated for a command. Commands declaring more grow as their arguments arrive
+	// so a client can not have memory allocated just by declaring a large array
+	argsPreallocated = 64
+
+	// The largest length a bulk string can declare (proto-max-bulk-len). Used for replies that never end
+	endlessBulkLength = 512 * 1024 * 1024
+
+	// The number of elements declared by aggregate replies that never end
+	endlessArrayLength = 1<<31 - 1
+)
+
+var errProtocol = errors.New("protocol error")
+
+type (
+	// Reads commands sent by a client. Both RESP arrays and inline commands are supported
+	respReader struct {
+		reader *bufio.Reader
+	}
+)
+
+func newRespReader(reader io.Reader) *respReader {
+	return &respReader{
+		reader: bufio.NewReaderSize(reader, maxInlineLength),
+	}
+}
+
+// Reads a single command returning its arguments
+func (r *respReader) ReadCommand() ([]string, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		if line == "" {
+			continue
+		}
+
+		if line[0] != '*' {
+			return strings.Fields(line), nil
+		}
+
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count > maxArrayElements {
+			return nil, errProtocol
+		}
+
+		if count <= 0 {
+			continue
+		}
+
+		args := make([]string, 0, min(count, argsPreallocated))
+		for i := 0; i < count; i++ {
+			arg, err := r.readBulkString()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+
+		return args, nil
+	}
+}
+
+func (r *respReader) readBulkString() (string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if len(line) == 0 || line[0] != '$' {
+		return "", errProtocol
+	}
+
+	length, err := strconv.Atoi(line[1:])
+	if err != nil || length < 0 || length > maxBulkLength {
+		return "", errProtocol
+	}
+
+	data := make([]byte, length+2)
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		return "", err
+	}
+
+	return string(data[:length]), nil
+}
+
+func (r *respReader) readLine() (string, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errProtocol
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// RESP encoding helpers
+
+func simpleString(value string) string {
+	return "+" + value + "\r\n"
+}
+
+func errorString(value string) string {
+	return "-" + value + "\r\n"
+}
+
+func integer(value int) string {
+	return fmt.Sprintf(":%d\r\n", value)
+}
+
+func bulkString(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+// The start of a bulk string. The data itself is written separately
+func bulkHeader(length int) string {
+	return fmt.Sprintf("$%d\r\n", length)
+}
+
+func arrayHeader(length int) string {
+	return fmt.Sprintf("*%d\r\n", length)
+}
+
+// Maps are only a part of RESP3. RESP2 clients get a flat array of key value pairs instead
+func mapHeader(protocol int, length int) string {
+	if protocol >= 3 {
+		return fmt.Sprintf("%%%d\r\n", length)
+	}
+
+	return arrayHeader(length * 2)
+}
+
+func null(protocol int) string {
+	if protocol >= 3 {
+		return "_\r\n"
+	}
+
+	return "$-1\r\n"
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantArgs []string
+		wantErr  error
+	}{
+		{name: "array", input: "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n", wantArgs: []string{"GET", "key"}},
+		{name: "inline", input: "SET key value\r\n", wantArgs: []string{"SET", "key", "value"}},
+		{name: "inline without carriage return", input: "PING\n", wantArgs: []string{"PING"}},
+		{name: "empty lines skipped", input: "\r\n\r\nPING\r\n", wantArgs: []string{"PING"}},
+		{name: "empty array skipped", input: "*0\r\n*-1\r\nPING\r\n", wantArgs: []string{"PING"}},
+		{name: "empty bulk string", input: "*1\r\n$0\r\n\r\n", wantArgs: []string{""}},
+		{name: "empty input", input: "", wantErr: io.EOF},
+		{name: "truncated array", input: "*2\r\n$3\r\nGET\r\n", wantErr: io.EOF},
+		{name: "truncated bulk string", input: "*1\r\n$10\r\nGET\r\n", wantErr: io.ErrUnexpectedEOF},
+		{name: "unterminated line", input: "*1", wantErr: io.EOF},
+		{name: "bad array length", input: "*two\r\n", wantErr: errProtocol},
+		{name: "oversized array length", input: "*1048576\r\n", wantErr: errProtocol},
+		{name: "array length overflowing an int", input: "*99999999999999999999\r\n", wantErr: errProtocol},
+		{name: "missing bulk string", input: "*1\r\n:1\r\n", wantErr: errProtocol},
+		{name: "bad bulk string length", input: "*1\r\n$x\r\n", wantErr: errProtocol},
+		{name: "negative bulk string length", input: "*1\r\n$-5\r\n", wantErr: errProtocol},
+		{name: "oversized bulk string length", input: "*1\r\n$524289\r\n", wantErr: errProtocol},
+		{name: "oversized inline command", input: strings.Repeat("A", maxInlineLength+1), wantErr: errProtocol},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args, err := newRespReader(strings.NewReader(test.input)).ReadCommand()
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v got %v", test.wantErr, err)
+			}
+
+			if strings.Join(args, "\x00") != strings.Join(test.wantArgs, "\x00") || len(args) != len(test.wantArgs) {
+				t.Errorf("expected %q got %q", test.wantArgs, args)
+			}
+		})
+	}
+}
+
+// Declaring a large array only allocates as much as the arguments actually sent
+func TestReadCommandDoesNotTrustDeclaredLength(t *testing.T) {
+	result := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newRespReader(strings.NewReader("*1024\r\n$1\r\na\r\n")).ReadCommand()
+		}
+	})
+
+	// The reader allocates a buffer of its own. Preallocating every declared argument would take another 16KiB
+	if bytes := result.AllocedBytesPerOp(); bytes > maxInlineLength+4*1024 {
+		t.Errorf("expected at most %d bytes to be allocated got %d", maxInlineLength+4*1024, bytes)
+	}
+}
@@ -0,0 +1,92 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/listener"
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/protocol/redis/logging"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	Server struct {
+		ListenPort int
+		ListenHost string
+
+		version   string
+		listener  *listener.TcpListener
+		connCount atomic.Uint64
+
+		// Services
+		stallerFactory   *stall.ConnStallerFactory
+		secretGenerators *secrets.SecretGeneratorCollection
+		logger           *logging.RedisCommandLogger
+	}
+)
+
+func NewServer(
+	lf fx.Lifecycle,
+	cfg *config.Config,
+	stallerFactory *stall.ConnStallerFactory,
+	secretGenerators *secrets.SecretGeneratorCollection,
+	logger *logging.RedisCommandLogger,
+) (*Server, error) {
+	if !cfg.RedisServer.Enabled {
+		return nil, nil
+	}
+
+	server := &Server{
+		ListenPort: cfg.RedisServer.Port,
+		ListenHost: cfg.RedisServer.Host,
+
+		version: cfg.RedisServer.Version,
+
+		stallerFactory:   stallerFactory,
+		secretGenerators: secretGenerators,
+		logger:           logger,
+	}
+
+	server.listener = listener.NewTcpListener("redis", server.ListenHost, server.ListenPort, server.handleConn)
+
+	lf.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			zap.L().Sugar().Info("Shutting down Redis server")
+			return server.listener.Stop()
+		},
+	})
+
+	return server, nil
+}
+
+// Starts listening for Redis connections. Blocks until the server is stopped
+func (s *Server) Start() error {
+	return s.listener.Start()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	ctx := coreLogging.NewConnContext(s.connCount.Add(1), conn)
+	logger := s.logger.WithContext(ctx)
+	startTime := time.Now()
+
+	logger.Log("client_connected")
+	defer func() {
+		logger.Log("client_disconnected", zap.Duration("duration", time.Since(startTime)))
+	}()
+
+	staller, err := s.stallerFactory.FromConn("redis", conn, conn, nil)
+	if err != nil {
+		zap.L().Warn("Failed to create Redis staller", zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	newSession(s, conn, staller, ctx, logger).run()
+}
@@ -0,0 +1,351 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+)
+
+// Real looking configuration handed out before the endless stream of generated values
+var configValues = [][2]string{
+	{"dir", "/var/lib/redis"},
+	{"dbfilename", "dump.rdb"},
+	{"requirepass", ""},
+	{"masterauth", ""},
+	{"bind", "* -::*"},
+	{"protected-mode", "no"},
+	{"port", "6379"},
+	{"maxmemory", "0"},
+	{"appendonly", "no"},
+	{"save", "3600 1 300 100 60 10000"},
+}
+
+type (
+	// A single connection to the Redis server. Every reply is dripped to the client through the staller
+	session struct {
+		server  *Server
+		conn    net.Conn
+		reader  *respReader
+		staller *stall.ConnStaller
+		ctx     *logging.ConnContext
+		logger  logging.CommandLogger
+		random  *rand.SeededRand
+		secrets *secrets.SecretGeneratorCollection
+
+		// The RESP version negotiated with HELLO
+		protocol int
+	}
+)
+
+func newSession(server *Server, conn net.Conn, staller *stall.ConnStaller, ctx *logging.ConnContext, logger logging.CommandLogger) *session {
+	return &session{
+		server:   server,
+		conn:     conn,
+		reader:   newRespReader(conn),
+		staller:  staller,
+		ctx:      ctx,
+		logger:   logger,
+		random:   rand.NewSeededRandFromTime(),
+		protocol: 2,
+		secrets: server.secretGenerators.ForClient(secrets.HoneytokenClient{
+			Protocol:  "redis",
+			Ip:        logging.GetHost(conn.RemoteAddr()),
+			RequestId: fmt.Sprintf("redis-%d", ctx.Id),
+		}),
+	}
+}
+
+func (s *session) run() {
+	s.staller.Halt(s.serve())
+}
+
+func (s *session) serve() error {
+	for {
+		args, err := s.readCommand()
+		if errors.Is(err, errProtocol) {
+			s.logger.Log("protocol_error")
+			s.flush(errorString("ERR Protocol error"))
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		if err := s.handleCommand(strings.ToUpper(args[0]), args[1:]); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *session) handleCommand(name string, args []string) error {
+	switch name {
+	case "PING":
+		s.logger.Log("ping")
+		if len(args) > 0 {
+			return s.write(bulkString(args[0]))
+		}
+		return s.write(simpleString("PONG"))
+	case "AUTH":
+		user, pass := "default", ""
+		if len(args) == 1 {
+			pass = args[0]
+		} else if len(args) > 1 {
+			user, pass = args[0], args[1]
+		}
+		s.logger.Log("auth_user", zap.String("user", user), zap.String("pass", pass))
+		return s.write(simpleString("OK"))
+	case "HELLO":
+		return s.hello(args)
+	case "CONFIG":
+		return s.config(args)
+	case "SLAVEOF", "REPLICAOF":
+		host, port := argAt(args, 0), argAt(args, 1)
+		s.logger.Log("slaveof", zap.String("command", name), zap.String("host", host), zap.String("port", port))
+		return s.write(simpleString("OK"))
+	case "SET":
+		s.logger.Log("set", zap.String("key", argAt(args, 0)), zap.String("value", argAt(args, 1)), zap.Strings("options", tail(args, 2)))
+		return s.write(simpleString("OK"))
+	case "SAVE", "BGSAVE":
+		s.logger.Log("save", zap.String("command", name))
+		if name == "BGSAVE" {
+			return s.write(simpleString("Background saving started"))
+		}
+		return s.write(simpleString("OK"))
+	case "FLUSHALL", "FLUSHDB":
+		s.logger.Log("flushall", zap.String("command", name))
+		return s.write(simpleString("OK"))
+	case "MODULE":
+		if strings.EqualFold(argAt(args, 0), "LOAD") {
+			s.logger.Log("module_load", zap.String("path", argAt(args, 1)), zap.Strings("args", tail(args, 2)))
+			return s.write(simpleString("OK"))
+		}
+	case "EVAL", "EVALSHA", "EVAL_RO", "EVALSHA_RO":
+		s.logger.Log("eval", zap.String("command", name), zap.String("script", argAt(args, 0)), zap.Strings("args", tail(args, 1)))
+		return s.write(null(s.protocol))
+	}
+
+	s.logger.Log("command", zap.String("command", name), zap.Strings("args", args))
+
+	switch name {
+	case "QUIT":
+		if err := s.write(simpleString("OK")); err != nil {
+			return err
+		}
+		return io.EOF
+	case "ECHO":
+		return s.write(bulkString(argAt(args, 0)))
+	case "SELECT", "CLIENT", "READONLY", "RESET":
+		return s.write(simpleString("OK"))
+	case "COMMAND":
+		return s.write(arrayHeader(0))
+	case "DBSIZE":
+		return s.write(integer(s.random.RandomInt(100000, 10000000)))
+	case "EXISTS", "DEL", "UNLINK", "EXPIRE":
+		return s.write(integer(1))
+	case "TTL", "PTTL":
+		return s.write(integer(-1))
+	case "TYPE":
+		return s.write(simpleString("string"))
+	case "INFO":
+		return s.stream(bulkHeader(endlessBulkLength)+s.infoHeader(), s.nextInfoLine)
+	case "KEYS":
+		return s.stream(arrayHeader(endlessArrayLength), s.nextKey)
+	case "SCAN", "HSCAN", "SSCAN", "ZSCAN":
+		cursor := strconv.Itoa(s.random.RandomInt(1, 1<<20))
+		return s.stream(arrayHeader(2)+bulkString(cursor)+arrayHeader(endlessArrayLength), s.nextKey)
+	case "GET", "GETDEL", "GETEX", "HGET", "LPOP", "RPOP":
+		return s.stream(bulkHeader(endlessBulkLength), s.nextSecret)
+	case "HGETALL":
+		return s.stream(mapHeader(s.protocol, endlessArrayLength), s.nextPair)
+	}
+
+	return s.write(errorString(fmt.Sprintf("ERR unknown command '%s', with args beginning with: %s", name, formatArgs(args))))
+}
+
+// Handles HELLO which can switch the connection to RESP3 and authenticate in one go
+func (s *session) hello(args []string) error {
+	protocol := s.protocol
+	if len(args) > 0 {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < 2 || version > 3 {
+			s.logger.Log("hello", zap.String("protocol", args[0]))
+			return s.write(errorString("NOPROTO unsupported protocol version"))
+		}
+		protocol = version
+	}
+
+	fields := []zap.Field{zap.Int("protocol", protocol)}
+	for i := 1; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "AUTH":
+			fields = append(fields, zap.String("user", argAt(args, i+1)), zap.String("pass", argAt(args, i+2)))
+			i += 2
+		case "SETNAME":
+			fields = append(fields, zap.String("client_name", argAt(args, i+1)))
+			i++
+		}
+	}
+
+	s.logger.Log("hello", fields...)
+	s.protocol = protocol
+
+	return s.write(mapHeader(s.protocol, 7) +
+		bulkString("server") + bulkString("redis") +
+		bulkString("version") + bulkString(s.server.version) +
+		bulkString("proto") + integer(s.protocol) +
+		bulkString("id") + integer(int(s.ctx.Id)) +
+		bulkString("mode") + bulkString("standalone") +
+		bulkString("role") + bulkString("master") +
+		bulkString("modules") + arrayHeader(0),
+	)
+}
+
+func (s *session) config(args []string) error {
+	subcommand := strings.ToUpper(argAt(args, 0))
+	switch subcommand {
+	case "SET":
+		// CONFIG SET can set multiple parameters at once
+		for i := 1; i+1 < len(args); i += 2 {
+			s.logger.Log("config_set", zap.String("parameter", args[i]), zap.String("value", args[i+1]))
+		}
+		return s.write(simpleString("OK"))
+	case "GET":
+		s.logger.Log("command", zap.String("command", "CONFIG"), zap.Strings("args", args))
+		header := mapHeader(s.protocol, endlessArrayLength)
+		for _, value := range configValues {
+			header += bulkString(value[0]) + bulkString(value[1])
+		}
+		return s.stream(header, s.nextPair)
+	}
+
+	s.logger.Log("command", zap.String("command", "CONFIG"), zap.Strings("args", args))
+	if subcommand == "RESETSTAT" || subcommand == "REWRITE" {
+		return s.write(simpleString("OK"))
+	}
+
+	return s.write(errorString(fmt.Sprintf("ERR unknown subcommand '%s'. Try CONFIG HELP.", argAt(args, 0))))
+}
+
+func (s *session) infoHeader() string {
+	return strings.Join([]string{
+		"# Server",
+		"redis_version:" + s.server.version,
+		"redis_mode:standalone",
+		"os:Linux 5.15.0-91-generic x86_64",
+		"arch_bits:64",
+		fmt.Sprintf("process_id:%d", s.random.RandomInt(300, 30000)),
+		"tcp_port:6379",
+		fmt.Sprintf("uptime_in_seconds:%d", s.random.RandomInt(86400, 86400*400)),
+		"config_file:/etc/redis/redis.conf",
+		"",
+		"# Replication",
+		"role:master",
+		"connected_slaves:0",
+		"",
+		"# Keyspace",
+		"",
+	}, "\r\n")
+}
+
+// Generators for the endless replies. Each call returns the next chunk of a reply
+
+func (s *session) nextInfoLine() string {
+	gen := s.secrets.GetRandomGenerator()
+	return fmt.Sprintf("%s:%s\r\n", strings.ToLower(gen.NameGenerator.Generate()), s.secrets.Secret(gen))
+}
+
+func (s *session) nextKey() string {
+	gen := s.secrets.GetRandomGenerator()
+	return bulkString(fmt.Sprintf("%s:%d", strings.ToLower(gen.NameGenerator.Generate()), s.random.RandomInt(1, 100000)))
+}
+
+func (s *session) nextSecret() string {
+	return s.secrets.Secret(s.secrets.GetRandomGenerator()) + "\n"
+}
+
+func (s *session) nextPair() string {
+	gen := s.secrets.GetRandomGenerator()
+	return bulkString(strings.ToLower(gen.NameGenerator.Generate())) + bulkString(s.secrets.Secret(gen))
+}
+
+// Sends the start of a reply followed by chunks from "next" until the staller runs out of time
+func (s *session) stream(header string, next func() string) error {
+	if err := s.write(header); err != nil {
+		return err
+	}
+
+	for {
+		if err := s.write(next()); err != nil {
+			return err
+		}
+	}
+}
+
+// Reads a command from the client. Reads are bound by the time the staller has left
+// so clients that go quiet are dropped once the staller runs out of time
+func (s *session) readCommand() ([]string, error) {
+	if err := s.conn.SetReadDeadline(time.Now().Add(s.staller.GetRemainingTime())); err != nil {
+		return nil, err
+	}
+
+	args, err := s.reader.ReadCommand()
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil, stall.ErrStallDeadlineReached
+	}
+
+	return args, err
+}
+
+// Slowly writes data to the client
+func (s *session) write(data string) error {
+	_, err := s.staller.Write([]byte(data))
+	return err
+}
+
+// Writes data to the client straight away
+func (s *session) flush(data string) {
+	if _, err := s.staller.WriteNow([]byte(data)); err != nil {
+		zap.L().Debug("Failed to flush Redis reply", zap.Error(err))
+	}
+}
+
+func argAt(args []string, index int) string {
+	if index >= len(args) {
+		return ""
+	}
+
+	return args[index]
+}
+
+func tail(args []string, from int) []string {
+	if from >= len(args) {
+		return []string{}
+	}
+
+	return args[from:]
+}
+
+func formatArgs(args []string) string {
+	formatted := make([]string, 0, len(args))
+	for _, arg := range args {
+		formatted = append(formatted, "'"+arg+"'")
+	}
+
+	return strings.Join(formatted, " ")
+}
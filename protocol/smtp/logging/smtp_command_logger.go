@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+)
+
+type (
+	SmtpCommandLogger struct {
+		*logging.ConnCommandLogger
+	}
+)
+
+// Commands that are not included in the "all" command group
+// but are verbose enough to be included in the "all_detailed" group
+var overlyVerboseCommands = []string{
+	"noop",
+	"rset",
+}
+
+func NewSmtpCommandLogger(config *config.Config) (*SmtpCommandLogger, error) {
+	logger, err := logging.NewConnCommandLogger(&logging.ConnCommandLoggerOptions{
+		Protocol:         "smtp",
+		Path:             config.SmtpServer.CommandLog.Path,
+		FallbackPath:     config.Logging.Path,
+		CommandsToLog:    config.SmtpServer.CommandLog.CommandsToLog,
+		VerboseCommands:  overlyVerboseCommands,
+		AdditionalFields: config.SmtpServer.CommandLog.AdditionalFields,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SmtpCommandLogger{logger}, nil
+}
@@ -0,0 +1,92 @@
+package smtp
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/listener"
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/protocol/smtp/logging"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	Server struct {
+		ListenPort int
+		ListenHost string
+
+		hostname       string
+		banner         string
+		maxBodyLogSize int
+		listener       *listener.TcpListener
+		connCount      atomic.Uint64
+
+		// Services
+		stallerFactory *stall.ConnStallerFactory
+		logger         *logging.SmtpCommandLogger
+	}
+)
+
+func NewServer(
+	lf fx.Lifecycle,
+	cfg *config.Config,
+	stallerFactory *stall.ConnStallerFactory,
+	logger *logging.SmtpCommandLogger,
+) (*Server, error) {
+	if !cfg.SmtpServer.Enabled {
+		return nil, nil
+	}
+
+	server := &Server{
+		ListenPort: cfg.SmtpServer.Port,
+		ListenHost: cfg.SmtpServer.Host,
+
+		hostname:       cfg.SmtpServer.Hostname,
+		banner:         cfg.SmtpServer.Banner,
+		maxBodyLogSize: cfg.SmtpServer.MaxBodyLogSize,
+
+		stallerFactory: stallerFactory,
+		logger:         logger,
+	}
+
+	server.listener = listener.NewTcpListener("smtp", server.ListenHost, server.ListenPort, server.handleConn)
+
+	lf.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			zap.L().Sugar().Info("Shutting down SMTP server")
+			return server.listener.Stop()
+		},
+	})
+
+	return server, nil
+}
+
+// Starts listening for SMTP connections. Blocks until the server is stopped
+func (s *Server) Start() error {
+	return s.listener.Start()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	ctx := coreLogging.NewConnContext(s.connCount.Add(1), conn)
+	logger := s.logger.WithContext(ctx)
+	startTime := time.Now()
+
+	logger.Log("client_connected")
+	defer func() {
+		logger.Log("client_disconnected", zap.Duration("duration", time.Since(startTime)))
+	}()
+
+	staller, err := s.stallerFactory.FromConn("smtp", conn, conn, nil)
+	if err != nil {
+		zap.L().Warn("Failed to create SMTP staller", zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	newSession(s, conn, staller, logger).run()
+}
@@ -0,0 +1,404 @@
+package smtp
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/rand"
+	"go.uber.org/zap"
+)
+
+// Lines longer than this are truncated (RFC 5321 limits text lines to 1000 bytes including CRLF)
+const maxLineLength = 1024
+
+// Extensions advertised in reply to EHLO. STARTTLS is left out on purpose so clients stay in plain text
+var ehloExtensions = []string{
+	"PIPELINING",
+	"SIZE 52428800",
+	"VRFY",
+	"ETRN",
+	"AUTH PLAIN LOGIN",
+	"AUTH=PLAIN LOGIN",
+	"ENHANCEDSTATUSCODES",
+	"8BITMIME",
+	"DSN",
+	"SMTPUTF8",
+}
+
+// Returned along with the error ending the conversation once the reply to the last command has been sent in full
+var errReplySent = errors.New("reply sent")
+
+type (
+	// A single SMTP conversation with a client. Every reply is dripped to the client through the staller
+	session struct {
+		server  *Server
+		conn    net.Conn
+		reader  *bufio.Reader
+		staller *stall.ConnStaller
+		logger  logging.CommandLogger
+		random  *rand.SeededRand
+
+		// Envelope
+		helo     string
+		authUser string
+		mailFrom string
+		rcptTo   []string
+	}
+)
+
+func newSession(server *Server, conn net.Conn, staller *stall.ConnStaller, logger logging.CommandLogger) *session {
+	return &session{
+		server:  server,
+		conn:    conn,
+		reader:  bufio.NewReaderSize(conn, maxLineLength),
+		staller: staller,
+		logger:  logger,
+		random:  rand.NewSeededRandFromTime(),
+	}
+}
+
+func (s *session) run() {
+	err := s.serve()
+
+	// Let the client know why the connection is being dropped. Clients already sent a reply are not sent a second one
+	if errors.Is(err, stall.ErrStallDeadlineReached) && !errors.Is(err, errReplySent) {
+		s.flush(fmt.Sprintf("421 4.4.2 %s Error: timeout exceeded\r\n", s.server.hostname))
+	}
+
+	s.staller.Halt(err)
+}
+
+func (s *session) serve() error {
+	err := s.reply(220,
+		fmt.Sprintf("%s %s", s.server.hostname, s.server.banner),
+		"Unauthorized use of this system is prohibited.",
+		"All activity is logged and monitored.",
+		fmt.Sprintf("%s ESMTP ready", s.server.hostname),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+
+		if err := s.handleCommand(strings.ToUpper(verb), arg, line); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *session) handleCommand(verb string, arg string, line string) error {
+	switch verb {
+	case "HELO":
+		s.helo = arg
+		s.logger.Log("helo", zap.String("helo", arg))
+		return s.reply(250, s.server.hostname)
+	case "EHLO":
+		s.helo = arg
+		s.logger.Log("ehlo", zap.String("helo", arg))
+		greeting := fmt.Sprintf("%s Hello %s [%s]", s.server.hostname, arg, logging.GetHost(s.conn.RemoteAddr()))
+		return s.reply(250, append([]string{greeting}, ehloExtensions...)...)
+	case "AUTH":
+		return s.handleAuth(arg)
+	case "MAIL":
+		return s.handleMail(arg)
+	case "RCPT":
+		return s.handleRcpt(arg)
+	case "DATA":
+		return s.handleData()
+	case "RSET":
+		s.logger.Log("rset")
+		s.resetEnvelope()
+		return s.reply(250, "2.0.0 Ok")
+	case "NOOP":
+		s.logger.Log("noop")
+		return s.reply(250, "2.0.0 Ok")
+	case "VRFY":
+		s.logger.Log("vrfy", zap.String("address", arg))
+		return s.reply(252, "2.0.0 "+arg)
+	case "STARTTLS":
+		s.logger.Log("starttls")
+		return s.reply(454, "4.7.0 TLS not available due to local problem")
+	case "HELP":
+		return s.reply(214, "2.0.0 Ok")
+	case "QUIT":
+		s.logger.Log("quit")
+		if err := s.reply(221, "2.0.0 Bye"); err != nil {
+			return err
+		}
+		return io.EOF
+	default:
+		s.logger.Log("unknown_command", zap.String("command", line))
+		return s.reply(502, "5.5.2 Error: command not recognized")
+	}
+}
+
+// Accepts AUTH PLAIN and AUTH LOGIN with any credentials given
+func (s *session) handleAuth(arg string) error {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		return s.reply(501, "5.5.4 Syntax: AUTH mechanism")
+	}
+
+	var user, pass string
+	mechanism := strings.ToUpper(fields[0])
+
+	switch mechanism {
+	case "PLAIN":
+		response, err := s.getAuthResponse(fields, "")
+		if err != nil {
+			return err
+		}
+
+		// The response is made up of "authzid\0authcid\0passwd"
+		parts := strings.SplitN(decodeBase64(response), "\x00", 3)
+		if len(parts) == 3 {
+			user, pass = parts[1], parts[2]
+		} else {
+			pass = strings.Join(parts, "")
+		}
+	case "LOGIN":
+		response, err := s.getAuthResponse(fields, "Username:")
+		if err != nil {
+			return err
+		}
+		user = decodeBase64(response)
+
+		response, err = s.getAuthResponse(nil, "Password:")
+		if err != nil {
+			return err
+		}
+		pass = decodeBase64(response)
+	default:
+		return s.reply(504, "5.5.4 Unrecognized authentication type")
+	}
+
+	s.authUser = user
+	s.logger.Log("auth_user",
+		zap.String("mechanism", mechanism),
+		zap.String("user", user),
+		zap.String("pass", pass),
+	)
+
+	return s.reply(235, "2.7.0 Authentication successful")
+}
+
+// Gets the response to an authentication challenge. The response can also be given up front
+// as part of the AUTH command itself (i.e "AUTH PLAIN <response>")
+func (s *session) getAuthResponse(fields []string, challenge string) (string, error) {
+	if len(fields) > 1 {
+		return fields[1], nil
+	}
+
+	if err := s.reply(334, base64.StdEncoding.EncodeToString([]byte(challenge))); err != nil {
+		return "", err
+	}
+
+	return s.readLine()
+}
+
+func (s *session) handleMail(arg string) error {
+	address, params, ok := parsePath(arg, "FROM:")
+	if !ok {
+		return s.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+	}
+
+	s.resetEnvelope()
+	s.mailFrom = address
+	s.logger.Log("mail_from", zap.String("address", address), zap.String("params", params))
+
+	return s.reply(250, "2.1.0 Ok")
+}
+
+func (s *session) handleRcpt(arg string) error {
+	address, params, ok := parsePath(arg, "TO:")
+	if !ok {
+		return s.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+	}
+
+	s.rcptTo = append(s.rcptTo, address)
+	s.logger.Log("rcpt_to", zap.String("address", address), zap.String("params", params))
+
+	return s.reply(250, "2.1.5 Ok")
+}
+
+// Reads the message body then drags out the final reply for as long as the staller allows.
+// The message is always "queued" in the end so clients believe the message went through
+func (s *session) handleData() error {
+	if len(s.rcptTo) == 0 {
+		return s.reply(503, "5.5.1 Error: need RCPT command")
+	}
+
+	s.logger.Log("data")
+	if err := s.reply(354, "End data with <CR><LF>.<CR><LF>"); err != nil {
+		return err
+	}
+
+	body, size, err := s.readBody()
+	if err != nil {
+		return err
+	}
+
+	queueId := fmt.Sprintf("%X", s.random.RandomInt(0x1000000000, 0xFFFFFFFFFF))
+	s.logger.Log("message",
+		zap.String("queue_id", queueId),
+		zap.String("helo", s.helo),
+		zap.String("auth_user", s.authUser),
+		zap.String("mail_from", s.mailFrom),
+		zap.Strings("rcpt_to", s.rcptTo),
+		zap.Int("size", size),
+		zap.Bool("body_truncated", size > len(body)),
+		zap.String("body", body),
+	)
+	s.resetEnvelope()
+
+	final := fmt.Sprintf("250 2.0.0 Ok: queued as %s\r\n", queueId)
+	for {
+		if err := s.write("250-2.6.0 Message scan in progress\r\n"); err != nil {
+			if errors.Is(err, stall.ErrStallDeadlineReached) {
+				s.flush(final)
+				return fmt.Errorf("%w: %w", errReplySent, err)
+			}
+			return err
+		}
+	}
+}
+
+// Reads a message body up until the terminating "." line. Only the first "max_body_log_size"
+// bytes are kept with the total size of the body returned alongside it
+func (s *session) readBody() (string, int, error) {
+	var body strings.Builder
+	size := 0
+
+	for {
+		line, err := s.readRawLine()
+		if err != nil {
+			return "", 0, err
+		}
+
+		if line == ".\r\n" || line == ".\n" {
+			return body.String(), size, nil
+		}
+
+		// Undo dot stuffing
+		line = strings.TrimPrefix(line, ".")
+		size += len(line)
+
+		if remaining := s.server.maxBodyLogSize - body.Len(); remaining > 0 {
+			if len(line) > remaining {
+				line = line[:remaining]
+			}
+			body.WriteString(line)
+		}
+	}
+}
+
+func (s *session) resetEnvelope() {
+	s.mailFrom = ""
+	s.rcptTo = nil
+}
+
+// Reads a single line from the client without the line ending
+func (s *session) readLine() (string, error) {
+	line, err := s.readRawLine()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Reads a single line from the client. Reads are bound by the time the staller has left
+// so clients that go quiet are dropped once the staller runs out of time
+func (s *session) readRawLine() (string, error) {
+	if err := s.conn.SetReadDeadline(time.Now().Add(s.staller.GetRemainingTime())); err != nil {
+		return "", err
+	}
+
+	chunk, err := s.reader.ReadSlice('\n')
+	line := string(chunk)
+
+	// Overly long lines are truncated
+	for errors.Is(err, bufio.ErrBufferFull) {
+		_, err = s.reader.ReadSlice('\n')
+	}
+
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return "", stall.ErrStallDeadlineReached
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return line, nil
+}
+
+// Slowly writes a (potentially multi-line) reply to the client
+func (s *session) reply(code int, lines ...string) error {
+	var reply strings.Builder
+	for i, line := range lines {
+		separator := "-"
+		if i == len(lines)-1 {
+			separator = " "
+		}
+
+		fmt.Fprintf(&reply, "%d%s%s\r\n", code, separator, line)
+	}
+
+	return s.write(reply.String())
+}
+
+// Slowly writes data to the client. Should the staller run out of time part way through
+// the rest of the data is flushed so the client is never left with a partial reply
+func (s *session) write(data string) error {
+	written, err := s.staller.Write([]byte(data))
+	if errors.Is(err, stall.ErrStallDeadlineReached) {
+		s.flush(data[written:])
+	}
+
+	return err
+}
+
+// Writes data to the client straight away (used for wrapping up the session)
+func (s *session) flush(data string) {
+	if _, err := s.staller.WriteNow([]byte(data)); err != nil {
+		zap.L().Debug("Failed to flush SMTP reply", zap.Error(err))
+	}
+}
+
+// Parses the path given to MAIL and RCPT (i.e "FROM:<user@example.com> SIZE=100")
+func parsePath(arg string, prefix string) (string, string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	address, params, _ := strings.Cut(strings.TrimSpace(arg[len(prefix):]), " ")
+	return strings.Trim(address, "<>"), strings.TrimSpace(params), true
+}
+
+func decodeBase64(data string) string {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return data
+	}
+
+	return string(decoded)
+}
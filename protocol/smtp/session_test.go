@@ -0,0 +1,185 @@
+package smtp
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/rand"
+	"go.uber.org/zap"
+)
+
+type nopCommandLogger struct{}
+
+func (nopCommandLogger) Log(string, ...zap.Field) {}
+
+var finalDataReply = regexp.MustCompile(`250 2\.0\.0 Ok: queued as [0-9A-F]+\r\n$`)
+
+// Every command gets exactly one reply. Once the final reply to DATA is sent the connection is closed without a 421
+func TestSessionRepliesOnceWhenDeadlineReached(t *testing.T) {
+	tests := []struct {
+		name      string
+		commands  string
+		wantEnd   *regexp.Regexp
+		want421   bool
+		wantCount int
+	}{
+		{
+			name:      "deadline reached during data",
+			commands:  "EHLO bot\r\nMAIL FROM:<a@example.com>\r\nRCPT TO:<b@example.com>\r\nDATA\r\nSubject: hi\r\n\r\nhello\r\n.\r\n",
+			wantEnd:   finalDataReply,
+			wantCount: 6,
+		},
+		{
+			name:      "deadline reached waiting for a command",
+			commands:  "HELO bot\r\n",
+			wantEnd:   regexp.MustCompile(`421 4\.4\.2 mail\.example\.com Error: timeout exceeded\r\n$`),
+			want421:   true,
+			wantCount: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := runTestSession(t, test.commands)
+			if !test.wantEnd.MatchString(output) {
+				t.Fatalf("unexpected end of conversation %q", output)
+			}
+
+			if strings.Contains(output, "421 ") != test.want421 {
+				t.Errorf("expected a 421 reply %t got %q", test.want421, output)
+			}
+
+			if count := countReplies(output); count != test.wantCount {
+				t.Errorf("expected %d replies got %d in %q", test.wantCount, count, output)
+			}
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		arg        string
+		wantPath   string
+		wantParams string
+		wantOk     bool
+	}{
+		{arg: "FROM:<user@example.com>", wantPath: "user@example.com", wantOk: true},
+		{arg: "from: <user@example.com> SIZE=100", wantPath: "user@example.com", wantParams: "SIZE=100", wantOk: true},
+		{arg: "FROM:<>", wantPath: "", wantOk: true},
+		{arg: "FROM:user@example.com", wantPath: "user@example.com", wantOk: true},
+		{arg: "FROM", wantOk: false},
+		{arg: "TO:<user@example.com>", wantOk: false},
+		{arg: "", wantOk: false},
+	}
+
+	for _, test := range tests {
+		path, params, ok := parsePath(test.arg, "FROM:")
+		if ok != test.wantOk || (ok && (path != test.wantPath || params != test.wantParams)) {
+			t.Errorf("parsePath(%q) expected %q %q %t got %q %q %t", test.arg, test.wantPath, test.wantParams, test.wantOk, path, params, ok)
+		}
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantBody string
+		wantSize int
+		wantErr  bool
+	}{
+		{name: "empty body", input: ".\r\n", wantBody: "", wantSize: 0},
+		{name: "bare line feeds", input: "a\nb\n.\n", wantBody: "a\nb\n", wantSize: 4},
+		{name: "dot stuffing undone", input: "..hidden\r\n.\r\n", wantBody: ".hidden\r\n", wantSize: 9},
+		{name: "logged body truncated", input: "0123456789\r\n0123456789\r\n.\r\n", wantBody: "0123456789\r\n0123", wantSize: 24},
+		{name: "overly long lines truncated", input: strings.Repeat("a", maxLineLength*3) + "\r\n.\r\n", wantBody: strings.Repeat("a", 16), wantSize: maxLineLength},
+		{name: "no terminating line", input: "a\r\nb\r\n", wantErr: true},
+		{name: "terminating line cut short", input: "a\r\n.", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, client := newTestSession(t)
+			go func() {
+				io.WriteString(client, test.input)
+				client.Close()
+			}()
+
+			body, size, err := s.readBody()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %t got %v", test.wantErr, err)
+			}
+
+			if body != test.wantBody || size != test.wantSize {
+				t.Errorf("expected %q of size %d got %q of size %d", test.wantBody, test.wantSize, body, size)
+			}
+		})
+	}
+}
+
+// Counts the replies in the output. Multiline replies count once
+func countReplies(output string) int {
+	count := 0
+	for _, line := range strings.SplitAfter(output, "\r\n") {
+		if len(line) > 3 && line[3] == ' ' {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Runs a session sending it the commands and returns everything sent back once the staller runs out of time
+func runTestSession(t *testing.T, commands string) string {
+	t.Helper()
+
+	s, client := newTestSession(t)
+	go s.run()
+
+	go func() {
+		io.WriteString(client, commands)
+	}()
+
+	client.SetReadDeadline(time.Now().Add(time.Second * 10))
+	output, err := io.ReadAll(client)
+	if err != nil {
+		t.Fatalf("failed to read the conversation %v", err)
+	}
+
+	return string(output)
+}
+
+func newTestSession(t *testing.T) (*session, net.Conn) {
+	t.Helper()
+
+	scheduler := stall.NewSchedulerWithOptions(&stall.SchedulerOptions{Tick: time.Millisecond})
+	scheduler.Start()
+	t.Cleanup(scheduler.Stop)
+
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	staller := stall.NewConnStaller(&stall.ConnStallerOptions{
+		Conn:         server,
+		TransferRate: time.Microsecond * 100,
+		Scheduler:    scheduler,
+		Timeout:      time.Millisecond * 500,
+	})
+
+	return &session{
+		server:  &Server{hostname: "mail.example.com", banner: "ESMTP Postfix", maxBodyLogSize: 16},
+		conn:    server,
+		reader:  bufio.NewReaderSize(server, maxLineLength),
+		staller: staller,
+		logger:  nopCommandLogger{},
+		random:  rand.NewSeededRand(1),
+	}, client
+}
@@ -0,0 +1,56 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+)
+
+// RFC 4253 allows for lines (up to 255 bytes including CRLF) to be sent before the version string
+// of the server. Clients will wait for the version string for as long as lines keep coming in
+const maxBannerLineLength = 253
+
+// Drips an endless stream of pre-banner lines to the client (Like endlessh). The handshake is never completed
+func (s *Server) handleBanner(conn net.Conn, logger logging.CommandLogger) {
+	staller, err := s.stallerFactory.FromConn("ssh", conn, conn, nil)
+	if err != nil {
+		zap.L().Warn("Failed to create SSH staller", zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	secretGenerators := s.secretGenerators.ForClient(secrets.HoneytokenClient{
+		Protocol: "ssh",
+		Ip:       logging.GetHost(conn.RemoteAddr()),
+	})
+
+	for {
+		line := generateBannerLine(secretGenerators)
+		logger.Log("banner_line", zap.String("line", line))
+
+		if _, err := staller.Write([]byte(line + "\r\n")); err != nil {
+			staller.Halt(err)
+			return
+		}
+	}
+}
+
+// Generates a single pre-banner line made up of a fake secret
+func generateBannerLine(secretGenerators *secrets.SecretGeneratorCollection) string {
+	gen := secretGenerators.GetRandomGenerator()
+	line := fmt.Sprintf("%s=%s", gen.NameGenerator.Generate(), secretGenerators.Secret(gen))
+	line = strings.NewReplacer("\r", " ", "\n", " ").Replace(line)
+
+	// Lines starting with "SSH-" would be interpreted as the version string by the client
+	line = strings.TrimPrefix(line, "SSH-")
+
+	if len(line) > maxBannerLineLength {
+		line = line[:maxBannerLineLength]
+	}
+
+	return line
+}
@@ -0,0 +1,52 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"os"
+
+	"github.com/ryanolee/go-pot/config"
+	sshLib "golang.org/x/crypto/ssh"
+)
+
+// Gets the host keys for the SSH server. Either from the configured path or generated in memory
+// in the event that a host key is not provided. Both an RSA and ED25519 key are generated to look like
+// a stock OpenSSH install
+func getHostKeys(c *config.Config) ([]sshLib.Signer, error) {
+	if c.SshServer.HostKeyPath != "" {
+		contents, err := os.ReadFile(c.SshServer.HostKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err := sshLib.ParsePrivateKey(contents)
+		if err != nil {
+			return nil, err
+		}
+
+		return []sshLib.Signer{signer}, nil
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaSigner, err := sshLib.NewSignerFromKey(rsaKey)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	ed25519Signer, err := sshLib.NewSignerFromKey(ed25519Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return []sshLib.Signer{rsaSigner, ed25519Signer}, nil
+}
@@ -0,0 +1,43 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ryanolee/go-pot/secrets"
+)
+
+// Generates an endless stream of shell environment variables made up of fake secrets
+type envGenerator struct {
+	secrets *secrets.SecretGeneratorCollection
+}
+
+func newEnvGenerator(secrets *secrets.SecretGeneratorCollection) *envGenerator {
+	return &envGenerator{
+		secrets: secrets,
+	}
+}
+
+func (g *envGenerator) Start() []byte {
+	return []byte{}
+}
+
+func (g *envGenerator) Generate() []byte {
+	gen := g.secrets.GetRandomGenerator()
+	name := strings.ToUpper(strings.NewReplacer("-", "_", " ", "_", ".", "_").Replace(gen.NameGenerator.Generate()))
+	value := strings.NewReplacer("\r", "", "\n", "", "'", "").Replace(g.secrets.Secret(gen))
+
+	return []byte(fmt.Sprintf("%s='%s'", name, value))
+}
+
+func (g *envGenerator) GenerateChunk() []byte {
+	return g.Generate()
+}
+
+func (g *envGenerator) ChunkSeparator() []byte {
+	return []byte("\n")
+}
+
+func (g *envGenerator) End() []byte {
+	return []byte{}
+}
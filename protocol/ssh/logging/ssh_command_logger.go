@@ -0,0 +1,35 @@
+package logging
+
+import (
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+)
+
+type (
+	SshCommandLogger struct {
+		*logging.ConnCommandLogger
+	}
+)
+
+// Commands that are not included in the "all" command group
+// but are verbose enough to be included in the "all_detailed" group
+var overlyVerboseCommands = []string{
+	"banner_line",
+}
+
+func NewSshCommandLogger(config *config.Config) (*SshCommandLogger, error) {
+	logger, err := logging.NewConnCommandLogger(&logging.ConnCommandLoggerOptions{
+		Protocol:         "ssh",
+		Path:             config.SshServer.CommandLog.Path,
+		FallbackPath:     config.Logging.Path,
+		CommandsToLog:    config.SshServer.CommandLog.CommandsToLog,
+		VerboseCommands:  overlyVerboseCommands,
+		AdditionalFields: config.SshServer.CommandLog.AdditionalFields,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &SshCommandLogger{logger}, nil
+}
@@ -0,0 +1,115 @@
+package ssh
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/listener"
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/generator/source"
+	"github.com/ryanolee/go-pot/protocol/ssh/logging"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	sshLib "golang.org/x/crypto/ssh"
+)
+
+const (
+	ModeBanner = "banner"
+	ModeShell  = "shell"
+)
+
+type (
+	Server struct {
+		ListenPort int
+		ListenHost string
+
+		mode          string
+		serverVersion string
+		hostname      string
+		hostKeys      []sshLib.Signer
+		listener      *listener.TcpListener
+		connCount     atomic.Uint64
+
+		// Services
+		stallerFactory   *stall.ConnStallerFactory
+		configGenerators *generator.ConfigGeneratorCollection
+		tabularSchemas   *source.TabularSchemaCollection
+		secretGenerators *secrets.SecretGeneratorCollection
+		logger           *logging.SshCommandLogger
+	}
+)
+
+func NewServer(
+	lf fx.Lifecycle,
+	cfg *config.Config,
+	stallerFactory *stall.ConnStallerFactory,
+	configGenerators *generator.ConfigGeneratorCollection,
+	tabularSchemas *source.TabularSchemaCollection,
+	secretGenerators *secrets.SecretGeneratorCollection,
+	logger *logging.SshCommandLogger,
+) (*Server, error) {
+	if !cfg.SshServer.Enabled {
+		return nil, nil
+	}
+
+	hostKeys, err := getHostKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &Server{
+		ListenPort: cfg.SshServer.Port,
+		ListenHost: cfg.SshServer.Host,
+
+		mode:          cfg.SshServer.Mode,
+		serverVersion: cfg.SshServer.ServerVersion,
+		hostname:      cfg.SshServer.ShellHostname,
+		hostKeys:      hostKeys,
+
+		stallerFactory:   stallerFactory,
+		configGenerators: configGenerators,
+		tabularSchemas:   tabularSchemas,
+		secretGenerators: secretGenerators,
+		logger:           logger,
+	}
+
+	server.listener = listener.NewTcpListener("ssh", server.ListenHost, server.ListenPort, server.handleConn)
+
+	lf.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			zap.L().Sugar().Info("Shutting down SSH server")
+			return server.listener.Stop()
+		},
+	})
+
+	return server, nil
+}
+
+// Starts listening for SSH connections. Blocks until the server is stopped
+func (s *Server) Start() error {
+	return s.listener.Start()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	ctx := coreLogging.NewConnContext(s.connCount.Add(1), conn)
+	logger := s.logger.WithContext(ctx)
+	startTime := time.Now()
+
+	logger.Log("client_connected")
+	defer func() {
+		logger.Log("client_disconnected", zap.Duration("duration", time.Since(startTime)))
+	}()
+
+	switch s.mode {
+	case ModeBanner:
+		s.handleBanner(conn, logger)
+	default:
+		s.handleShell(conn, ctx, logger)
+	}
+}
@@ -0,0 +1,387 @@
+package ssh
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/generator"
+	"github.com/ryanolee/go-pot/generator/encoder"
+	"github.com/ryanolee/go-pot/rand"
+	"github.com/ryanolee/go-pot/secrets"
+	"go.uber.org/zap"
+	sshLib "golang.org/x/crypto/ssh"
+)
+
+const motd = `Welcome to Ubuntu 22.04.3 LTS (GNU/Linux 5.15.0-91-generic x86_64)
+
+ * Documentation:  https://help.ubuntu.com
+ * Management:     https://landscape.canonical.com
+ * Support:        https://ubuntu.com/advantage
+
+Last login: %s from %s
+`
+
+// Commands that read a file given to them as an argument. The output of these is
+// generated based on the extension of the file requested
+var fileReadingCommands = map[string]bool{
+	"cat":     true,
+	"less":    true,
+	"more":    true,
+	"head":    true,
+	"tail":    true,
+	"strings": true,
+	"nano":    true,
+	"vi":      true,
+	"vim":     true,
+}
+
+type (
+	// A single "session" channel opened by an authenticated client
+	shellSession struct {
+		server  *Server
+		channel sshLib.Channel
+		staller *stall.ConnStaller
+		logger  logging.CommandLogger
+		secrets *secrets.SecretGeneratorCollection
+		pty     bool
+	}
+)
+
+// Completes the SSH handshake accepting any password given and serves a fake shell to the client
+func (s *Server) handleShell(conn net.Conn, ctx *logging.ConnContext, logger logging.CommandLogger) {
+	sshConn, channels, requests, err := sshLib.NewServerConn(conn, s.newServerConfig(logger))
+	if err != nil {
+		logger.Log("handshake_failed", zap.Error(err))
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+
+	ctx.ClientVersion = string(sshConn.ClientVersion())
+	go sshLib.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			logger.Log("channel_rejected", zap.String("channel_type", newChannel.ChannelType()), zap.Binary("extra_data", newChannel.ExtraData()))
+			if err := newChannel.Reject(sshLib.Prohibited, "administratively prohibited"); err != nil {
+				return
+			}
+			continue
+		}
+
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+
+		staller, err := s.stallerFactory.FromConn("ssh", conn, channel, &stall.ConnStallerHooks{
+			// Drop the whole connection once any of the sessions has been stalled for long enough
+			OnClose: func(_ *stall.ConnStaller) {
+				sshConn.Close()
+			},
+		})
+
+		if err != nil {
+			zap.L().Warn("Failed to create SSH staller", zap.Error(err))
+			channel.Close()
+			return
+		}
+
+		session := &shellSession{
+			server:  s,
+			channel: channel,
+			staller: staller,
+			logger:  logger,
+			secrets: s.secretGenerators.ForClient(secrets.HoneytokenClient{
+				Protocol:  "ssh",
+				Ip:        logging.GetHost(conn.RemoteAddr()),
+				RequestId: fmt.Sprintf("ssh-%d", ctx.Id),
+			}),
+		}
+
+		go session.handleRequests(channelRequests)
+	}
+}
+
+func (s *Server) newServerConfig(logger logging.CommandLogger) *sshLib.ServerConfig {
+	sshConfig := &sshLib.ServerConfig{
+		ServerVersion: s.serverVersion,
+		MaxAuthTries:  -1,
+
+		PasswordCallback: func(conn sshLib.ConnMetadata, password []byte) (*sshLib.Permissions, error) {
+			logger.Log("auth_user",
+				zap.String("user", conn.User()),
+				zap.String("pass", string(password)),
+				zap.String("client_version", string(conn.ClientVersion())),
+				zap.String("client_ip", conn.RemoteAddr().String()),
+			)
+
+			return &sshLib.Permissions{}, nil
+		},
+
+		KeyboardInteractiveCallback: func(conn sshLib.ConnMetadata, client sshLib.KeyboardInteractiveChallenge) (*sshLib.Permissions, error) {
+			answers, err := client("", "", []string{"Password: "}, []bool{false})
+			if err != nil {
+				return nil, err
+			}
+
+			logger.Log("auth_user",
+				zap.String("user", conn.User()),
+				zap.Strings("pass", answers),
+				zap.String("client_version", string(conn.ClientVersion())),
+				zap.String("client_ip", conn.RemoteAddr().String()),
+			)
+
+			return &sshLib.Permissions{}, nil
+		},
+
+		// Public keys are always rejected so clients fall back to giving up a password
+		PublicKeyCallback: func(conn sshLib.ConnMetadata, key sshLib.PublicKey) (*sshLib.Permissions, error) {
+			logger.Log("auth_public_key",
+				zap.String("user", conn.User()),
+				zap.String("key_type", key.Type()),
+				zap.String("fingerprint", sshLib.FingerprintSHA256(key)),
+				zap.String("client_version", string(conn.ClientVersion())),
+				zap.String("client_ip", conn.RemoteAddr().String()),
+			)
+
+			return nil, errors.New("public key rejected")
+		},
+	}
+
+	for _, key := range s.hostKeys {
+		sshConfig.AddHostKey(key)
+	}
+
+	return sshConfig
+}
+
+// Handles requests made against a session channel. Requests need to be serviced for the
+// duration of the session so the shell itself runs in its own goroutine
+func (s *shellSession) handleRequests(requests <-chan *sshLib.Request) {
+	started := false
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			s.pty = true
+			s.reply(req, true)
+		case "env", "window-change":
+			s.reply(req, true)
+		case "shell":
+			s.logger.Log("session_request", zap.String("request_type", req.Type))
+			s.reply(req, !started)
+			if !started {
+				started = true
+				go s.runShell()
+			}
+		case "exec":
+			command := parseStringPayload(req.Payload)
+			s.logger.Log("exec", zap.String("command", command))
+			s.reply(req, !started)
+			if !started {
+				started = true
+				go s.runExec(command)
+			}
+		default:
+			s.logger.Log("session_request", zap.String("request_type", req.Type), zap.String("payload", parseStringPayload(req.Payload)))
+			s.reply(req, false)
+		}
+	}
+
+	// The client closed the session before anything was run
+	if !started {
+		s.staller.Halt(io.EOF)
+	}
+}
+
+func (s *shellSession) reply(req *sshLib.Request, ok bool) {
+	if !req.WantReply {
+		return
+	}
+
+	if err := req.Reply(ok, nil); err != nil {
+		zap.L().Debug("Failed to reply to SSH request", zap.Error(err))
+	}
+}
+
+// Serves an interactive shell. The message of the day is sent slowly followed by a prompt.
+// The first command given will stream generated secrets until the staller runs out of time
+func (s *shellSession) runShell() {
+	random := rand.NewSeededRandFromTime()
+	lastLogin := time.Now().Add(-time.Duration(random.RandomInt(1, 72)) * time.Hour).Format("Mon Jan _2 15:04:05 2006")
+	lastLoginIp := fmt.Sprintf("10.%d.%d.%d", random.RandomInt(0, 255), random.RandomInt(0, 255), random.RandomInt(1, 255))
+
+	if err := s.write(fmt.Sprintf(motd, lastLogin, lastLoginIp)); err != nil {
+		s.staller.Halt(err)
+		return
+	}
+
+	reader := bufio.NewReader(s.channel)
+	for {
+		if err := s.write(s.prompt()); err != nil {
+			s.staller.Halt(err)
+			return
+		}
+
+		command, err := s.readLine(reader)
+		if err != nil {
+			s.staller.Halt(err)
+			return
+		}
+
+		command = strings.TrimSpace(command)
+		if command == "" {
+			continue
+		}
+
+		s.logger.Log("command", zap.String("command", command))
+
+		if command == "exit" || command == "logout" {
+			s.exit(io.EOF)
+			return
+		}
+
+		if err := s.streamCommandOutput(command); err != nil {
+			s.exit(err)
+			return
+		}
+	}
+}
+
+// Runs a single command given as part of an "exec" request
+func (s *shellSession) runExec(command string) {
+	s.exit(s.streamCommandOutput(command))
+}
+
+// Streams generated output for a command until the staller runs out of time or the client disconnects
+func (s *shellSession) streamCommandOutput(command string) error {
+	gen := s.getGeneratorForCommand(command)
+
+	if err := s.write(string(gen.Start())); err != nil {
+		return err
+	}
+
+	for {
+		if err := s.write(string(gen.GenerateChunk())); err != nil {
+			s.flush(string(gen.End()) + "\n")
+			return err
+		}
+
+		if err := s.write(string(gen.ChunkSeparator())); err != nil {
+			s.flush(string(gen.End()) + "\n")
+			return err
+		}
+	}
+}
+
+// Picks a generator based on the file a command is trying to read. Commands that don't read
+// files get a stream of environment variables
+func (s *shellSession) getGeneratorForCommand(command string) generator.Generator {
+	fields := strings.Fields(command)
+	if len(fields) > 1 && fileReadingCommands[fields[0]] {
+		path := fields[len(fields)-1]
+		gen := generator.GetGeneratorForPath(path, encoder.GetEncoderForPath(path), s.server.configGenerators, s.server.tabularSchemas, s.secrets, generator.NewStreamSource())
+		if gen != nil {
+			return gen
+		}
+	}
+
+	return newEnvGenerator(s.secrets)
+}
+
+// Reads a single line from the client echoing input back in the event a pty was requested
+func (s *shellSession) readLine(reader *bufio.Reader) (string, error) {
+	line := make([]byte, 0, 64)
+	for {
+		char, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch char {
+		case '\r', '\n':
+			if s.pty {
+				s.echo("\r\n")
+			}
+			return string(line), nil
+		case 0x7f, 0x08:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				s.echo("\b \b")
+			}
+		case 0x03:
+			s.echo("^C\r\n")
+			return "", nil
+		case 0x04:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+		default:
+			line = append(line, char)
+			s.echo(string(char))
+		}
+	}
+}
+
+func (s *shellSession) prompt() string {
+	return fmt.Sprintf("root@%s:~# ", s.server.hostname)
+}
+
+// Writes data slowly to the client
+func (s *shellSession) write(data string) error {
+	_, err := s.staller.Write([]byte(s.normalise(data)))
+	return err
+}
+
+// Writes data to the client straight away (used for wrapping up the session)
+func (s *shellSession) flush(data string) {
+	if _, err := s.staller.WriteNow([]byte(s.normalise(data))); err != nil {
+		zap.L().Debug("Failed to flush SSH output", zap.Error(err))
+	}
+}
+
+func (s *shellSession) echo(data string) {
+	if !s.pty {
+		return
+	}
+
+	if _, err := s.channel.Write([]byte(data)); err != nil {
+		zap.L().Debug("Failed to echo SSH input", zap.Error(err))
+	}
+}
+
+// Terminals attached to a pty expect CRLF line endings
+func (s *shellSession) normalise(data string) string {
+	if !s.pty {
+		return data
+	}
+
+	return strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// Sends the exit status for the session and halts the staller
+func (s *shellSession) exit(cause error) {
+	status := struct{ Status uint32 }{0}
+	if _, err := s.channel.SendRequest("exit-status", false, sshLib.Marshal(&status)); err != nil {
+		zap.L().Debug("Failed to send SSH exit status", zap.Error(err))
+	}
+
+	s.staller.Halt(cause)
+}
+
+// Parses a payload made up of a single SSH string (exec and subsystem requests)
+func parseStringPayload(payload []byte) string {
+	var value struct{ Value string }
+	if err := sshLib.Unmarshal(payload, &value); err != nil {
+		return ""
+	}
+
+	return value.Value
+}
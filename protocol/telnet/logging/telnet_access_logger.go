@@ -0,0 +1,125 @@
+package logging
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/logging"
+	"go.uber.org/zap"
+)
+
+const (
+	EventConnect    = "connect"
+	EventLogin      = "login"
+	EventCommand    = "command"
+	EventDisconnect = "disconnect"
+)
+
+type (
+	TelnetAccessLogger struct {
+		logger      *zap.Logger
+		fieldsToLog []string
+	}
+
+	TelnetAccessLogEntry struct {
+		Context   *logging.ConnContext
+		Event     string
+		User      string
+		Pass      string
+		Success   *bool
+		Command   string
+		Duration  time.Duration
+		timestamp time.Time
+	}
+
+	fieldLoggers map[string]func(*TelnetAccessLogEntry) string
+)
+
+// Lookup table for pulling fields from a telnet log entry
+var fieldAccessors = fieldLoggers{
+	"timestamp": func(entry *TelnetAccessLogEntry) string {
+		return entry.timestamp.Format(time.RFC3339)
+	},
+	"id": func(entry *TelnetAccessLogEntry) string {
+		return strconv.FormatUint(entry.Context.Id, 10)
+	},
+	"event": func(entry *TelnetAccessLogEntry) string {
+		return entry.Event
+	},
+	"src_ip": func(entry *TelnetAccessLogEntry) string {
+		return logging.GetHost(entry.Context.RemoteAddr)
+	},
+	"src_port": func(entry *TelnetAccessLogEntry) string {
+		return strconv.FormatUint(uint64(logging.GetPort(entry.Context.RemoteAddr)), 10)
+	},
+	"dest_port": func(entry *TelnetAccessLogEntry) string {
+		return strconv.FormatUint(uint64(logging.GetPort(entry.Context.LocalAddr)), 10)
+	},
+	"type": func(entry *TelnetAccessLogEntry) string {
+		return "telnet"
+	},
+	"user": func(entry *TelnetAccessLogEntry) string {
+		return entry.User
+	},
+	"pass": func(entry *TelnetAccessLogEntry) string {
+		return entry.Pass
+	},
+	"success": func(entry *TelnetAccessLogEntry) string {
+		if entry.Success == nil {
+			return ""
+		}
+		return strconv.FormatBool(*entry.Success)
+	},
+	"command": func(entry *TelnetAccessLogEntry) string {
+		return entry.Command
+	},
+	"duration": func(entry *TelnetAccessLogEntry) string {
+		if entry.Duration == 0 {
+			return ""
+		}
+		return entry.Duration.String()
+	},
+}
+
+func NewTelnetAccessLogger(cfg *config.Config) (*TelnetAccessLogger, error) {
+	loggerCfg := zap.NewProductionConfig()
+
+	if cfg.TelnetServer.AccessLog.Path != "" {
+		loggerCfg.OutputPaths = []string{cfg.TelnetServer.AccessLog.Path}
+	} else if cfg.Logging.Path != "" {
+		loggerCfg.OutputPaths = []string{cfg.Logging.Path}
+	} else {
+		loggerCfg.OutputPaths = []string{"stdout"}
+	}
+
+	logger, err := loggerCfg.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelnetAccessLogger{
+		logger:      logger,
+		fieldsToLog: cfg.TelnetServer.AccessLog.FieldsToLog,
+	}, nil
+}
+
+// Logs a single entry with the configured fields
+func (l *TelnetAccessLogger) Log(entry *TelnetAccessLogEntry) {
+	entry.timestamp = time.Now()
+	l.logger.Info("", l.getFields(entry)...)
+}
+
+// Pulls zap fields from the entry
+func (l *TelnetAccessLogger) getFields(entry *TelnetAccessLogEntry) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(l.fieldsToLog))
+	for _, field := range l.fieldsToLog {
+		if accessor, ok := fieldAccessors[field]; ok {
+			zapFields = append(zapFields, zap.String(field, accessor(entry)))
+		} else {
+			zapFields = append(zapFields, zap.String(field, ""))
+		}
+	}
+
+	return zapFields
+}
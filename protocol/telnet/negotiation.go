@@ -0,0 +1,107 @@
+package telnet
+
+import (
+	"bufio"
+)
+
+// Telnet commands and options (RFC 854 / RFC 857 / RFC 858 / RFC 1073)
+const (
+	cmdSe   byte = 240
+	cmdSb   byte = 250
+	cmdWill byte = 251
+	cmdWont byte = 252
+	cmdDo   byte = 253
+	cmdDont byte = 254
+	cmdIac  byte = 255
+
+	optEcho            byte = 1
+	optSuppressGoAhead byte = 3
+	optNaws            byte = 31
+)
+
+// Options offered to the client as soon as it connects. The server takes care of echoing
+// input (so passwords can be hidden) and asks for the window size like a real telnetd would
+var initialNegotiation = []byte{
+	cmdIac, cmdWill, optEcho,
+	cmdIac, cmdWill, optSuppressGoAhead,
+	cmdIac, cmdDo, optNaws,
+}
+
+type (
+	// Reads from a telnet connection stripping out IAC command sequences. Any options
+	// requested by the client that the server does not support are refused
+	telnetReader struct {
+		reader *bufio.Reader
+		send   func([]byte)
+	}
+)
+
+func newTelnetReader(reader *bufio.Reader, send func([]byte)) *telnetReader {
+	return &telnetReader{
+		reader: reader,
+		send:   send,
+	}
+}
+
+// Reads a single byte of data from the client
+func (r *telnetReader) ReadByte() (byte, error) {
+	for {
+		b, err := r.reader.ReadByte()
+		if err != nil || b != cmdIac {
+			return b, err
+		}
+
+		command, err := r.reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch command {
+		case cmdIac:
+			// An escaped 0xFF data byte
+			return cmdIac, nil
+		case cmdDo, cmdDont, cmdWill, cmdWont:
+			option, err := r.reader.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			r.negotiate(command, option)
+		case cmdSb:
+			if err := r.skipSubnegotiation(); err != nil {
+				return 0, err
+			}
+		}
+
+		// Any other commands (NOP, AYT, GA etc) are ignored
+	}
+}
+
+func (r *telnetReader) negotiate(command byte, option byte) {
+	switch command {
+	case cmdDo:
+		if option != optEcho && option != optSuppressGoAhead {
+			r.send([]byte{cmdIac, cmdWont, option})
+		}
+	case cmdWill:
+		if option != optNaws {
+			r.send([]byte{cmdIac, cmdDont, option})
+		}
+	}
+}
+
+// Skips over a subnegotiation (IAC SB ... IAC SE). The contents are of no interest
+func (r *telnetReader) skipSubnegotiation() error {
+	lastWasIac := false
+	for {
+		b, err := r.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		if lastWasIac && b == cmdSe {
+			return nil
+		}
+
+		lastWasIac = b == cmdIac && !lastWasIac
+	}
+}
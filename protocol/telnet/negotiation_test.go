@@ -0,0 +1,66 @@
+package telnet
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTelnetReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		wantData []byte
+		wantSent []byte
+		wantErr  error
+	}{
+		{name: "plain data", input: []byte("root\r\n"), wantData: []byte("root\r\n"), wantErr: io.EOF},
+		{name: "escaped iac", input: []byte{'a', cmdIac, cmdIac, 'b'}, wantData: []byte{'a', cmdIac, 'b'}, wantErr: io.EOF},
+		{name: "supported options accepted", input: []byte{cmdIac, cmdDo, optEcho, cmdIac, cmdWill, optNaws, 'a'}, wantData: []byte("a"), wantErr: io.EOF},
+		{
+			name:     "unsupported options refused",
+			input:    []byte{cmdIac, cmdDo, 24, cmdIac, cmdWill, 24, cmdIac, cmdDont, 24, 'a'},
+			wantData: []byte("a"),
+			wantSent: []byte{cmdIac, cmdWont, 24, cmdIac, cmdDont, 24},
+			wantErr:  io.EOF,
+		},
+		{name: "subnegotiation skipped", input: []byte{cmdIac, cmdSb, optNaws, 0, 80, cmdIac, cmdIac, 24, cmdIac, cmdSe, 'a'}, wantData: []byte("a"), wantErr: io.EOF},
+		{name: "other commands ignored", input: []byte{cmdIac, 241, 'a'}, wantData: []byte("a"), wantErr: io.EOF},
+		{name: "truncated command", input: []byte{'a', cmdIac}, wantData: []byte("a"), wantErr: io.EOF},
+		{name: "truncated option", input: []byte{'a', cmdIac, cmdDo}, wantData: []byte("a"), wantErr: io.EOF},
+		{name: "unterminated subnegotiation", input: []byte{'a', cmdIac, cmdSb, optNaws, 0, 80}, wantData: []byte("a"), wantErr: io.EOF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sent := []byte{}
+			reader := newTelnetReader(bufio.NewReader(bytes.NewReader(test.input)), func(data []byte) {
+				sent = append(sent, data...)
+			})
+
+			data := []byte{}
+			var err error
+			for {
+				var b byte
+				if b, err = reader.ReadByte(); err != nil {
+					break
+				}
+				data = append(data, b)
+			}
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("expected error %v got %v", test.wantErr, err)
+			}
+
+			if !bytes.Equal(data, test.wantData) {
+				t.Errorf("expected data %q got %q", test.wantData, data)
+			}
+
+			if !bytes.Equal(sent, append([]byte{}, test.wantSent...)) {
+				t.Errorf("expected %v to be sent got %v", test.wantSent, sent)
+			}
+		})
+	}
+}
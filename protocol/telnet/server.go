@@ -0,0 +1,105 @@
+package telnet
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/ryanolee/go-pot/core/listener"
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/protocol/telnet/logging"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	Server struct {
+		ListenPort int
+		ListenHost string
+
+		hostname         string
+		credentials      map[string]bool
+		maxLoginAttempts int
+		listener         *listener.TcpListener
+		connCount        atomic.Uint64
+
+		// Services
+		stallerFactory *stall.ConnStallerFactory
+		logger         *logging.TelnetAccessLogger
+	}
+)
+
+func NewServer(
+	lf fx.Lifecycle,
+	cfg *config.Config,
+	stallerFactory *stall.ConnStallerFactory,
+	logger *logging.TelnetAccessLogger,
+) (*Server, error) {
+	if !cfg.TelnetServer.Enabled {
+		return nil, nil
+	}
+
+	credentials := make(map[string]bool, len(cfg.TelnetServer.Credentials))
+	for _, credential := range cfg.TelnetServer.Credentials {
+		credentials[credential] = true
+	}
+
+	server := &Server{
+		ListenPort: cfg.TelnetServer.Port,
+		ListenHost: cfg.TelnetServer.Host,
+
+		hostname:         cfg.TelnetServer.Hostname,
+		credentials:      credentials,
+		maxLoginAttempts: cfg.TelnetServer.MaxLoginAttempts,
+
+		stallerFactory: stallerFactory,
+		logger:         logger,
+	}
+
+	server.listener = listener.NewTcpListener("telnet", server.ListenHost, server.ListenPort, server.handleConn)
+
+	lf.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			zap.L().Sugar().Info("Shutting down Telnet server")
+			return server.listener.Stop()
+		},
+	})
+
+	return server, nil
+}
+
+// Starts listening for Telnet connections. Blocks until the server is stopped
+func (s *Server) Start() error {
+	return s.listener.Start()
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	ctx := coreLogging.NewConnContext(s.connCount.Add(1), conn)
+	startTime := time.Now()
+
+	s.logger.Log(&logging.TelnetAccessLogEntry{Context: ctx, Event: logging.EventConnect})
+	defer func() {
+		s.logger.Log(&logging.TelnetAccessLogEntry{Context: ctx, Event: logging.EventDisconnect, Duration: time.Since(startTime)})
+	}()
+
+	staller, err := s.stallerFactory.FromConn("telnet", conn, conn, nil)
+	if err != nil {
+		zap.L().Warn("Failed to create Telnet staller", zap.Error(err))
+		conn.Close()
+		return
+	}
+
+	newSession(s, conn, staller, ctx).run()
+}
+
+// Checks a set of credentials against the configured list. With no list configured anything goes
+func (s *Server) checkCredentials(user string, pass string) bool {
+	if len(s.credentials) == 0 {
+		return true
+	}
+
+	return s.credentials[user+":"+pass]
+}
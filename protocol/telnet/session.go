@@ -0,0 +1,230 @@
+package telnet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	coreLogging "github.com/ryanolee/go-pot/core/logging"
+	"github.com/ryanolee/go-pot/core/stall"
+	"github.com/ryanolee/go-pot/protocol/telnet/logging"
+	"go.uber.org/zap"
+)
+
+// Lines longer than this are cut short
+const maxLineLength = 1024
+
+var errTooManyLoginAttempts = errors.New("too many login attempts")
+
+type (
+	// A single telnet connection. All output other than echoed input is dripped to the client through the staller
+	session struct {
+		server  *Server
+		conn    net.Conn
+		reader  *telnetReader
+		staller *stall.ConnStaller
+		ctx     *coreLogging.ConnContext
+		user    string
+
+		// If the last line ended with a carriage return (Clients can send "\r\n", "\r\0" or just "\r")
+		lastWasCr bool
+	}
+)
+
+func newSession(server *Server, conn net.Conn, staller *stall.ConnStaller, ctx *coreLogging.ConnContext) *session {
+	s := &session{
+		server:  server,
+		conn:    conn,
+		staller: staller,
+		ctx:     ctx,
+	}
+
+	s.reader = newTelnetReader(bufio.NewReader(conn), s.flush)
+	return s
+}
+
+func (s *session) run() {
+	s.staller.Halt(s.serve())
+}
+
+func (s *session) serve() error {
+	s.flush(initialNegotiation)
+
+	if err := s.login(); err != nil {
+		return err
+	}
+
+	return s.shell()
+}
+
+// Prompts for credentials until a set of configured credentials is given
+func (s *session) login() error {
+	if err := s.write(fmt.Sprintf("\n%s login: ", s.server.hostname)); err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		user, err := s.readLine(true)
+		if err != nil {
+			return err
+		}
+
+		if err := s.write("Password: "); err != nil {
+			return err
+		}
+
+		pass, err := s.readLine(false)
+		if err != nil {
+			return err
+		}
+
+		success := s.server.checkCredentials(user, pass)
+		s.server.logger.Log(&logging.TelnetAccessLogEntry{
+			Context: s.ctx,
+			Event:   logging.EventLogin,
+			User:    user,
+			Pass:    pass,
+			Success: &success,
+		})
+
+		if success {
+			s.user = user
+			return nil
+		}
+
+		if attempt >= s.server.maxLoginAttempts {
+			s.write("\nLogin incorrect\n")
+			return errTooManyLoginAttempts
+		}
+
+		if err := s.write(fmt.Sprintf("\nLogin incorrect\n%s login: ", s.server.hostname)); err != nil {
+			return err
+		}
+	}
+}
+
+// Serves a busybox shell until the client leaves or the staller runs out of time
+func (s *session) shell() error {
+	if err := s.write(busyboxBanner); err != nil {
+		return err
+	}
+
+	for {
+		if err := s.write(s.prompt()); err != nil {
+			return err
+		}
+
+		line, err := s.readLine(true)
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		s.server.logger.Log(&logging.TelnetAccessLogEntry{
+			Context: s.ctx,
+			Event:   logging.EventCommand,
+			User:    s.user,
+			Command: line,
+		})
+
+		for _, command := range splitCommands(line) {
+			if err := s.runCommand(command); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *session) prompt() string {
+	if s.user == "root" {
+		return fmt.Sprintf("%s:~# ", s.server.hostname)
+	}
+
+	return fmt.Sprintf("%s:~$ ", s.server.hostname)
+}
+
+// Reads a single line of input from the client optionally echoing it back (Passwords are not echoed)
+// Reads are bound by the time the staller has left so clients that go quiet are dropped once it runs out
+func (s *session) readLine(echo bool) (string, error) {
+	if err := s.conn.SetReadDeadline(time.Now().Add(s.staller.GetRemainingTime())); err != nil {
+		return "", err
+	}
+
+	line := make([]byte, 0, 64)
+	for {
+		char, err := s.reader.ReadByte()
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return "", stall.ErrStallDeadlineReached
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		// Skip the second half of a "\r\n" or "\r\0" line ending
+		if s.lastWasCr && (char == '\n' || char == 0) {
+			s.lastWasCr = false
+			continue
+		}
+		s.lastWasCr = char == '\r'
+
+		switch char {
+		case '\r', '\n':
+			s.flush([]byte("\r\n"))
+			return string(line), nil
+		case 0x7f, 0x08:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				if echo {
+					s.flush([]byte("\b \b"))
+				}
+			}
+		case 0x03:
+			s.flush([]byte("^C\r\n"))
+			return "", nil
+		case 0x04:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+		default:
+			if char < 0x20 && char != '\t' {
+				continue
+			}
+
+			if len(line) < maxLineLength {
+				line = append(line, char)
+			}
+
+			if echo {
+				s.flush([]byte{char})
+			}
+		}
+	}
+}
+
+// Writes data slowly to the client
+func (s *session) write(data string) error {
+	_, err := s.staller.Write([]byte(normalise(data)))
+	return err
+}
+
+// Writes data to the client straight away (Used for option negotiation and echoing input)
+func (s *session) flush(data []byte) {
+	if _, err := s.staller.WriteNow(data); err != nil {
+		zap.L().Debug("Failed to flush Telnet output", zap.Error(err))
+	}
+}
+
+// Terminals expect CRLF line endings
+func normalise(data string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\n", "\r\n")
+}
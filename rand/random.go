@@ -1,8 +1,13 @@
 package rand
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
 	"hash/crc64"
 	"math/rand"
+	randv2 "math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/thoas/go-funk"
@@ -27,19 +32,48 @@ func NewSeededRandFromString(stringSeed string) *SeededRand {
 	return NewSeededRand(int64(hash))
 }
 
+// Seeds from an HMAC of the parts so the same secret and parts always give the same sequence but the
+// sequence can not be predicted without the secret
+func NewSeededRandFromSecret(secret []byte, parts ...string) *SeededRand {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+	return NewSeededRand(int64(binary.BigEndian.Uint64(mac.Sum(nil)[:8])))
+}
+
+// Nothing seeded from the time is ever generated again so a smaller PCG source is used. Every stream served
+// has a source of its own and the default math/rand source takes up around 5KB where this takes up 16 bytes
 func NewSeededRandFromTime() *SeededRand {
-	return NewSeededRand(time.Now().UnixNano())
+	return newSeededRandFromSource(&pcgSource{pcg: randv2.NewPCG(uint64(time.Now().UnixNano()), 0)})
 }
 
+// Seeded sources stay on math/rand so replayed streams, mazes and honeytokens match those already served
 func NewSeededRand(seed int64) *SeededRand {
-	source := rand.NewSource(seed)
-	r := rand.New(source)
+	return newSeededRandFromSource(rand.NewSource(seed))
+}
+
+func newSeededRandFromSource(source rand.Source) *SeededRand {
 	return &SeededRand{
 		Source: source,
-		Rand:   r,
+		Rand:   rand.New(source),
 	}
 }
 
+type pcgSource struct {
+	pcg *randv2.PCG
+}
+
+func (s *pcgSource) Int63() int64 {
+	return int64(s.pcg.Uint64() >> 1)
+}
+
+func (s *pcgSource) Uint64() uint64 {
+	return s.pcg.Uint64()
+}
+
+func (s *pcgSource) Seed(seed int64) {
+	s.pcg.Seed(uint64(seed), 0)
+}
+
 // Getters and setters
 func (sr *SeededRand) SetSource(source rand.Source) {
 	sr.Rand = rand.New(source)
@@ -57,7 +91,11 @@ func (sr *SeededRand) StringChoice(stringSlice *[]string) string {
 
 func (sr *SeededRand) StringChoiceMultiple(stringSlice *[]string, numChoices int) []string {
 	// Pick NumChoices random choices from the string slice without duplicates
-	choices := funk.Shuffle(*stringSlice).([]string)
+	choices := make([]string, len(*stringSlice))
+	copy(choices, *stringSlice)
+	sr.Rand.Shuffle(len(choices), func(i, j int) {
+		choices[i], choices[j] = choices[j], choices[i]
+	})
 
 	return choices[:numChoices]
 
@@ -0,0 +1,202 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/hmac"
+	cryptoRand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	mathRand "math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ryanolee/go-pot/config"
+	"go.uber.org/zap"
+)
+
+// Partial secrets shorter than this are not traced as they would match too many tokens
+const minTraceLength = 8
+
+type (
+	// Who a honeytoken was served to
+	HoneytokenClient struct {
+		Protocol string
+		Ip       string
+		Path     string
+
+		// Identifies the request (or connection for stream based protocols) the token was served in
+		RequestId string
+	}
+
+	// Derives each secret from a fingerprint of the client it is served to and records it in the ledger. Secrets are
+	// still generated from the regex of their rule but the generator is seeded with an HMAC of the client, time and
+	// request so every token is unique and can be proven to have been served to that client
+	HoneytokenIssuer struct {
+		key      []byte
+		ledger   *zap.Logger
+		sequence atomic.Uint64
+	}
+
+	// A single token recorded in the ledger
+	HoneytokenLedgerEntry struct {
+		Time        string `json:"time"`
+		Protocol    string `json:"protocol"`
+		Ip          string `json:"ip"`
+		Path        string `json:"path"`
+		RequestId   string `json:"request_id"`
+		Sequence    uint64 `json:"sequence"`
+		Rule        string `json:"rule"`
+		Fingerprint string `json:"fingerprint"`
+		Secret      string `json:"secret"`
+	}
+)
+
+func NewHoneytokenIssuer(conf *config.Config) (*HoneytokenIssuer, error) {
+	honeytokenConfig := conf.Generator.Secrets.Honeytokens
+	if !honeytokenConfig.Enabled {
+		return nil, nil
+	}
+
+	key := []byte(honeytokenConfig.Key)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := cryptoRand.Read(key); err != nil {
+			return nil, err
+		}
+		zap.L().Warn("No honeytoken key given. Honeytokens served before a restart will not be able to be verified")
+	}
+
+	// Every token must be recorded so the ledger can not be sampled like the other logs
+	loggerCfg := zap.NewProductionConfig()
+	loggerCfg.OutputPaths = []string{honeytokenConfig.LedgerPath}
+	loggerCfg.Sampling = nil
+	loggerCfg.DisableCaller = true
+	loggerCfg.DisableStacktrace = true
+
+	ledger, err := loggerCfg.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open honeytoken ledger %s: %w", honeytokenConfig.LedgerPath, err)
+	}
+
+	return &HoneytokenIssuer{
+		key:    key,
+		ledger: ledger,
+	}, nil
+}
+
+// Creates an issuer that can only verify ledger entries made with the given key
+func NewHoneytokenVerifier(key string) *HoneytokenIssuer {
+	return &HoneytokenIssuer{
+		key: []byte(key),
+	}
+}
+
+// Generates a secret for the given client and records it in the ledger
+func (i *HoneytokenIssuer) Issue(generator *SecretGenerator, client *HoneytokenClient) string {
+	entry := &HoneytokenLedgerEntry{
+		Time:      time.Now().UTC().Format(time.RFC3339Nano),
+		Protocol:  client.Protocol,
+		Ip:        client.Ip,
+		Path:      client.Path,
+		RequestId: client.RequestId,
+		Sequence:  i.sequence.Add(1),
+		Rule:      generator.Name,
+	}
+
+	fingerprint := i.fingerprint(entry)
+	entry.Fingerprint = hex.EncodeToString(fingerprint)
+
+	secret := deriveSecret(generator, fingerprint)
+	entry.Secret = secret
+
+	i.ledger.Info("honeytoken",
+		zap.String("time", entry.Time),
+		zap.String("protocol", entry.Protocol),
+		zap.String("ip", entry.Ip),
+		zap.String("path", entry.Path),
+		zap.String("request_id", entry.RequestId),
+		zap.Uint64("sequence", entry.Sequence),
+		zap.String("rule", entry.Rule),
+		zap.String("fingerprint", entry.Fingerprint),
+		zap.String("secret", entry.Secret),
+	)
+
+	return secret
+}
+
+// Checks the entry was signed with the key of the issuer and that its secret was derived from it
+func (i *HoneytokenIssuer) Verify(entry *HoneytokenLedgerEntry, generator *SecretGenerator) bool {
+	fingerprint := i.fingerprint(entry)
+	expected, err := hex.DecodeString(entry.Fingerprint)
+	if err != nil || !hmac.Equal(fingerprint, expected) {
+		return false
+	}
+
+	return deriveSecret(generator, fingerprint) == entry.Secret
+}
+
+func (i *HoneytokenIssuer) fingerprint(entry *HoneytokenLedgerEntry) []byte {
+	mac := hmac.New(sha256.New, i.key)
+	mac.Write([]byte(strings.Join([]string{
+		entry.Time,
+		entry.Protocol,
+		entry.Ip,
+		entry.Path,
+		entry.RequestId,
+		strconv.FormatUint(entry.Sequence, 10),
+		entry.Rule,
+	}, "\x00")))
+
+	return mac.Sum(nil)
+}
+
+// Generates a secret from the regex of the rule seeded by the fingerprint
+func deriveSecret(generator *SecretGenerator, fingerprint []byte) string {
+	// The seed is passed through a math/rand source so secrets already in the ledger can still be verified
+	seed := mathRand.NewSource(int64(binary.BigEndian.Uint64(fingerprint[:8]))).Int63()
+	return cleanSecret(generator.seededSecret.Generate(seed))
+}
+
+// Finds every entry in the ledger for the given secret. Secrets match if they are the same, if the secret contains
+// a token from the ledger (i.e a whole line from a paste) or if the secret is part of a token from the ledger
+func TraceHoneytoken(ledgerPath string, secret string) ([]*HoneytokenLedgerEntry, error) {
+	file, err := os.Open(ledgerPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	matches := []*HoneytokenLedgerEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := &HoneytokenLedgerEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil || entry.Secret == "" {
+			continue
+		}
+
+		if entry.Secret == secret ||
+			(len(entry.Secret) >= minTraceLength && strings.Contains(secret, entry.Secret)) ||
+			(len(secret) >= minTraceLength && strings.Contains(entry.Secret, secret)) {
+			matches = append(matches, entry)
+		}
+	}
+
+	return matches, scanner.Err()
+}
+
+// Creates an id for requests that do not have one of their own
+func newRequestId() string {
+	id := make([]byte, 8)
+	if _, err := cryptoRand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,135 @@
+package secrets
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ryanolee/go-pot/config"
+	"github.com/spf13/cobra"
+)
+
+const testHoneytokenKey = "honeytoken key"
+
+// Every secret served is unique to the client, recorded in the ledger and can be derived again from its entry
+func TestHoneytokensCanBeTracedAndVerified(t *testing.T) {
+	conf, err := config.NewConfig(config.BindConfigFileFlags(&cobra.Command{}), config.GetStartFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	conf.Generator.Secrets.Honeytokens.Enabled = true
+	conf.Generator.Secrets.Honeytokens.Key = testHoneytokenKey
+	conf.Generator.Secrets.Honeytokens.LedgerPath = ledgerPath
+
+	collection, err := NewSecretGeneratorCollection(conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generator := collection.Generators[0]
+	clients := []HoneytokenClient{
+		{Protocol: "http", Ip: "203.0.113.7", Path: "/.env", RequestId: "a"},
+		{Protocol: "http", Ip: "203.0.113.7", Path: "/.env", RequestId: "a"},
+		{Protocol: "ftp", Ip: "203.0.113.8", Path: "/backup.sql"},
+	}
+
+	served := map[string]HoneytokenClient{}
+	for _, client := range clients {
+		secret := collection.ForClient(client).Secret(generator)
+		if _, ok := served[secret]; ok {
+			t.Fatalf("expected every secret to be unique got %s twice", secret)
+		}
+		served[secret] = client
+	}
+	collection.honeytokens.ledger.Sync()
+
+	verifier := NewHoneytokenVerifier(testHoneytokenKey)
+	otherVerifier := NewHoneytokenVerifier("another key")
+	for secret, client := range served {
+		entries, err := TraceHoneytoken(ledgerPath, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 {
+			t.Fatalf("expected %s to be traced to a single entry got %d", secret, len(entries))
+		}
+
+		entry := entries[0]
+		if entry.Ip != client.Ip || entry.Protocol != client.Protocol || entry.Path != client.Path || entry.Rule != generator.Name {
+			t.Errorf("expected %s to be traced to %+v got %+v", secret, client, entry)
+		}
+
+		if !verifier.Verify(entry, generator) {
+			t.Errorf("expected the entry for %s to be verified", secret)
+		}
+
+		if otherVerifier.Verify(entry, generator) {
+			t.Errorf("expected the entry for %s to only be verified with the key it was signed with", secret)
+		}
+
+		tampered := *entry
+		tampered.Ip = "198.51.100.1"
+		if verifier.Verify(&tampered, generator) {
+			t.Errorf("expected the entry for %s to fail verification once changed", secret)
+		}
+	}
+}
+
+func TestTraceHoneytokenPartialSecrets(t *testing.T) {
+	conf, err := config.NewConfig(config.BindConfigFileFlags(&cobra.Command{}), config.GetStartFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := NewSecretGeneratorCollection(conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledgerPath := filepath.Join(t.TempDir(), "ledger.jsonl")
+	conf.Generator.Secrets.Honeytokens.Enabled = true
+	conf.Generator.Secrets.Honeytokens.Key = testHoneytokenKey
+	conf.Generator.Secrets.Honeytokens.LedgerPath = ledgerPath
+
+	issuer, err := NewHoneytokenIssuer(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Partial secrets need to be long enough to be traced
+	client := &HoneytokenClient{Protocol: "http", Ip: "203.0.113.7", Path: "/", RequestId: "a"}
+	secret := ""
+	for _, generator := range collection.Generators {
+		if secret = issuer.Issue(generator, client); len(secret) >= minTraceLength*2 {
+			break
+		}
+	}
+	issuer.ledger.Sync()
+
+	tests := []struct {
+		name      string
+		secret    string
+		wantMatch bool
+	}{
+		{name: "whole secret", secret: secret, wantMatch: true},
+		{name: "secret in a paste", secret: "export TOKEN=" + secret + "\n", wantMatch: true},
+		{name: "part of the secret", secret: secret[2 : minTraceLength+2], wantMatch: true},
+		{name: "too short to trace", secret: secret[2 : minTraceLength+1], wantMatch: false},
+		{name: "another secret", secret: "not a secret that was served", wantMatch: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := TraceHoneytoken(ledgerPath, test.secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if (len(entries) == 1) != test.wantMatch {
+				t.Errorf("expected a match %t got %d entries", test.wantMatch, len(entries))
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package secrets
 
+import "sort"
+
 const secretsToInject = 4
 
 func InjectSecrets(generators *SecretGeneratorCollection, data interface{}) interface{} {
@@ -11,11 +13,18 @@ func InjectSecrets(generators *SecretGeneratorCollection, data interface{}) inte
 	for i := 0; i < secretsToInject; i++ {
 		generators.onGenerate()
 		generator := generators.GetRandomGenerator()
-		dataMap[generator.NameGenerator.Generate()] = generator.SecretGenerator.Generate()
+		dataMap[generators.Name(generator)] = generators.Secret(generator)
+	}
+
+	// Keys are visited in order so the same source injects the same secrets
+	keys := make([]string, 0, len(dataMap))
+	for key := range dataMap {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	for key, value := range dataMap {
-		dataMap[key] = InjectSecrets(generators, value)
+	for _, key := range keys {
+		dataMap[key] = InjectSecrets(generators, dataMap[key])
 	}
 
 	return dataMap
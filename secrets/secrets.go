@@ -2,16 +2,25 @@ package secrets
 
 import (
 	"embed"
+	"fmt"
 	"log"
+	"maps"
+	"os"
+	"regexp"
 	"regexp/syntax"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
 
+	"github.com/ryanolee/go-pot/config"
 	"github.com/ryanolee/go-pot/core/metrics"
 	"github.com/ryanolee/go-pot/internal/regen"
 	"github.com/ryanolee/go-pot/rand"
 	"gopkg.in/yaml.v3"
 
 	"github.com/thoas/go-funk"
+	"go.uber.org/zap"
 )
 
 var (
@@ -19,6 +28,9 @@ var (
 	rulesFile embed.FS
 )
 
+// Weight of rules that are not given one. The same as every embedded rule
+const defaultRuleWeight = 1
+
 type (
 	SecretGeneratorRules = map[string]SecretGeneratorRule
 
@@ -31,8 +43,20 @@ type (
 
 	SecretGenerator struct {
 		Name            string
+		Weight          int
 		NameGenerator   regen.Generator
 		SecretGenerator regen.Generator
+
+		// Generators reseeded for each value. Used for honeytokens and replayable streams
+		seededName   *seededGenerator
+		seededSecret *seededGenerator
+	}
+
+	// A generator created once for a pattern. Regex generators hold their own source so
+	// it is reseeded before each value is generated
+	seededGenerator struct {
+		lock      sync.Mutex
+		generator regen.SeedableGenerator
 	}
 
 	SecretGeneratorCollectionInput struct {
@@ -41,12 +65,58 @@ type (
 	SecretGeneratorCollection struct {
 		onGenerate func()
 		Generators []*SecretGenerator
+
+		// Running total of the weights of the generators. Generators are picked in proportion to their weight
+		cumulativeWeights []int
+		profiles          []secretProfile
+
+		// Set in honeytoken mode. Secrets are fingerprinted for the client of the collection
+		honeytokens *HoneytokenIssuer
+		client      *HoneytokenClient
+
+		// Set for streams that can be replayed. Generators are picked and seeded from it
+		rand *rand.SeededRand
+	}
+
+	// Weights used in place of the defaults for paths matching the pattern
+	secretProfile struct {
+		pattern    *regexp.Regexp
+		collection *SecretGeneratorCollection
 	}
 )
 
-func NewSecretGeneratorCollection(telemetry *metrics.Telemetry) *SecretGeneratorCollection {
-	return &SecretGeneratorCollection{
-		Generators: GetGenerators(),
+func NewSecretGeneratorCollection(conf *config.Config, telemetry *metrics.Telemetry) (*SecretGeneratorCollection, error) {
+	secretsConfig := conf.Generator.Secrets
+	rules, err := LoadRules(secretsConfig.RuleFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generators are kept in the order of their names so seeded streams pick the same ones every time go-pot is run
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	generators := make([]*SecretGenerator, 0, len(rules))
+	for _, name := range names {
+		generator, err := NewGeneratorFromRule(rules[name])
+		if err != nil {
+			return nil, err
+		}
+
+		generators = append(generators, generator)
+	}
+
+	honeytokens, err := NewHoneytokenIssuer(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &SecretGeneratorCollection{
+		Generators:  generators,
+		honeytokens: honeytokens,
 		onGenerate: func() {
 			if telemetry == nil {
 				return
@@ -55,43 +125,294 @@ func NewSecretGeneratorCollection(telemetry *metrics.Telemetry) *SecretGenerator
 			telemetry.TrackGeneratedSecrets(1)
 		},
 	}
+
+	collection.setWeights(secretsConfig.Weights)
+
+	for _, profile := range secretsConfig.Profiles {
+		pattern, err := regexp.Compile(profile.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret profile pattern %s: %w", profile.Pattern, err)
+		}
+
+		// Profiles are layered on top of the deployment wide weights
+		weights := map[string]int{}
+		maps.Copy(weights, secretsConfig.Weights)
+		maps.Copy(weights, profile.Weights)
+
+		profileCollection := &SecretGeneratorCollection{
+			Generators:  collection.Generators,
+			onGenerate:  collection.onGenerate,
+			honeytokens: collection.honeytokens,
+		}
+		profileCollection.setWeights(weights)
+
+		collection.profiles = append(collection.profiles, secretProfile{
+			pattern:    pattern,
+			collection: profileCollection,
+		})
+	}
+
+	return collection, nil
+}
+
+// Gets the collection to use for the given path. Paths matching a profile get a collection using the weights of that profile
+func (c *SecretGeneratorCollection) ForPath(path string) *SecretGeneratorCollection {
+	collection := c
+	for _, profile := range c.profiles {
+		if profile.pattern.MatchString(path) {
+			collection = profile.collection
+			break
+		}
+	}
+
+	if c.client == nil && c.rand == nil {
+		return collection
+	}
+
+	view := *collection
+	view.rand = c.rand
+	if c.client != nil {
+		// Clients keep the path they were first given (i.e the archive a file was served in)
+		client := *c.client
+		if client.Path == "" {
+			client.Path = path
+		}
+		view.client = &client
+	}
+
+	return &view
 }
 
+// Gets a collection serving secrets to the given client. In honeytoken mode every secret it generates is unique
+// to the client and recorded in the ledger. Clients without a request id are given one
+func (c *SecretGeneratorCollection) ForClient(client HoneytokenClient) *SecretGeneratorCollection {
+	if c.honeytokens == nil {
+		return c
+	}
+
+	if client.RequestId == "" {
+		client.RequestId = newRequestId()
+	}
+
+	return c.withClient(&client)
+}
+
+func (c *SecretGeneratorCollection) withClient(client *HoneytokenClient) *SecretGeneratorCollection {
+	collection := *c
+	collection.client = client
+	return &collection
+}
+
+// Gets a collection that picks and generates secrets using the given source so they can be generated again
+func (c *SecretGeneratorCollection) WithRand(source *rand.SeededRand) *SecretGeneratorCollection {
+	collection := *c
+	collection.rand = source
+	return &collection
+}
+
+// Generates a secret from the given generator. In honeytoken mode the secret is fingerprinted for the client of the collection.
+// Secrets not served to a particular client (i.e the shared git repository) are generated as normal
+func (c *SecretGeneratorCollection) Secret(generator *SecretGenerator) string {
+	if c.honeytokens != nil && c.client != nil {
+		return c.honeytokens.Issue(generator, c.client)
+	}
+
+	if c.rand != nil {
+		return generator.seededSecret.Generate(c.rand.Rand.Int63())
+	}
+
+	return generator.SecretGenerator.Generate()
+}
+
+// Generates a name for a secret from the given generator
+func (c *SecretGeneratorCollection) Name(generator *SecretGenerator) string {
+	if c.rand != nil {
+		return generator.seededName.Generate(c.rand.Rand.Int63())
+	}
+
+	return generator.NameGenerator.Generate()
+}
+
+// Picks a generator at random in proportion to the weights of the generators
 func (c *SecretGeneratorCollection) GetRandomGenerator() *SecretGenerator {
-	rnd := rand.NewSeededRandFromTime()
-	return c.Generators[rnd.RandomInt(0, len(c.Generators))]
+	rnd := c.rand
+	if rnd == nil {
+		rnd = rand.NewSeededRandFromTime()
+	}
+	total := 0
+	if len(c.cumulativeWeights) > 0 {
+		total = c.cumulativeWeights[len(c.cumulativeWeights)-1]
+	}
+
+	// Fall back to picking uniformly in the event every rule has been given a weight of 0
+	if total <= 0 {
+		return c.Generators[rnd.RandomInt(0, len(c.Generators))]
+	}
+
+	target := rnd.RandomInt(0, total)
+	return c.Generators[sort.SearchInts(c.cumulativeWeights, target+1)]
+}
+
+// Sets the weight of each generator to the weight of its rule unless overridden
+func (c *SecretGeneratorCollection) setWeights(overrides map[string]int) {
+	for name := range overrides {
+		if c.GetGeneratorByName(name) == nil {
+			zap.L().Sugar().Warnw("Weight given for unknown secret rule", "rule", name)
+		}
+	}
+
+	c.cumulativeWeights = make([]int, len(c.Generators))
+	total := 0
+	for i, generator := range c.Generators {
+		weight, ok := overrides[generator.Name]
+		if !ok {
+			weight = generator.Weight
+		}
+
+		total += max(weight, 0)
+		c.cumulativeWeights[i] = total
+	}
+}
+
+// Gets the generator for the rule with the given name (nil if there is no such rule)
+func (c *SecretGeneratorCollection) GetGeneratorByName(name string) *SecretGenerator {
+	for _, generator := range c.Generators {
+		if generator.Name == name {
+			return generator
+		}
+	}
+
+	return nil
+}
+
+// Generates a secret for the rule with the given name falling back to a random rule if there is no such rule.
+// Characters the rule only uses to find the end of a secret are trimmed off so the value can be placed into a field
+func (c *SecretGeneratorCollection) GenerateSecret(name string) string {
+	generator := c.GetGeneratorByName(name)
+	if generator == nil {
+		generator = c.GetRandomGenerator()
+	}
+
+	c.onGenerate()
+	return cleanSecret(c.Secret(generator))
+}
+
+// Removes characters the rule only uses to find the end of a secret and folds non ASCII characters back to ASCII
+func cleanSecret(secret string) string {
+	return strings.TrimRight(strings.Map(asciiFold, secret), "'\"|;`\r\n")
+}
+
+// Case insensitive rules can generate non ASCII case variants of letters (i.e "ſ" for "s"). Maps them back to ASCII
+func asciiFold(r rune) rune {
+	if r <= unicode.MaxASCII {
+		return r
+	}
+
+	for folded := unicode.SimpleFold(r); folded != r; folded = unicode.SimpleFold(folded) {
+		if folded <= unicode.MaxASCII {
+			return folded
+		}
+	}
+
+	return r
 }
 
 func NewGenerator(rule SecretGeneratorRule) *SecretGenerator {
-	args := &regen.GeneratorArgs{
-		Flags:                   syntax.PerlX,
-		MinUnboundedRepeatCount: 30,
+	generator, err := NewGeneratorFromRule(rule)
+	if err != nil {
+		log.Fatal(err)
 	}
+
+	return generator
+}
+
+// Like NewGenerator but returns an error for rules with patterns that can not be generated from
+func NewGeneratorFromRule(rule SecretGeneratorRule) (*SecretGenerator, error) {
+	args := newGeneratorArgs()
 	nameGenerator, err := newRegexGenerator(rule.NameRegex, args)
 	if err != nil {
-		log.Fatalf("Failed to parse name generator for %s error given as: %s", rule.Name, err)
+		return nil, fmt.Errorf("failed to parse name generator for %s error given as: %w", rule.Name, err)
 	}
 
 	secretGenerator, err := newRegexGenerator(rule.SecretRegex, args)
 	if err != nil {
-		log.Fatalf("Failed to parse secret generator for %s error given as: %s", rule.Name, err)
+		return nil, fmt.Errorf("failed to parse secret generator for %s error given as: %w", rule.Name, err)
+	}
+
+	seededName, err := newSeededGenerator(rule.NameRegex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse name generator for %s error given as: %w", rule.Name, err)
+	}
+
+	seededSecret, err := newSeededGenerator(rule.SecretRegex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse secret generator for %s error given as: %w", rule.Name, err)
 	}
 
 	return &SecretGenerator{
 		Name:            rule.Name,
+		Weight:          rule.Weight,
 		NameGenerator:   nameGenerator,
 		SecretGenerator: secretGenerator,
+		seededName:      seededName,
+		seededSecret:    seededSecret,
+	}, nil
+}
+
+func newSeededGenerator(pattern string) (*seededGenerator, error) {
+	generator, err := newRegexGenerator(pattern, newGeneratorArgs())
+	if err != nil {
+		return nil, err
+	}
+
+	return &seededGenerator{
+		generator: generator,
+	}, nil
+}
+
+// Generates a value from the given seed. Values generated from the same seed are the same
+func (g *seededGenerator) Generate(seed int64) string {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.generator.Seed(seed)
+	return g.generator.Generate()
+}
+
+// Arguments for regex generators. Generators are seeded randomly until reseeded
+func newGeneratorArgs() *regen.GeneratorArgs {
+	return &regen.GeneratorArgs{
+		Flags:                   syntax.PerlX,
+		MinUnboundedRepeatCount: 30,
 	}
 }
 
+// Checks every rule can be generated from. Returns an error for each rule that can not
+func ValidateRules(rules SecretGeneratorRules) []error {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := []error{}
+	for _, name := range names {
+		if _, err := NewGeneratorFromRule(rules[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
 const fullStringLiteral = "[~{FULL_STOP_LITERAL}~]"
 
-func newRegexGenerator(pattern string, opts *regen.GeneratorArgs) (regen.Generator, error) {
+func newRegexGenerator(pattern string, opts *regen.GeneratorArgs) (regen.SeedableGenerator, error) {
 	pattern = strings.ReplaceAll(pattern, "\\.", fullStringLiteral)
 	pattern = strings.ReplaceAll(pattern, ".", "\\w")
 	pattern = strings.ReplaceAll(pattern, fullStringLiteral, "\\.")
 
-	return regen.NewGenerator(pattern, opts)
+	return regen.NewSeedableGenerator(pattern, opts)
 }
 
 func GetGenerators() []*SecretGenerator {
@@ -100,15 +421,83 @@ func GetGenerators() []*SecretGenerator {
 }
 
 func GetRules() *SecretGeneratorRules {
-	rules := &SecretGeneratorRules{}
 	yamlFile, err := rulesFile.ReadFile("secret-rules.yml")
 	if err != nil {
 		panic(err)
 	}
 
-	if err := yaml.Unmarshal(yamlFile, &rules); err != nil {
+	rules, err := ParseRules(yamlFile)
+	if err != nil {
 		panic(err)
 	}
 
-	return rules
+	return &rules
+}
+
+// Loads the embedded rules along with the rules from the given files. Rules from files replace embedded rules with the same name
+func LoadRules(ruleFiles []string) (SecretGeneratorRules, error) {
+	rules := *GetRules()
+	for _, path := range ruleFiles {
+		fileRules, err := LoadRulesFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(rules, fileRules)
+		zap.L().Sugar().Infow("Loaded secret rules", "path", path, "rules", len(fileRules))
+	}
+
+	return rules, nil
+}
+
+// Loads rules from a YAML file in the same format as the embedded secret-rules.yml
+func LoadRulesFile(path string) (SecretGeneratorRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret rules file %s: %w", path, err)
+	}
+
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse secret rules file %s: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// Parses rules keyed by name. Rules without a name are given the key they are listed under and
+// rules without a weight are picked as often as the embedded rules
+func ParseRules(data []byte) (SecretGeneratorRules, error) {
+	rules := SecretGeneratorRules{}
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	// Weights are read again to tell rules without a weight apart from those given a weight of 0
+	weights := map[string]struct {
+		Weight *int `yaml:"weight"`
+	}{}
+	if err := yaml.Unmarshal(data, &weights); err != nil {
+		return nil, err
+	}
+
+	for key, rule := range rules {
+		if rule.SecretRegex == "" {
+			return nil, fmt.Errorf("rule %s has no secret_regex", key)
+		}
+
+		if rule.Name == "" {
+			rule.Name = key
+		}
+
+		if weights[key].Weight == nil {
+			rule.Weight = defaultRuleWeight
+		} else if rule.Weight < 0 {
+			return nil, fmt.Errorf("rule %s has a negative weight of %d", key, rule.Weight)
+		}
+
+		rules[key] = rule
+	}
+
+	return rules, nil
 }
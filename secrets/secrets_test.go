@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/ryanolee/go-pot/rand"
+)
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantName   string
+		wantWeight int
+		wantErr    bool
+	}{
+		{name: "weight given", data: "key:\n  name: named\n  weight: 3\n  secret_regex: a+\n", wantName: "named", wantWeight: 3},
+		{name: "no weight", data: "key:\n  secret_regex: a+\n", wantName: "key", wantWeight: defaultRuleWeight},
+		{name: "weight of 0", data: "key:\n  weight: 0\n  secret_regex: a+\n", wantName: "key", wantWeight: 0},
+		{name: "negative weight", data: "key:\n  weight: -1\n  secret_regex: a+\n", wantErr: true},
+		{name: "no secret regex", data: "key:\n  name_regex: a+\n", wantErr: true},
+		{name: "weight that is not a number", data: "key:\n  weight: heavy\n  secret_regex: a+\n", wantErr: true},
+		{name: "not a map of rules", data: "- secret_regex: a+\n", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := ParseRules([]byte(test.data))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error %t got %v", test.wantErr, err)
+			}
+
+			if test.wantErr {
+				return
+			}
+
+			rule := rules["key"]
+			if rule.Name != test.wantName || rule.Weight != test.wantWeight {
+				t.Errorf("expected %s with a weight of %d got %s with a weight of %d", test.wantName, test.wantWeight, rule.Name, rule.Weight)
+			}
+		})
+	}
+}
+
+// Every embedded rule is given a weight so rules loaded from files without one are picked as often
+func TestEmbeddedRulesHaveDefaultWeight(t *testing.T) {
+	for name, rule := range *GetRules() {
+		if rule.Weight != defaultRuleWeight {
+			t.Errorf("expected rule %s to have a weight of %d got %d", name, defaultRuleWeight, rule.Weight)
+		}
+	}
+}
+
+func TestGetRandomGeneratorWeights(t *testing.T) {
+	tests := []struct {
+		name      string
+		weights   []int
+		overrides map[string]int
+		want      []float64
+	}{
+		{name: "equal weights", weights: []int{1, 1, 1, 1}, want: []float64{0.25, 0.25, 0.25, 0.25}},
+		{name: "uneven weights", weights: []int{1, 3, 0, 4}, want: []float64{0.125, 0.375, 0, 0.5}},
+		{name: "overridden weights", weights: []int{1, 1, 1, 1}, overrides: map[string]int{"a": 0, "d": 2}, want: []float64{0, 0.25, 0.25, 0.5}},
+		{name: "negative overrides count as 0", weights: []int{1, 1}, overrides: map[string]int{"a": -5}, want: []float64{0, 1}},
+		{name: "every weight 0", weights: []int{0, 0}, want: []float64{0.5, 0.5}},
+	}
+
+	const draws = 20000
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			collection := &SecretGeneratorCollection{rand: rand.NewSeededRand(1)}
+			for i, weight := range test.weights {
+				collection.Generators = append(collection.Generators, &SecretGenerator{Name: string(rune('a' + i)), Weight: weight})
+			}
+			collection.setWeights(test.overrides)
+
+			counts := map[string]int{}
+			for i := 0; i < draws; i++ {
+				counts[collection.GetRandomGenerator().Name]++
+			}
+
+			for i, want := range test.want {
+				name := string(rune('a' + i))
+				got := float64(counts[name]) / draws
+				if want == 0 && counts[name] != 0 {
+					t.Errorf("expected %s to never be picked got %d picks", name, counts[name])
+				}
+
+				if got < want-0.02 || got > want+0.02 {
+					t.Errorf("expected %s to be picked %.3f of the time got %.3f", name, want, got)
+				}
+			}
+		})
+	}
+}
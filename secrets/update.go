@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"regexp"
 	"strings"
 
@@ -36,8 +37,22 @@ func GetSecretsFromGitLeaks() (*GitLeaksSecretRules, error) {
 		return nil, err
 	}
 
+	return ParseGitLeaksRules(body)
+}
+
+// Reads gitleaks rules from a local gitleaks.toml
+func GetSecretsFromGitLeaksFile(path string) (*GitLeaksSecretRules, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGitLeaksRules(body)
+}
+
+func ParseGitLeaksRules(data []byte) (*GitLeaksSecretRules, error) {
 	secretsData := &GitLeaksSecretRules{}
-	if _, err := toml.Decode(string(body), secretsData); err != nil {
+	if _, err := toml.Decode(string(data), secretsData); err != nil {
 		return nil, err
 	}
 	return secretsData, nil
@@ -49,8 +64,17 @@ func UpdateSecretsFromGitLeaks() ([]byte, error) {
 		return nil, err
 	}
 
-	currentRules := make(map[string]SecretGeneratorRule)
+	return yaml.Marshal(ConvertGitLeaksRules(gitLeaksSecrets))
+}
+
+// Converts gitleaks rules into secret generator rules. Rules that only match on file paths (and so have no regex) are skipped
+func ConvertGitLeaksRules(gitLeaksSecrets *GitLeaksSecretRules) SecretGeneratorRules {
+	currentRules := make(SecretGeneratorRules)
 	for _, gitLeaksSecret := range gitLeaksSecrets.Rules {
+		if gitLeaksSecret.Regex == "" {
+			continue
+		}
+
 		currentRules[gitLeaksSecret.Id] = SecretGeneratorRule{
 			Name:        gitLeaksSecret.Id,
 			NameRegex:   formatSecretsNameRegex(gitLeaksSecret.Id),
@@ -59,12 +83,7 @@ func UpdateSecretsFromGitLeaks() ([]byte, error) {
 		}
 	}
 
-	secretRulesYaml, err := yaml.Marshal(currentRules)
-	if err != nil {
-		return nil, err
-	}
-
-	return secretRulesYaml, nil
+	return currentRules
 }
 
 func formatSecretsRegex(secretRegex string) string {